@@ -11,12 +11,14 @@ import (
 )
 
 type AuthHandler struct {
-	authService services.AuthService
+	authService  services.AuthService
+	matchService services.MatchService
 }
 
-func NewAuthHandler(authService services.AuthService) *AuthHandler {
+func NewAuthHandler(authService services.AuthService, matchService services.MatchService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:  authService,
+		matchService: matchService,
 	}
 }
 
@@ -105,5 +107,11 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"user": user})
+	matching, err := h.matchService.GetMatchingStatus(user.ID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"user": user, "matching": matching})
 }
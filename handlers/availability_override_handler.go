@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/services"
+	"virtual-cuppa-be/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AvailabilityOverrideHandler struct {
+	overrideService services.AvailabilityOverrideService
+	matchService    services.MatchService
+}
+
+func NewAvailabilityOverrideHandler(overrideService services.AvailabilityOverrideService, matchService services.MatchService) *AvailabilityOverrideHandler {
+	return &AvailabilityOverrideHandler{
+		overrideService: overrideService,
+		matchService:    matchService,
+	}
+}
+
+// GetOverrides returns the authenticated user's current and upcoming availability overrides
+func (h *AvailabilityOverrideHandler) GetOverrides(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	overrides, err := h.overrideService.GetOverrides(userID.(uint))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{
+		"overrides": overrides,
+		"count":     len(overrides),
+	})
+}
+
+// CreateOverride adds a dated availability override for the authenticated user
+func (h *AvailabilityOverrideHandler) CreateOverride(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.CreateAvailabilityOverrideInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	override, err := h.overrideService.CreateOverride(userID.(uint), &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	// An available override may open up a slot for a match
+	if input.Kind == models.OverrideKindAvailable {
+		_ = h.matchService.TryGenerateMatchForUser(userID.(uint))
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, override)
+}
+
+// DeleteOverride removes one of the authenticated user's availability overrides
+func (h *AvailabilityOverrideHandler) DeleteOverride(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	overrideID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid override ID")
+		return
+	}
+
+	if err := h.overrideService.DeleteOverride(userID.(uint), uint(overrideID)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"message": "Availability override deleted successfully"})
+}
+
+func (h *AvailabilityOverrideHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidOverride):
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrOverrideNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(c, err)
+	}
+}
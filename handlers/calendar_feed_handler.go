@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"virtual-cuppa-be/services"
+	"virtual-cuppa-be/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarFeedHandler struct {
+	feedService services.CalendarFeedService
+}
+
+func NewCalendarFeedHandler(feedService services.CalendarFeedService) *CalendarFeedHandler {
+	return &CalendarFeedHandler{
+		feedService: feedService,
+	}
+}
+
+// GetFeed returns the address of the current user's calendar feed
+func (h *CalendarFeedHandler) GetFeed(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	feed, err := h.feedService.GetFeed(userID.(uint))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, feed)
+}
+
+// RotateFeedToken issues a new feed address, revoking the old one
+func (h *CalendarFeedHandler) RotateFeedToken(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	feed, err := h.feedService.RotateFeedToken(userID.(uint))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, feed)
+}
+
+// ServeFeed serves a calendar feed to calendar clients, authenticated by the token in the path
+func (h *CalendarFeedHandler) ServeFeed(c *gin.Context) {
+	// Some clients insist on an .ics suffix, so accept the token with or without it
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	feed, err := h.feedService.RenderFeed(token)
+	if err != nil {
+		if err == services.ErrInvalidFeedToken {
+			utils.RespondWithError(c, http.StatusNotFound, "Calendar feed not found")
+			return
+		}
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/services"
+	"virtual-cuppa-be/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HolidayCalendarHandler struct {
+	calendarService services.HolidayCalendarService
+}
+
+func NewHolidayCalendarHandler(calendarService services.HolidayCalendarService) *HolidayCalendarHandler {
+	return &HolidayCalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// GetCalendars returns the holiday and blackout calendars of the admin's organisation
+func (h *HolidayCalendarHandler) GetCalendars(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	calendars, err := h.calendarService.GetCalendars(orgID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{
+		"calendars": calendars,
+		"count":     len(calendars),
+	})
+}
+
+// CreateCalendar creates a calendar from a list of dates
+func (h *HolidayCalendarHandler) CreateCalendar(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateHolidayCalendarInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	calendar, err := h.calendarService.CreateCalendar(orgID, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, calendar)
+}
+
+// ImportICS creates a calendar from an uploaded ICS file
+func (h *HolidayCalendarHandler) ImportICS(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	var input models.ImportHolidayCalendarInput
+	if err := c.ShouldBind(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "ICS file is required")
+		return
+	}
+	defer file.Close()
+
+	calendar, err := h.calendarService.ImportICS(orgID, &input, file)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, calendar)
+}
+
+// DeleteCalendar removes a calendar and its dates
+func (h *HolidayCalendarHandler) DeleteCalendar(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	calendarID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid calendar ID")
+		return
+	}
+
+	if err := h.calendarService.DeleteCalendar(orgID, uint(calendarID)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"message": "Holiday calendar deleted successfully"})
+}
+
+func (h *HolidayCalendarHandler) handleError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrInvalidCalendarDate, services.ErrEmptyCalendar, services.ErrInvalidICS:
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	case services.ErrCalendarNotFound:
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(c, err)
+	}
+}
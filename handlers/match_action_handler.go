@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"virtual-cuppa-be/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MatchActionHandler serves the accept and reject links of new match emails. Opening a link
+// only shows a confirmation page; the action is taken when the page is submitted, so mail
+// scanners that follow links can't answer for the user.
+type MatchActionHandler struct {
+	matchService services.MatchService
+	appURL       string
+}
+
+func NewMatchActionHandler(matchService services.MatchService, appURL string) *MatchActionHandler {
+	return &MatchActionHandler{
+		matchService: matchService,
+		appURL:       appURL,
+	}
+}
+
+var matchActionPage = template.Must(template.New("match-action").Parse(`<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1.0" />
+    <title>{{.Title}} - Virtual Cuppa</title>
+  </head>
+  <body style="margin: 0; padding: 40px 20px; font-family: Arial, Helvetica, sans-serif; background-color: #f4f4f4">
+    <div style="max-width: 480px; margin: 0 auto; padding: 30px; background-color: #ffffff; text-align: center">
+      <h1 style="margin: 0 0 20px 0; color: #667eea; font-size: 24px; font-weight: normal">{{.Title}}</h1>
+      <p style="margin: 0 0 30px 0; color: #333333; font-size: 16px; line-height: 1.6">{{.Message}}</p>
+      {{if .Confirm}}
+      <form method="post">
+        <button type="submit" style="padding: 12px 30px; border: 0; background-color: #667eea; color: #ffffff; font-size: 16px; cursor: pointer">{{.Confirm}}</button>
+      </form>
+      {{else if .AppURL}}
+      <a href="{{.AppURL}}" style="color: #667eea; text-decoration: none">Open Virtual Cuppa</a>
+      {{end}}
+    </div>
+  </body>
+</html>
+`))
+
+type matchActionView struct {
+	Title   string
+	Message string
+	Confirm string
+	AppURL  string
+}
+
+// ShowAction asks the user to confirm the action of the link
+func (h *MatchActionHandler) ShowAction(c *gin.Context) {
+	action, err := h.matchService.CheckMatchLink(c.Param("token"))
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+
+	if action == services.MatchActionAccept {
+		h.render(c, http.StatusOK, matchActionView{
+			Title:   "Accept your cuppa?",
+			Message: "We'll let the others know you're in.",
+			Confirm: "Accept",
+		})
+		return
+	}
+	h.render(c, http.StatusOK, matchActionView{
+		Title:   "Decline your cuppa?",
+		Message: "We'll let the others know you can't make it.",
+		Confirm: "Decline",
+	})
+}
+
+// TakeAction accepts or rejects the match of the link
+func (h *MatchActionHandler) TakeAction(c *gin.Context) {
+	action, err := h.matchService.ActOnMatchLink(c.Param("token"))
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+
+	if action == services.MatchActionAccept {
+		h.render(c, http.StatusOK, matchActionView{
+			Title:   "Cuppa accepted",
+			Message: "Thanks! We'll let you know once everyone has accepted.",
+			AppURL:  h.appURL,
+		})
+		return
+	}
+	h.render(c, http.StatusOK, matchActionView{
+		Title:   "Cuppa declined",
+		Message: "No problem, you'll be matched again soon.",
+		AppURL:  h.appURL,
+	})
+}
+
+func (h *MatchActionHandler) renderError(c *gin.Context, err error) {
+	view := matchActionView{Title: "Something went wrong", AppURL: h.appURL}
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidMatchLink):
+		status = http.StatusBadRequest
+		view.Title = "Link not valid"
+		view.Message = "This link is invalid or has expired. You can still answer in Virtual Cuppa."
+	case errors.Is(err, services.ErrMatchNotFound), errors.Is(err, services.ErrUnauthorizedMatch):
+		status = http.StatusNotFound
+		view.Title = "Cuppa not found"
+		view.Message = "This cuppa no longer exists or you have already left it."
+	case errors.Is(err, services.ErrMatchClosed):
+		status = http.StatusConflict
+		view.Title = "Cuppa closed"
+		view.Message = "This cuppa has expired or was called off, so it can't be answered any more."
+	case errors.Is(err, services.ErrMatchLinkUsed):
+		status = http.StatusConflict
+		view.Title = "Already answered"
+		view.Message = "You have already answered this cuppa, so this link no longer works. You can find it in Virtual Cuppa."
+	default:
+		view.Message = "We couldn't update your cuppa. Please try again in Virtual Cuppa."
+	}
+	h.render(c, status, view)
+}
+
+func (h *MatchActionHandler) render(c *gin.Context, status int, view matchActionView) {
+	var page bytes.Buffer
+	if err := matchActionPage.Execute(&page, view); err != nil {
+		c.String(http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
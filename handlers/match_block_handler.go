@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/services"
+	"virtual-cuppa-be/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MatchBlockHandler struct {
+	blockService services.MatchBlockService
+}
+
+func NewMatchBlockHandler(blockService services.MatchBlockService) *MatchBlockHandler {
+	return &MatchBlockHandler{
+		blockService: blockService,
+	}
+}
+
+// GetBlockedUsers returns the authenticated user's personal blocklist
+func (h *MatchBlockHandler) GetBlockedUsers(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blocks, err := h.blockService.GetBlockedUsers(userID.(uint))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{
+		"blocks": blocks,
+		"count":  len(blocks),
+	})
+}
+
+// BlockUser adds a colleague to the authenticated user's blocklist
+func (h *MatchBlockHandler) BlockUser(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.BlockUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	block, err := h.blockService.BlockUser(userID.(uint), input.UserID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, block)
+}
+
+// UnblockUser removes a colleague from the authenticated user's blocklist
+func (h *MatchBlockHandler) UnblockUser(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blockedUserID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.blockService.UnblockUser(userID.(uint), uint(blockedUserID)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"message": "User unblocked successfully"})
+}
+
+// GetForbiddenPairs returns the admin-managed forbidden pairs of the admin's organisation
+func (h *MatchBlockHandler) GetForbiddenPairs(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	pairs, err := h.blockService.GetForbiddenPairs(orgID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{
+		"pairs": pairs,
+		"count": len(pairs),
+	})
+}
+
+// AddForbiddenPair prevents two users of the admin's organisation from being matched
+func (h *MatchBlockHandler) AddForbiddenPair(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	var input models.ForbiddenPairInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pair, err := h.blockService.AddForbiddenPair(userID.(uint), orgID, input.User1ID, input.User2ID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, pair)
+}
+
+// RemoveForbiddenPair deletes an admin-managed forbidden pair
+func (h *MatchBlockHandler) RemoveForbiddenPair(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	blockID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid forbidden pair ID")
+		return
+	}
+
+	if err := h.blockService.RemoveForbiddenPair(orgID, uint(blockID)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"message": "Forbidden pair removed successfully"})
+}
+
+func (h *MatchBlockHandler) handleError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrCannotBlockSelf:
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	case services.ErrBlockTargetNotFound, services.ErrBlockNotFound:
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(c, err)
+	}
+}
+
+// adminOrganisationID reads the admin's organisation from the context, responding with an
+// error if it is missing
+func adminOrganisationID(c *gin.Context) (uint, bool) {
+	organisationID, exists := c.Get("organisationID")
+	if !exists || organisationID == nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Admin not assigned to any organisation")
+		return 0, false
+	}
+
+	orgID, ok := organisationID.(uint)
+	if !ok {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid organisation ID")
+		return 0, false
+	}
+	return orgID, true
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
+	"virtual-cuppa-be/models"
 	"virtual-cuppa-be/scheduler"
 	"virtual-cuppa-be/services"
 	"virtual-cuppa-be/utils"
@@ -64,6 +68,29 @@ func (h *MatchHandler) GetMatchHistory(c *gin.Context) {
 	})
 }
 
+// UpdateMatchPreferences sets how often the user wants a cuppa and pauses or resumes matching
+func (h *MatchHandler) UpdateMatchPreferences(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.UpdateMatchPreferencesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status, err := h.matchService.UpdateMatchPreferences(userID.(uint), &input)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, status)
+}
+
 // AcceptMatch accepts a pending match
 func (h *MatchHandler) AcceptMatch(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -98,7 +125,7 @@ func (h *MatchHandler) AcceptMatch(c *gin.Context) {
 	})
 }
 
-// RejectMatch rejects a pending match
+// RejectMatch rejects a pending match, optionally with a reason
 func (h *MatchHandler) RejectMatch(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -113,7 +140,14 @@ func (h *MatchHandler) RejectMatch(c *gin.Context) {
 		return
 	}
 
-	if err := h.matchService.RejectMatch(userID.(uint), uint(matchID)); err != nil {
+	// The reason is optional, so an empty body is allowed
+	var input models.RejectMatchInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.matchService.RejectMatch(userID.(uint), uint(matchID), input.Reason); err != nil {
 		if err == services.ErrMatchNotFound {
 			utils.RespondWithError(c, http.StatusNotFound, "Match not found")
 			return
@@ -143,7 +177,7 @@ func (h *MatchHandler) GenerateMatches(c *gin.Context) {
 		return
 	}
 
-	count, err := h.matchService.GenerateMatchesForOrganisation(orgID)
+	result, err := h.matchService.GenerateMatchesForOrganisation(orgID)
 	if err != nil {
 		if err == services.ErrNoUsersToMatch {
 			utils.RespondWithError(c, http.StatusBadRequest, "Not enough users to create matches")
@@ -154,8 +188,70 @@ func (h *MatchHandler) GenerateMatches(c *gin.Context) {
 	}
 
 	utils.RespondWithSuccess(c, http.StatusOK, gin.H{
-		"message": "Matches generated successfully",
-		"count":   count,
+		"message":        "Matches generated successfully",
+		"count":          result.MatchesCreated,
+		"eligibleUsers":  result.EligibleUsers,
+		"unmatchedCount": len(result.Unmatched),
+		"unmatched":      result.Unmatched,
+	})
+}
+
+// PreviewMatches runs match generation without creating anything and returns the proposal
+func (h *MatchHandler) PreviewMatches(c *gin.Context) {
+	organisationID, exists := c.Get("organisationID")
+	if !exists || organisationID == nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Admin not assigned to any organisation")
+		return
+	}
+
+	orgID, ok := organisationID.(uint)
+	if !ok {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid organisation ID")
+		return
+	}
+
+	proposal, err := h.matchService.PreviewMatchesForOrganisation(orgID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, proposal)
+}
+
+// CommitMatchProposal creates the matches of a previously previewed proposal
+func (h *MatchHandler) CommitMatchProposal(c *gin.Context) {
+	organisationID, exists := c.Get("organisationID")
+	if !exists || organisationID == nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Admin not assigned to any organisation")
+		return
+	}
+
+	orgID, ok := organisationID.(uint)
+	if !ok {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid organisation ID")
+		return
+	}
+
+	result, err := h.matchService.CommitMatchProposal(orgID, c.Param("proposalId"))
+	if err != nil {
+		switch err {
+		case services.ErrProposalNotFound:
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+		case services.ErrProposalOutdated:
+			utils.RespondWithError(c, http.StatusConflict, err.Error())
+		default:
+			utils.HandleServiceError(c, err)
+		}
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{
+		"message":        "Match proposal committed successfully",
+		"count":          result.MatchesCreated,
+		"eligibleUsers":  result.EligibleUsers,
+		"unmatchedCount": len(result.Unmatched),
+		"unmatched":      result.Unmatched,
 	})
 }
 
@@ -199,6 +295,43 @@ func (h *MatchHandler) TriggerScheduler(c *gin.Context) {
 	})
 }
 
+// GetMatchCalendar downloads the match as an .ics calendar invite
+func (h *MatchHandler) GetMatchCalendar(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	matchIDStr := c.Param("id")
+	matchID, err := strconv.ParseUint(matchIDStr, 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid match ID")
+		return
+	}
+
+	invite, err := h.matchService.GetMatchCalendar(userID.(uint), uint(matchID))
+	if err != nil {
+		if err == services.ErrMatchNotFound {
+			utils.RespondWithError(c, http.StatusNotFound, "Match not found")
+			return
+		}
+		if err == services.ErrUnauthorizedMatch {
+			utils.RespondWithError(c, http.StatusForbidden, "Unauthorized to view this match")
+			return
+		}
+		if err == services.ErrMatchNotScheduled {
+			utils.RespondWithError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="cuppa-%d.ics"`, matchID))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", invite)
+}
+
 // GetMatchAvailabilities returns availabilities for a specific match
 func (h *MatchHandler) GetMatchAvailabilities(c *gin.Context) {
 	userID, exists := c.Get("userID")
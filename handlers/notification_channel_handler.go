@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/services"
+	"virtual-cuppa-be/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationChannelHandler struct {
+	channelService services.NotificationChannelService
+}
+
+func NewNotificationChannelHandler(channelService services.NotificationChannelService) *NotificationChannelHandler {
+	return &NotificationChannelHandler{
+		channelService: channelService,
+	}
+}
+
+// GetChannels returns the notification channels of the admin's organisation
+func (h *NotificationChannelHandler) GetChannels(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	channels, err := h.channelService.GetChannels(orgID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{
+		"channels": channels,
+		"count":    len(channels),
+	})
+}
+
+// CreateChannel adds a notification channel to the admin's organisation
+func (h *NotificationChannelHandler) CreateChannel(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	var input models.CreateNotificationChannelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	channel, err := h.channelService.CreateChannel(orgID, &input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, channel)
+}
+
+// DeleteChannel removes a notification channel
+func (h *NotificationChannelHandler) DeleteChannel(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	channelID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid channel ID")
+		return
+	}
+
+	if err := h.channelService.DeleteChannel(orgID, uint(channelID)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"message": "Notification channel deleted successfully"})
+}
+
+func (h *NotificationChannelHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidChannel):
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrChannelNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(c, err)
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/services"
+	"virtual-cuppa-be/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxHandler struct {
+	outboxService services.OutboxService
+}
+
+func NewOutboxHandler(outboxService services.OutboxService) *OutboxHandler {
+	return &OutboxHandler{
+		outboxService: outboxService,
+	}
+}
+
+// GetMessages returns the newest outbox messages of the admin's organisation, optionally
+// filtered by ?status=pending|sent|dead
+func (h *OutboxHandler) GetMessages(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	messages, err := h.outboxService.GetMessages(orgID, models.OutboxStatus(c.Query("status")))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{
+		"messages": messages,
+		"count":    len(messages),
+	})
+}
+
+// GetMessage returns one outbox message with its payload and last error
+func (h *OutboxHandler) GetMessage(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid message ID")
+		return
+	}
+
+	message, err := h.outboxService.GetMessage(orgID, uint(messageID))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, message)
+}
+
+// RetryMessage queues a failed or dead-lettered message for immediate delivery, unless a
+// worker is delivering it
+func (h *OutboxHandler) RetryMessage(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid message ID")
+		return
+	}
+
+	message, err := h.outboxService.RetryMessage(orgID, uint(messageID))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, message)
+}
+
+func (h *OutboxHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidOutboxStatus):
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrOutboxMessageNotFound):
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrOutboxMessageSent), errors.Is(err, services.ErrOutboxMessageLeased):
+		utils.RespondWithError(c, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(c, err)
+	}
+}
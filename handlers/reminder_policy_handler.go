@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/services"
+	"virtual-cuppa-be/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReminderPolicyHandler struct {
+	reminderService services.ReminderService
+}
+
+func NewReminderPolicyHandler(reminderService services.ReminderService) *ReminderPolicyHandler {
+	return &ReminderPolicyHandler{
+		reminderService: reminderService,
+	}
+}
+
+// GetPolicy returns the reminder policy of the admin's organisation, or the default policy
+// if it has not set one
+func (h *ReminderPolicyHandler) GetPolicy(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.reminderService.GetPolicy(orgID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, policy)
+}
+
+// UpdatePolicy replaces the reminder policy of the admin's organisation
+func (h *ReminderPolicyHandler) UpdatePolicy(c *gin.Context) {
+	orgID, ok := adminOrganisationID(c)
+	if !ok {
+		return
+	}
+
+	var input models.UpdateReminderPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	policy, err := h.reminderService.UpdatePolicy(orgID, &input)
+	if errors.Is(err, services.ErrReminderAfterExpiry) {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, policy)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/services"
+	"virtual-cuppa-be/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RescheduleHandler struct {
+	rescheduleService services.RescheduleService
+}
+
+func NewRescheduleHandler(rescheduleService services.RescheduleService) *RescheduleHandler {
+	return &RescheduleHandler{
+		rescheduleService: rescheduleService,
+	}
+}
+
+// GetProposals returns every reschedule proposal made for the match, oldest first
+func (h *RescheduleHandler) GetProposals(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid match ID")
+		return
+	}
+
+	proposals, err := h.rescheduleService.GetProposals(userID.(uint), uint(matchID))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{
+		"proposals": proposals,
+		"count":     len(proposals),
+	})
+}
+
+// ProposeSlots offers alternative slots for an accepted match, or counter-proposes
+// when another participant's proposal is open
+func (h *RescheduleHandler) ProposeSlots(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid match ID")
+		return
+	}
+
+	var input models.ProposeRescheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	proposal, err := h.rescheduleService.ProposeSlots(userID.(uint), uint(matchID), input.Slots)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusCreated, proposal)
+}
+
+// AcceptProposal picks one of the proposed slots and moves the match to it
+func (h *RescheduleHandler) AcceptProposal(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	matchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid match ID")
+		return
+	}
+
+	proposalID, err := strconv.ParseUint(c.Param("proposalId"), 10, 32)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid proposal ID")
+		return
+	}
+
+	var input models.AcceptRescheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	match, err := h.rescheduleService.AcceptProposal(userID.(uint), uint(matchID), uint(proposalID), input.Start)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, match)
+}
+
+func (h *RescheduleHandler) handleError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrMatchNotFound:
+		utils.RespondWithError(c, http.StatusNotFound, "Match not found")
+	case services.ErrUnauthorizedMatch:
+		utils.RespondWithError(c, http.StatusForbidden, "Unauthorized to modify this match")
+	case services.ErrRescheduleNotFound:
+		utils.RespondWithError(c, http.StatusNotFound, err.Error())
+	case services.ErrInvalidRescheduleSlot, services.ErrSlotNotProposed, services.ErrOwnRescheduleProposal:
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+	case services.ErrMatchNotReschedulable, services.ErrReschedulePending, services.ErrRescheduleClosed:
+		utils.RespondWithError(c, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(c, err)
+	}
+}
@@ -128,3 +128,42 @@ func (h *UserAvailabilityConfigHandler) HasConfig(c *gin.Context) {
 		"hasConfig": hasConfig,
 	})
 }
+
+// ImportICS proposes availability from an uploaded calendar file, saving it when apply is set
+func (h *UserAvailabilityConfigHandler) ImportICS(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.ImportAvailabilityInput
+	if err := c.ShouldBind(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "ICS file is required")
+		return
+	}
+	defer file.Close()
+
+	result, err := h.configService.ImportICS(userID.(uint), file, input.Apply)
+	if err != nil {
+		if err == services.ErrInvalidICS {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	if result.Applied {
+		// Try to generate a match for the user with their new availability
+		_ = h.matchService.TryGenerateMatchForUser(userID.(uint))
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, result)
+}
@@ -195,3 +195,93 @@ func (h *UserHandler) UpdateTags(c *gin.Context) {
 
 	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"message": "Tags updated successfully"})
 }
+
+func (h *UserHandler) UpdateSeniority(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	targetUserID := c.Param("userId")
+	if targetUserID == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	var id uint
+	if _, err := fmt.Sscanf(targetUserID, "%d", &id); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var input models.UpdateSeniorityInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.userService.UpdateUserSeniority(userID.(uint), id, *input.SeniorityLevel); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"message": "Seniority level updated successfully"})
+}
+
+// UpdateOffice sets the office whose regional holiday calendars apply to a user
+func (h *UserHandler) UpdateOffice(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	targetUserID := c.Param("userId")
+	if targetUserID == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	var id uint
+	if _, err := fmt.Sscanf(targetUserID, "%d", &id); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var input models.UpdateOfficeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.userService.UpdateUserOffice(userID.(uint), id, input.Office); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, gin.H{"message": "Office updated successfully"})
+}
+
+// UpdateTimeZone sets the time zone the current user's availability and meeting times are read in
+func (h *UserHandler) UpdateTimeZone(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input models.UpdateTimeZoneInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.userService.UpdateTimeZone(userID.(uint), input.TimeZone)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondWithSuccess(c, http.StatusOK, user)
+}
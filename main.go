@@ -4,7 +4,11 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+	// Embed the time zone database so user and organisation zones load on minimal images
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -31,21 +35,82 @@ func main() {
 	matchHistoryRepo := repositories.NewMatchHistoryRepository(config.DB)
 	matchFeedbackRepo := repositories.NewMatchFeedbackRepository(config.DB)
 	userAvailConfigRepo := repositories.NewUserAvailabilityConfigRepository(config.DB)
-	emailService := services.NewEmailService()
-	matchService := services.NewMatchService(matchRepo, matchHistoryRepo, matchFeedbackRepo, userRepo, userAvailConfigRepo, emailService)
+	matchBlockRepo := repositories.NewMatchBlockRepository(config.DB)
+	rescheduleProposalRepo := repositories.NewRescheduleProposalRepository(config.DB)
+	holidayCalendarRepo := repositories.NewHolidayCalendarRepository(config.DB)
+	availabilityOverrideRepo := repositories.NewAvailabilityOverrideRepository(config.DB)
+	notificationChannelRepo := repositories.NewNotificationChannelRepository(config.DB)
+	outboxRepo := repositories.NewOutboxRepository(config.DB)
+	reminderPolicyRepo := repositories.NewReminderPolicyRepository(config.DB)
+	matchReminderRepo := repositories.NewMatchReminderRepository(config.DB)
+	unitOfWork := repositories.NewUnitOfWork(config.DB)
+	var emailService services.EmailService
+	switch backend := os.Getenv("EMAIL_BACKEND"); backend {
+	case "", "sendgrid":
+		emailService = services.NewEmailService()
+	case "smtp":
+		smtpEmailService, err := services.NewSMTPEmailService()
+		if err != nil {
+			log.Fatalf("Failed to load email templates: %v", err)
+		}
+		emailService = smtpEmailService
+	default:
+		log.Fatalf("Unknown EMAIL_BACKEND %q, expected sendgrid or smtp", backend)
+	}
+	notifier := services.NewNotifier(notificationChannelRepo, outboxRepo, emailService)
+	matchService := services.NewMatchService(matchRepo, matchHistoryRepo, matchFeedbackRepo, userRepo, userAvailConfigRepo, orgRepo, matchBlockRepo, holidayCalendarRepo, availabilityOverrideRepo, unitOfWork, notifier)
 	authService := services.NewAuthService(userRepo, emailService, matchService)
-	userService := services.NewUserService(userRepo, orgRepo, tagRepo, emailService)
+	userService := services.NewUserService(userRepo, orgRepo, tagRepo, unitOfWork, notifier)
 	orgService := services.NewOrganisationService(orgRepo)
-	userAvailConfigService := services.NewUserAvailabilityConfigService(userAvailConfigRepo, userRepo)
-	authHandler := handlers.NewAuthHandler(authService)
+	userAvailConfigService := services.NewUserAvailabilityConfigService(userAvailConfigRepo, userRepo, orgRepo)
+	matchBlockService := services.NewMatchBlockService(matchBlockRepo, userRepo)
+	rescheduleService := services.NewRescheduleService(rescheduleProposalRepo, matchRepo, userAvailConfigRepo, orgRepo, availabilityOverrideRepo, holidayCalendarRepo, unitOfWork, notifier)
+	holidayCalendarService := services.NewHolidayCalendarService(holidayCalendarRepo, orgRepo)
+	calendarFeedService := services.NewCalendarFeedService(userRepo, orgRepo, matchRepo)
+	availabilityOverrideService := services.NewAvailabilityOverrideService(availabilityOverrideRepo, userRepo, orgRepo)
+	notificationChannelService := services.NewNotificationChannelService(notificationChannelRepo)
+	outboxService := services.NewOutboxService(outboxRepo, notifier)
+	// Pending matches expire once nobody has answered them for the acceptance window
+	acceptanceWindowHours := 72
+	if v, err := strconv.Atoi(os.Getenv("MATCH_ACCEPTANCE_WINDOW_HOURS")); err == nil && v > 0 {
+		acceptanceWindowHours = v
+	}
+	acceptanceWindow := time.Duration(acceptanceWindowHours) * time.Hour
+	reminderService := services.NewReminderService(reminderPolicyRepo, matchReminderRepo, matchRepo, orgRepo, unitOfWork, notifier, acceptanceWindow)
+	authHandler := handlers.NewAuthHandler(authService, matchService)
 	userHandler := handlers.NewUserHandler(userService)
 	orgHandler := handlers.NewOrganisationHandler(orgService, userService)
 	userAvailConfigHandler := handlers.NewUserAvailabilityConfigHandler(userAvailConfigService, matchService)
+	matchBlockHandler := handlers.NewMatchBlockHandler(matchBlockService)
+	rescheduleHandler := handlers.NewRescheduleHandler(rescheduleService)
+	holidayCalendarHandler := handlers.NewHolidayCalendarHandler(holidayCalendarService)
+	calendarFeedHandler := handlers.NewCalendarFeedHandler(calendarFeedService)
+	matchActionHandler := handlers.NewMatchActionHandler(matchService, os.Getenv("APP_URL"))
+	availabilityOverrideHandler := handlers.NewAvailabilityOverrideHandler(availabilityOverrideService, matchService)
+	notificationChannelHandler := handlers.NewNotificationChannelHandler(notificationChannelService)
+	outboxHandler := handlers.NewOutboxHandler(outboxService)
+	reminderPolicyHandler := handlers.NewReminderPolicyHandler(reminderService)
 
 	// Start match scheduler
-	matchScheduler := scheduler.NewMatchScheduler(matchService, orgRepo)
+	matchScheduler := scheduler.NewMatchScheduler(matchService, holidayCalendarService, orgRepo)
 	matchScheduler.Start()
 
+	// Start expiry sweeper for matches nobody responded to
+	expiryScheduler := scheduler.NewMatchExpiryScheduler(matchService, acceptanceWindow, 15*time.Minute)
+	expiryScheduler.Start()
+
+	// Start outbox worker delivering queued notifications
+	outboxPollSeconds := 30
+	if v, err := strconv.Atoi(os.Getenv("OUTBOX_POLL_SECONDS")); err == nil && v > 0 {
+		outboxPollSeconds = v
+	}
+	outboxWorker := scheduler.NewOutboxWorker(outboxService, time.Duration(outboxPollSeconds)*time.Second)
+	outboxWorker.Start()
+
+	// Start reminders for matches waiting on an answer or on feedback
+	reminderScheduler := scheduler.NewReminderScheduler(reminderService, time.Hour)
+	reminderScheduler.Start()
+
 	matchHandler := handlers.NewMatchHandler(matchService, matchScheduler)
 	feedbackHandler := handlers.NewMatchFeedbackHandler(matchService)
 
@@ -108,10 +173,21 @@ func main() {
 		auth.POST("/refresh", authHandler.RefreshToken)
 	}
 
+	// Calendar clients cannot send bearer tokens, so feeds are authenticated by their secret address
+	router.GET("/api/calendar-feed/:token", calendarFeedHandler.ServeFeed)
+
+	// Accept and reject links of new match emails, authenticated by the signed token
+	router.GET("/api/match-actions/:token", matchActionHandler.ShowAction)
+	router.POST("/api/match-actions/:token", matchActionHandler.TakeAction)
+
 	api := router.Group("/api")
 	api.Use(middleware.AuthRequired())
 	{
 		api.GET("/profile", authHandler.GetProfile)
+		api.PUT("/profile/match-preferences", matchHandler.UpdateMatchPreferences)
+		api.PUT("/profile/time-zone", userHandler.UpdateTimeZone)
+		api.GET("/profile/calendar-feed", calendarFeedHandler.GetFeed)
+		api.POST("/profile/calendar-feed/rotate", calendarFeedHandler.RotateFeedToken)
 		api.GET("/organisation", orgHandler.GetOrganisation)
 		
 		// Availability configuration endpoints
@@ -120,6 +196,10 @@ func main() {
 		api.PUT("/availability-config", userAvailConfigHandler.UpdateConfig)
 		api.DELETE("/availability-config", userAvailConfigHandler.DeleteConfig)
 		api.GET("/availability-config/check", userAvailConfigHandler.HasConfig)
+		api.POST("/availability-config/import-ics", userAvailConfigHandler.ImportICS)
+		api.GET("/availability-config/overrides", availabilityOverrideHandler.GetOverrides)
+		api.POST("/availability-config/overrides", availabilityOverrideHandler.CreateOverride)
+		api.DELETE("/availability-config/overrides/:id", availabilityOverrideHandler.DeleteOverride)
 		
 		// Match endpoints for all authenticated users
 		api.GET("/matches/current", matchHandler.GetCurrentMatch)
@@ -127,12 +207,21 @@ func main() {
 		api.PATCH("/matches/:id/accept", matchHandler.AcceptMatch)
 		api.PATCH("/matches/:id/reject", matchHandler.RejectMatch)
 		api.GET("/matches/:id/availabilities", matchHandler.GetMatchAvailabilities)
+		api.GET("/matches/:id/calendar.ics", matchHandler.GetMatchCalendar)
+		api.GET("/matches/:id/reschedule-proposals", rescheduleHandler.GetProposals)
+		api.POST("/matches/:id/reschedule-proposals", rescheduleHandler.ProposeSlots)
+		api.POST("/matches/:id/reschedule-proposals/:proposalId/accept", rescheduleHandler.AcceptProposal)
 		
 		// Feedback endpoints
 		api.POST("/matches/:id/feedback", feedbackHandler.SubmitFeedback)
 		api.GET("/matches/:id/feedbacks", feedbackHandler.GetMatchFeedbacks)
 		api.GET("/matches/pending-feedback", feedbackHandler.GetPendingFeedback)
 
+		// Personal blocklist ("never match me with")
+		api.GET("/match-blocks", matchBlockHandler.GetBlockedUsers)
+		api.POST("/match-blocks", matchBlockHandler.BlockUser)
+		api.DELETE("/match-blocks/:userId", matchBlockHandler.UnblockUser)
+
 		admin := api.Group("/admin")
 		admin.Use(middleware.AdminRequired())
 		{
@@ -147,13 +236,32 @@ func main() {
 		admin.POST("/users", userHandler.CreateUser)
 		admin.DELETE("/users/:id", userHandler.DeleteUser)
 		admin.PATCH("/users/:userId/tags", userHandler.UpdateTags)
+		admin.PATCH("/users/:userId/seniority", userHandler.UpdateSeniority)
+		admin.PATCH("/users/:userId/office", userHandler.UpdateOffice)
 		admin.GET("/organisation", orgHandler.GetOrganisation)
 		admin.PUT("/organisation", orgHandler.UpsertOrganisation)
 		
 		// Match endpoints for admins
 		admin.POST("/matches/generate", matchHandler.GenerateMatches)
+		admin.POST("/matches/preview", matchHandler.PreviewMatches)
+		admin.POST("/matches/proposals/:proposalId/commit", matchHandler.CommitMatchProposal)
 		admin.POST("/matches/trigger-scheduler", matchHandler.TriggerScheduler)
 		admin.GET("/matches", matchHandler.GetOrganisationMatches)
+		admin.GET("/forbidden-pairs", matchBlockHandler.GetForbiddenPairs)
+		admin.POST("/forbidden-pairs", matchBlockHandler.AddForbiddenPair)
+		admin.DELETE("/forbidden-pairs/:id", matchBlockHandler.RemoveForbiddenPair)
+		admin.GET("/holiday-calendars", holidayCalendarHandler.GetCalendars)
+		admin.POST("/holiday-calendars", holidayCalendarHandler.CreateCalendar)
+		admin.POST("/holiday-calendars/import", holidayCalendarHandler.ImportICS)
+		admin.DELETE("/holiday-calendars/:id", holidayCalendarHandler.DeleteCalendar)
+		admin.GET("/notification-channels", notificationChannelHandler.GetChannels)
+		admin.POST("/notification-channels", notificationChannelHandler.CreateChannel)
+		admin.DELETE("/notification-channels/:id", notificationChannelHandler.DeleteChannel)
+		admin.GET("/outbox", outboxHandler.GetMessages)
+		admin.GET("/outbox/:id", outboxHandler.GetMessage)
+		admin.POST("/outbox/:id/retry", outboxHandler.RetryMessage)
+		admin.GET("/reminder-policy", reminderPolicyHandler.GetPolicy)
+		admin.PUT("/reminder-policy", reminderPolicyHandler.UpdatePolicy)
 		admin.GET("/matches/:id/feedbacks", feedbackHandler.AdminGetMatchFeedbacks)
 		}
 	}
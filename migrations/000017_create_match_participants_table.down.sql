@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_match_histories_match_id;
+ALTER TABLE match_histories DROP COLUMN IF EXISTS match_id;
+
+DROP TABLE IF EXISTS match_participants;
+
+ALTER TABLE organisations DROP CONSTRAINT IF EXISTS check_match_group_size;
+ALTER TABLE organisations DROP COLUMN IF EXISTS match_group_size;
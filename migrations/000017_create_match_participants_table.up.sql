@@ -0,0 +1,34 @@
+-- Group cuppas: every match gets a participant list (pairs have two participants)
+ALTER TABLE organisations ADD COLUMN match_group_size INTEGER NOT NULL DEFAULT 2;
+ALTER TABLE organisations ADD CONSTRAINT check_match_group_size CHECK (match_group_size BETWEEN 2 AND 5);
+
+CREATE TABLE IF NOT EXISTS match_participants (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(match_id, user_id)
+);
+
+CREATE INDEX idx_match_participants_match_id ON match_participants(match_id);
+CREATE INDEX idx_match_participants_user_id ON match_participants(user_id);
+
+-- Backfill participants from the existing pair columns
+INSERT INTO match_participants (match_id, user_id, status, accepted_at, created_at, updated_at)
+SELECT id, user1_id,
+       CASE WHEN user1_accepted THEN 'accepted' ELSE 'pending' END,
+       user1_accepted_at, created_at, updated_at
+FROM matches;
+
+INSERT INTO match_participants (match_id, user_id, status, accepted_at, created_at, updated_at)
+SELECT id, user2_id,
+       CASE WHEN user2_accepted THEN 'accepted' ELSE 'pending' END,
+       user2_accepted_at, created_at, updated_at
+FROM matches;
+
+-- Link history rows to the match that produced them (one row per pair of group members)
+ALTER TABLE match_histories ADD COLUMN match_id INTEGER REFERENCES matches(id) ON DELETE SET NULL;
+CREATE INDEX idx_match_histories_match_id ON match_histories(match_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_matches_status_created_at;
+
+ALTER TABLE matches
+DROP COLUMN IF EXISTS expired_at,
+DROP COLUMN IF EXISTS expiry_reason;
@@ -0,0 +1,5 @@
+ALTER TABLE matches
+ADD COLUMN expired_at TIMESTAMP,
+ADD COLUMN expiry_reason VARCHAR(50);
+
+CREATE INDEX idx_matches_status_created_at ON matches(status, created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE organisations DROP CONSTRAINT IF EXISTS check_rematch_cooldown_days;
+ALTER TABLE organisations DROP CONSTRAINT IF EXISTS check_rematch_policy;
+ALTER TABLE organisations DROP COLUMN IF EXISTS rematch_cooldown_days;
+ALTER TABLE organisations DROP COLUMN IF EXISTS rematch_policy;
@@ -0,0 +1,5 @@
+-- Per-organisation policy for pairing colleagues who already had a cuppa together
+ALTER TABLE organisations ADD COLUMN rematch_policy VARCHAR(20) NOT NULL DEFAULT 'never';
+ALTER TABLE organisations ADD COLUMN rematch_cooldown_days INTEGER NOT NULL DEFAULT 180;
+ALTER TABLE organisations ADD CONSTRAINT check_rematch_policy CHECK (rematch_policy IN ('never', 'after_days', 'after_everyone'));
+ALTER TABLE organisations ADD CONSTRAINT check_rematch_cooldown_days CHECK (rematch_cooldown_days > 0);
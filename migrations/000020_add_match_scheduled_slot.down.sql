@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_matches_scheduled_start;
+
+ALTER TABLE matches DROP COLUMN IF EXISTS scheduled_end;
+ALTER TABLE matches DROP COLUMN IF EXISTS scheduled_start;
+
+ALTER TABLE organisations DROP CONSTRAINT IF EXISTS check_cuppa_duration_minutes;
+ALTER TABLE organisations DROP CONSTRAINT IF EXISTS check_working_hours;
+ALTER TABLE organisations DROP COLUMN IF EXISTS cuppa_duration_minutes;
+ALTER TABLE organisations DROP COLUMN IF EXISTS working_hours_end;
+ALTER TABLE organisations DROP COLUMN IF EXISTS working_hours_start;
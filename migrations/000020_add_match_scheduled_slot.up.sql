@@ -0,0 +1,19 @@
+-- Working hours and cuppa length used to pick a concrete meeting slot
+ALTER TABLE organisations ADD COLUMN working_hours_start INTEGER NOT NULL DEFAULT 9;
+ALTER TABLE organisations ADD COLUMN working_hours_end INTEGER NOT NULL DEFAULT 17;
+ALTER TABLE organisations ADD COLUMN cuppa_duration_minutes INTEGER NOT NULL DEFAULT 30;
+ALTER TABLE organisations ADD CONSTRAINT check_working_hours CHECK (working_hours_start >= 0 AND working_hours_end <= 24 AND working_hours_start < working_hours_end);
+ALTER TABLE organisations ADD CONSTRAINT check_cuppa_duration_minutes CHECK (cuppa_duration_minutes BETWEEN 10 AND 240);
+
+-- Structured meeting slot on matches
+ALTER TABLE matches ADD COLUMN scheduled_start TIMESTAMP;
+ALTER TABLE matches ADD COLUMN scheduled_end TIMESTAMP;
+
+-- Convert legacy "3 PM" style times into a slot and a "15:00" start time
+UPDATE matches
+SET scheduled_start = scheduled_date::date + to_timestamp(scheduled_time, 'HH12 AM')::time,
+    scheduled_end = scheduled_date::date + to_timestamp(scheduled_time, 'HH12 AM')::time + INTERVAL '30 minutes',
+    scheduled_time = to_char(to_timestamp(scheduled_time, 'HH12 AM'), 'HH24:MI')
+WHERE scheduled_time ~ '^[0-9]{1,2} (AM|PM)$';
+
+CREATE INDEX idx_matches_scheduled_start ON matches(scheduled_start);
@@ -0,0 +1,4 @@
+ALTER TABLE organisations DROP COLUMN IF EXISTS scoring_weights;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS check_seniority_level;
+ALTER TABLE users DROP COLUMN IF EXISTS seniority_level;
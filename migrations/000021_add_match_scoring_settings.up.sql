@@ -0,0 +1,7 @@
+-- Seniority level used by the seniority spread scoring strategy
+ALTER TABLE users ADD COLUMN seniority_level SMALLINT;
+ALTER TABLE users ADD CONSTRAINT check_seniority_level CHECK (seniority_level BETWEEN 1 AND 10);
+
+-- Per-organisation scoring strategy weights, e.g. {"tag_overlap": 2, "random": 1}
+-- NULL means tag overlap only
+ALTER TABLE organisations ADD COLUMN scoring_weights JSONB;
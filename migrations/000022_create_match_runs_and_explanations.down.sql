@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_matches_run_id;
+ALTER TABLE matches DROP COLUMN IF EXISTS explanation;
+ALTER TABLE matches DROP COLUMN IF EXISTS run_id;
+
+DROP TABLE IF EXISTS match_runs;
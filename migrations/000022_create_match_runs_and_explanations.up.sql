@@ -0,0 +1,20 @@
+-- One row per execution of match generation
+CREATE TABLE IF NOT EXISTS match_runs (
+    id SERIAL PRIMARY KEY,
+    organisation_id INTEGER NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    trigger VARCHAR(20) NOT NULL,
+    eligible_users INTEGER NOT NULL DEFAULT 0,
+    matches_created INTEGER NOT NULL DEFAULT 0,
+    unmatched_count INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_match_runs_organisation_id ON match_runs(organisation_id);
+
+-- Why the members of a match were put together (shared tags, common slots, score breakdown)
+ALTER TABLE matches ADD COLUMN run_id INTEGER REFERENCES match_runs(id) ON DELETE SET NULL;
+ALTER TABLE matches ADD COLUMN explanation JSONB;
+
+CREATE INDEX idx_matches_run_id ON matches(run_id);
@@ -0,0 +1,16 @@
+-- Pairs of users who must never be matched: personal blocks (source 'user') are private
+-- to the user who created them, forbidden pairs (source 'admin') are managed by admins
+CREATE TABLE IF NOT EXISTS match_blocks (
+    id SERIAL PRIMARY KEY,
+    organisation_id INTEGER NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    created_by_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_match_block_not_self CHECK (user_id <> blocked_user_id),
+    CONSTRAINT idx_match_block_pair UNIQUE (user_id, blocked_user_id, source)
+);
+
+CREATE INDEX idx_match_blocks_organisation_id ON match_blocks(organisation_id);
+CREATE INDEX idx_match_blocks_blocked_user_id ON match_blocks(blocked_user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS paused_until;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS check_match_cadence;
+ALTER TABLE users DROP COLUMN IF EXISTS match_cadence;
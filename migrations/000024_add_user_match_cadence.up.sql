@@ -0,0 +1,4 @@
+-- How often a user wants a new cuppa and an optional pause (holidays, busy periods)
+ALTER TABLE users ADD COLUMN match_cadence VARCHAR(20) NOT NULL DEFAULT 'weekly';
+ALTER TABLE users ADD CONSTRAINT check_match_cadence CHECK (match_cadence IN ('weekly', 'fortnightly', 'monthly'));
+ALTER TABLE users ADD COLUMN paused_until TIMESTAMP;
@@ -0,0 +1,4 @@
+DELETE FROM match_blocks WHERE source = 'rejection';
+ALTER TABLE match_blocks DROP COLUMN IF EXISTS expires_at;
+
+ALTER TABLE match_participants DROP COLUMN IF EXISTS rejection_reason;
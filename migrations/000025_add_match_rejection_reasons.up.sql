@@ -0,0 +1,5 @@
+-- Why a participant rejected a match (bad_timing, dont_want_to_meet, on_leave)
+ALTER TABLE match_participants ADD COLUMN rejection_reason VARCHAR(30);
+
+-- Soft exclusions (source 'rejection') stop applying once they expire
+ALTER TABLE match_blocks ADD COLUMN expires_at TIMESTAMP;
@@ -0,0 +1,19 @@
+-- Alternative slots offered for an accepted match; rows are kept as the match's history
+CREATE TABLE IF NOT EXISTS reschedule_proposals (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    proposed_by_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    slots JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    accepted_start TIMESTAMP,
+    responded_by_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_reschedule_status CHECK (status IN ('pending', 'accepted', 'countered'))
+);
+
+CREATE INDEX idx_reschedule_proposals_match_id ON reschedule_proposals(match_id);
+
+-- At most one open proposal per match
+CREATE UNIQUE INDEX idx_reschedule_proposals_pending ON reschedule_proposals(match_id) WHERE status = 'pending';
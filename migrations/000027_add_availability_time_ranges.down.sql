@@ -0,0 +1,2 @@
+-- The half-day columns are kept in sync with the ranges, so nothing else needs converting back
+ALTER TABLE user_availability_configs DROP COLUMN IF EXISTS time_ranges;
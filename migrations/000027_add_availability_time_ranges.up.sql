@@ -0,0 +1,24 @@
+-- Weekly availability as time ranges, e.g. [{"day": "Tuesday", "start": "12:00", "end": "13:30"}].
+-- The half-day columns stay and mirror the ranges for older clients.
+ALTER TABLE user_availability_configs ADD COLUMN time_ranges JSONB NOT NULL DEFAULT '[]';
+
+-- Convert existing half-day flags: mornings are 00:00-12:00 and afternoons 12:00-24:00
+-- (scheduling clips them to the organisation's working hours as before)
+UPDATE user_availability_configs c SET time_ranges = COALESCE((
+    SELECT jsonb_agg(
+        jsonb_build_object(
+            'day', d.day,
+            'start', CASE WHEN d.morning THEN '00:00' ELSE '12:00' END,
+            'end', CASE WHEN d.afternoon THEN '24:00' ELSE '12:00' END
+        ) ORDER BY d.ord)
+    FROM (VALUES
+        (1, 'Monday', c.monday_morning, c.monday_afternoon),
+        (2, 'Tuesday', c.tuesday_morning, c.tuesday_afternoon),
+        (3, 'Wednesday', c.wednesday_morning, c.wednesday_afternoon),
+        (4, 'Thursday', c.thursday_morning, c.thursday_afternoon),
+        (5, 'Friday', c.friday_morning, c.friday_afternoon),
+        (6, 'Saturday', c.saturday_morning, c.saturday_afternoon),
+        (7, 'Sunday', c.sunday_morning, c.sunday_afternoon)
+    ) AS d(ord, day, morning, afternoon)
+    WHERE d.morning OR d.afternoon
+), '[]');
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS time_zone;
+ALTER TABLE organisations DROP COLUMN IF EXISTS time_zone;
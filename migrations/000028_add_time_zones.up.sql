@@ -0,0 +1,4 @@
+-- IANA time zones: working hours are read in the organisation's zone and each user's
+-- availability in their own, falling back to the organisation's when unset
+ALTER TABLE organisations ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE users ADD COLUMN time_zone VARCHAR(64);
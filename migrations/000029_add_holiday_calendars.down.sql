@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS office;
+DROP TABLE IF EXISTS calendar_dates;
+DROP TABLE IF EXISTS holiday_calendars;
@@ -0,0 +1,27 @@
+-- Days on which no cuppas are scheduled: public holidays and company blackout periods,
+-- either for the whole organisation or for the users of one office
+CREATE TABLE IF NOT EXISTS holiday_calendars (
+    id SERIAL PRIMARY KEY,
+    organisation_id INTEGER NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'holiday',
+    office VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_holiday_calendar_kind CHECK (kind IN ('holiday', 'blackout'))
+);
+
+CREATE INDEX idx_holiday_calendars_organisation_id ON holiday_calendars(organisation_id);
+
+CREATE TABLE IF NOT EXISTS calendar_dates (
+    id SERIAL PRIMARY KEY,
+    calendar_id INTEGER NOT NULL REFERENCES holiday_calendars(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    name VARCHAR(255),
+    CONSTRAINT idx_calendar_dates_calendar_date UNIQUE (calendar_id, date)
+);
+
+CREATE INDEX idx_calendar_dates_date ON calendar_dates(date);
+
+-- Office a user works from, selecting their regional holiday calendars
+ALTER TABLE users ADD COLUMN office VARCHAR(100);
@@ -0,0 +1,2 @@
+-- SEQUENCE of a match's calendar invite, bumped whenever the invite is updated or cancelled
+ALTER TABLE matches ADD COLUMN calendar_sequence INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Secret token of the user's subscribable calendar feed, created on first use
+ALTER TABLE users ADD COLUMN calendar_feed_token VARCHAR(64);
+CREATE UNIQUE INDEX idx_users_calendar_feed_token ON users(calendar_feed_token);
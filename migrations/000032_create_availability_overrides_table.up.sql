@@ -0,0 +1,17 @@
+-- Dated changes to a user's weekly availability, e.g. annual leave or a one-off free afternoon
+CREATE TABLE IF NOT EXISTS availability_overrides (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    start_time VARCHAR(5),
+    end_time VARCHAR(5),
+    note VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_availability_override_kind CHECK (kind IN ('unavailable', 'available')),
+    CONSTRAINT check_availability_override_dates CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_availability_overrides_user_id_end_date ON availability_overrides(user_id, end_date);
@@ -0,0 +1,16 @@
+-- Where an organisation's notifications are delivered: email, Slack, Teams or a generic
+-- webhook, each for all events or a chosen list
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id SERIAL PRIMARY KEY,
+    organisation_id INTEGER NOT NULL REFERENCES organisations(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    url VARCHAR(2048),
+    secret VARCHAR(255),
+    events JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_notification_channel_kind CHECK (kind IN ('email', 'slack', 'teams', 'webhook'))
+);
+
+CREATE INDEX idx_notification_channels_organisation_id ON notification_channels(organisation_id);
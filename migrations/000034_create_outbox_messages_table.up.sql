@@ -0,0 +1,22 @@
+-- Notifications waiting to be delivered, one row per channel, written with the change they
+-- describe and delivered with retries by the outbox worker
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id SERIAL PRIMARY KEY,
+    organisation_id INTEGER NOT NULL DEFAULT 0,
+    event VARCHAR(50) NOT NULL,
+    channel_id INTEGER,
+    channel_kind VARCHAR(20) NOT NULL,
+    recipient VARCHAR(255),
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_outbox_message_status CHECK (status IN ('pending', 'sent', 'dead'))
+);
+
+CREATE INDEX idx_outbox_messages_organisation_id ON outbox_messages(organisation_id);
+CREATE INDEX idx_outbox_messages_due ON outbox_messages(next_attempt_at) WHERE status = 'pending';
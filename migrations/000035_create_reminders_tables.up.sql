@@ -0,0 +1,25 @@
+-- Per-organisation reminder campaigns, and the reminders already sent so nobody is
+-- reminded twice by the same campaign
+CREATE TABLE IF NOT EXISTS reminder_policies (
+    id SERIAL PRIMARY KEY,
+    organisation_id INTEGER NOT NULL UNIQUE REFERENCES organisations(id) ON DELETE CASCADE,
+    acceptance_reminder_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    acceptance_reminder_after_days INTEGER NOT NULL DEFAULT 2,
+    feedback_reminder_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    feedback_reminder_hours_before INTEGER NOT NULL DEFAULT 24,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_acceptance_reminder_after_days CHECK (acceptance_reminder_after_days > 0),
+    CONSTRAINT check_feedback_reminder_hours_before CHECK (feedback_reminder_hours_before > 0)
+);
+
+CREATE TABLE IF NOT EXISTS match_reminders (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_match_reminder_kind CHECK (kind IN ('acceptance', 'feedback'))
+);
+
+CREATE UNIQUE INDEX idx_match_reminder ON match_reminders(match_id, user_id, kind);
@@ -0,0 +1,7 @@
+ALTER TABLE matches
+    ALTER COLUMN scheduled_start TYPE TIMESTAMP USING scheduled_start AT TIME ZONE 'UTC',
+    ALTER COLUMN scheduled_end TYPE TIMESTAMP USING scheduled_end AT TIME ZONE 'UTC',
+    ALTER COLUMN expires_at TYPE TIMESTAMP USING expires_at AT TIME ZONE 'UTC';
+
+ALTER TABLE reschedule_proposals
+    ALTER COLUMN accepted_start TYPE TIMESTAMP USING accepted_start AT TIME ZONE 'UTC';
@@ -0,0 +1,17 @@
+-- Store match slots and feedback deadlines as instants. Slots were written on the
+-- organisation's clock into columns without a time zone, so they are read back on that clock.
+ALTER TABLE matches
+    ALTER COLUMN scheduled_start TYPE TIMESTAMPTZ USING scheduled_start AT TIME ZONE 'UTC',
+    ALTER COLUMN scheduled_end TYPE TIMESTAMPTZ USING scheduled_end AT TIME ZONE 'UTC',
+    ALTER COLUMN expires_at TYPE TIMESTAMPTZ USING expires_at AT TIME ZONE 'UTC';
+
+UPDATE matches m
+SET scheduled_start = (m.scheduled_start AT TIME ZONE 'UTC') AT TIME ZONE o.time_zone,
+    scheduled_end = (m.scheduled_end AT TIME ZONE 'UTC') AT TIME ZONE o.time_zone
+FROM organisations o
+WHERE o.id = m.organisation_id
+  AND o.time_zone <> 'UTC'
+  AND m.scheduled_start IS NOT NULL;
+
+ALTER TABLE reschedule_proposals
+    ALTER COLUMN accepted_start TYPE TIMESTAMPTZ USING accepted_start AT TIME ZONE 'UTC';
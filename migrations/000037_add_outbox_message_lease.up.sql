@@ -0,0 +1,2 @@
+-- When a worker's claim on a message runs out; null while no worker is delivering it
+ALTER TABLE outbox_messages ADD COLUMN leased_until TIMESTAMP;
@@ -0,0 +1,4 @@
+ALTER TABLE reminder_policies ADD COLUMN acceptance_reminder_after_days INTEGER NOT NULL DEFAULT 2;
+UPDATE reminder_policies SET acceptance_reminder_after_days = GREATEST((72 - acceptance_reminder_hours_before) / 24, 1);
+ALTER TABLE reminder_policies ADD CONSTRAINT check_acceptance_reminder_after_days CHECK (acceptance_reminder_after_days > 0);
+ALTER TABLE reminder_policies DROP COLUMN acceptance_reminder_hours_before;
@@ -0,0 +1,7 @@
+-- Acceptance reminders are timed back from when the pending match expires, so no policy can
+-- fall after the acceptance window. Existing policies keep their send time under the default
+-- 72 hour window; ones that never fired now go an hour before expiry.
+ALTER TABLE reminder_policies ADD COLUMN acceptance_reminder_hours_before INTEGER NOT NULL DEFAULT 24;
+UPDATE reminder_policies SET acceptance_reminder_hours_before = GREATEST(72 - 24 * acceptance_reminder_after_days, 1);
+ALTER TABLE reminder_policies ADD CONSTRAINT check_acceptance_reminder_hours_before CHECK (acceptance_reminder_hours_before > 0);
+ALTER TABLE reminder_policies DROP COLUMN acceptance_reminder_after_days;
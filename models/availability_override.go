@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// OverrideKind says how a dated override changes a user's weekly availability
+type OverrideKind string
+
+const (
+	// OverrideKindUnavailable takes time out of the weekly availability, e.g. annual leave
+	OverrideKindUnavailable OverrideKind = "unavailable"
+	// OverrideKindAvailable replaces the weekly availability on its days: the user is free
+	// only in the available overrides of that day
+	OverrideKindAvailable OverrideKind = "available"
+)
+
+// AvailabilityOverride changes a user's availability on specific dates. It applies on the
+// user's own clock from StartDate to EndDate inclusive and expires after EndDate.
+type AvailabilityOverride struct {
+	ID        uint         `gorm:"primarykey" json:"id"`
+	UserID    uint         `gorm:"not null;index" json:"userId"`
+	Kind      OverrideKind `gorm:"type:varchar(20);not null" json:"kind"`
+	StartDate time.Time    `gorm:"type:date;not null" json:"startDate"`
+	EndDate   time.Time    `gorm:"type:date;not null" json:"endDate"`
+	// StartTime and EndTime limit the override to part of each day as "15:04";
+	// both empty covers whole days
+	StartTime string    `gorm:"type:varchar(5)" json:"startTime,omitempty"`
+	EndTime   string    `gorm:"type:varchar(5)" json:"endTime,omitempty"`
+	Note      string    `gorm:"type:varchar(255)" json:"note,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// DayWindow returns the minutes of each day the override covers
+func (o *AvailabilityOverride) DayWindow() (int, int, error) {
+	if o.StartTime == "" && o.EndTime == "" {
+		return 0, minutesPerDay, nil
+	}
+	start, ok := parseClock(o.StartTime)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid start time %q", o.StartTime)
+	}
+	end, ok := parseClock(o.EndTime)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid end time %q", o.EndTime)
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("override %s-%s must end after it starts", o.StartTime, o.EndTime)
+	}
+	return start, end, nil
+}
+
+// Intervals places the override on loc's calendar, one interval per day, keeping the part of
+// each day between the given minutes
+func (o *AvailabilityOverride) Intervals(loc *time.Location, clipStart, clipEnd int) []Interval {
+	start, end, err := o.DayWindow()
+	if err != nil {
+		return nil
+	}
+	if start < clipStart {
+		start = clipStart
+	}
+	if end > clipEnd {
+		end = clipEnd
+	}
+	if end <= start {
+		return nil
+	}
+
+	var intervals []Interval
+	for date := o.StartDate.UTC(); !date.After(o.EndDate.UTC()); date = date.AddDate(0, 0, 1) {
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+		intervals = append(intervals, Interval{Start: atMinute(day, start), End: atMinute(day, end)})
+	}
+	return intervals
+}
+
+// Days returns the whole days the override falls on, on loc's calendar
+func (o *AvailabilityOverride) Days(loc *time.Location) []Interval {
+	var days []Interval
+	for date := o.StartDate.UTC(); !date.After(o.EndDate.UTC()); date = date.AddDate(0, 0, 1) {
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+		days = append(days, Interval{Start: day, End: day.AddDate(0, 0, 1)})
+	}
+	return days
+}
+
+// CreateAvailabilityOverrideInput represents input for adding a dated override
+type CreateAvailabilityOverrideInput struct {
+	Kind OverrideKind `json:"kind" binding:"required,oneof=unavailable available"`
+	// StartDate and EndDate are "2006-01-02"; EndDate defaults to StartDate
+	StartDate string `json:"startDate" binding:"required"`
+	EndDate   string `json:"endDate"`
+	// StartTime and EndTime are "15:04" and must be given together; omit both for whole days
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Note      string `json:"note" binding:"max=255"`
+}
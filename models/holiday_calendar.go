@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// CalendarKind says what a holiday calendar's days mean for matching
+type CalendarKind string
+
+const (
+	// CalendarKindHoliday marks public holidays: no cuppas are scheduled on these days
+	CalendarKindHoliday CalendarKind = "holiday"
+	// CalendarKindBlackout marks company shutdowns: no cuppas are scheduled and, for
+	// organisation-wide calendars, automatic match generation does not run
+	CalendarKindBlackout CalendarKind = "blackout"
+)
+
+// HolidayCalendar is a set of days on which an organisation, or one of its offices, does
+// not hold cuppas
+type HolidayCalendar struct {
+	ID             uint         `gorm:"primarykey" json:"id"`
+	OrganisationID uint         `gorm:"not null;index" json:"organisationId"`
+	Name           string       `gorm:"type:varchar(255);not null" json:"name"`
+	Kind           CalendarKind `gorm:"type:varchar(20);not null;default:'holiday'" json:"kind"`
+	// Office limits the calendar to users in that office; empty applies to the whole organisation
+	Office    string         `gorm:"type:varchar(100)" json:"office,omitempty"`
+	Dates     []CalendarDate `gorm:"foreignKey:CalendarID" json:"dates"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// AppliesTo reports whether the calendar's days are closed for the user
+func (c *HolidayCalendar) AppliesTo(user *User) bool {
+	return c.Office == "" || c.Office == user.Office
+}
+
+// CalendarDate is a single closed day of a holiday calendar
+type CalendarDate struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CalendarID uint      `gorm:"not null;index" json:"calendarId"`
+	Date       time.Time `gorm:"type:date;not null" json:"date"`
+	Name       string    `gorm:"type:varchar(255)" json:"name,omitempty"`
+}
+
+// DateKey returns the day as "2006-01-02"
+func (d CalendarDate) DateKey() string {
+	return d.Date.UTC().Format("2006-01-02")
+}
+
+type CalendarDateInput struct {
+	// Date is the closed day as "2006-01-02"
+	Date string `json:"date" binding:"required"`
+	Name string `json:"name"`
+}
+
+type CreateHolidayCalendarInput struct {
+	Name   string              `json:"name" binding:"required"`
+	Kind   CalendarKind        `json:"kind" binding:"omitempty,oneof=holiday blackout"`
+	Office string              `json:"office" binding:"max=100"`
+	Dates  []CalendarDateInput `json:"dates" binding:"required,min=1,dive"`
+}
+
+// ImportHolidayCalendarInput holds the form fields sent with an ICS upload
+type ImportHolidayCalendarInput struct {
+	Name   string       `form:"name" binding:"required"`
+	Kind   CalendarKind `form:"kind" binding:"omitempty,oneof=holiday blackout"`
+	Office string       `form:"office" binding:"max=100"`
+}
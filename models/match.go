@@ -19,6 +19,29 @@ const (
 	MatchStatusCompleted         MatchStatus = "completed"
 )
 
+type ParticipantStatus string
+
+const (
+	ParticipantStatusPending  ParticipantStatus = "pending"
+	ParticipantStatusAccepted ParticipantStatus = "accepted"
+	ParticipantStatusRejected ParticipantStatus = "rejected"
+)
+
+// RejectionReason is the optional reason a participant gives for rejecting a match
+type RejectionReason string
+
+const (
+	RejectionReasonBadTiming      RejectionReason = "bad_timing"
+	RejectionReasonDontWantToMeet RejectionReason = "dont_want_to_meet"
+	RejectionReasonOnLeave        RejectionReason = "on_leave"
+)
+
+// Reasons recorded on a match when the expiry sweeper moves it to expired
+const (
+	ExpiryReasonAcceptanceWindowElapsed = "acceptance_window_elapsed"
+	ExpiryReasonFeedbackWindowElapsed   = "feedback_window_elapsed"
+)
+
 // Availability represents user's available time slots by weekday
 // Format: {"Monday": ["morning", "afternoon"], "Tuesday": ["afternoon"], "Wednesday": ["morning"]}
 // Valid days: Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday
@@ -46,6 +69,56 @@ func (a Availability) Value() (driver.Value, error) {
 	return json.Marshal(a)
 }
 
+// MatchExplanation records why the members of a match were put together
+type MatchExplanation struct {
+	// SharedTags are tags every member has
+	SharedTags []string `json:"sharedTags"`
+	// CommonSlots are the weekly windows every member is available in, e.g. "Tuesday 12:00-13:30"
+	CommonSlots []string `json:"commonSlots"`
+	// ScoreBreakdown is the score of each scoring strategy (averaged over member pairs for groups)
+	ScoreBreakdown map[string]float64 `json:"scoreBreakdown"`
+	// Repeat is set when some of the members have been matched before
+	Repeat bool  `json:"repeat"`
+	RunID  *uint `json:"runId,omitempty"`
+}
+
+// Scan implements sql.Scanner interface
+func (e *MatchExplanation) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, e)
+}
+
+// Value implements driver.Valuer interface
+func (e MatchExplanation) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+// Match run triggers
+const (
+	MatchRunTriggerBatch    = "batch"
+	MatchRunTriggerProposal = "proposal"
+	MatchRunTriggerRematch  = "rematch"
+)
+
+// MatchRun records one execution of match generation for an organisation
+type MatchRun struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	OrganisationID uint       `gorm:"not null;index" json:"organisationId"`
+	Trigger        string     `gorm:"type:varchar(20);not null" json:"trigger"`
+	EligibleUsers  int        `gorm:"not null;default:0" json:"eligibleUsers"`
+	MatchesCreated int        `gorm:"not null;default:0" json:"matchesCreated"`
+	UnmatchedCount int        `gorm:"not null;default:0" json:"unmatchedCount"`
+	StartedAt      time.Time  `gorm:"not null" json:"startedAt"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
 type Match struct {
 	ID                  uint           `gorm:"primarykey" json:"id"`
 	OrganisationID      uint           `gorm:"not null;index" json:"organisationId"`
@@ -60,17 +133,101 @@ type Match struct {
 	User1AcceptedAt     *time.Time     `json:"user1AcceptedAt,omitempty"`
 	User2AcceptedAt     *time.Time     `json:"user2AcceptedAt,omitempty"`
 	ExpiresAt           *time.Time     `json:"expiresAt,omitempty"`
+	ExpiredAt           *time.Time     `json:"expiredAt,omitempty"`
+	ExpiryReason        string         `gorm:"type:varchar(50)" json:"expiryReason,omitempty"`
+	Participants        []*MatchParticipant `gorm:"foreignKey:MatchID" json:"participants,omitempty"`
 	Availabilities      []*MatchAvailability `gorm:"foreignKey:MatchID" json:"availabilities,omitempty"`
 	Feedbacks           []*MatchFeedback `gorm:"foreignKey:MatchID" json:"feedbacks,omitempty"`
 	ScheduledDate       time.Time      `json:"scheduledDate"`
+	// ScheduledTime is the local start time as "15:04"; ScheduledStart/End hold the exact slot
 	ScheduledTime       string         `gorm:"type:varchar(10)" json:"scheduledTime"`
+	ScheduledStart      *time.Time     `gorm:"index" json:"scheduledStart,omitempty"`
+	ScheduledEnd        *time.Time     `json:"scheduledEnd,omitempty"`
+	// CalendarSequence is the SEQUENCE of the match's calendar invite, bumped on every update
+	CalendarSequence    int            `gorm:"not null;default:0" json:"-"`
+	RunID               *uint          `gorm:"index" json:"runId,omitempty"`
+	Run                 *MatchRun      `gorm:"foreignKey:RunID" json:"run,omitempty"`
+	Explanation         *MatchExplanation `gorm:"type:jsonb" json:"explanation,omitempty"`
 	CreatedAt           time.Time      `json:"createdAt"`
 	UpdatedAt           time.Time      `json:"updatedAt"`
 	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// MatchParticipant links a user to a match. Every match has at least two participants;
+// group cuppas have three or more. User1ID/User2ID on Match mirror the first two participants.
+type MatchParticipant struct {
+	ID              uint              `gorm:"primarykey" json:"id"`
+	MatchID         uint              `gorm:"not null;index;uniqueIndex:idx_participant_match_user" json:"matchId"`
+	UserID          uint              `gorm:"not null;index;uniqueIndex:idx_participant_match_user" json:"userId"`
+	User            *User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Status          ParticipantStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
+	AcceptedAt      *time.Time        `json:"acceptedAt,omitempty"`
+	// RejectionReason is never returned: it would tell partners and admins who doesn't want
+	// to meet whom
+	RejectionReason RejectionReason   `gorm:"type:varchar(30)" json:"-"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+}
+
+// IsGroup reports whether the match has more than two participants
+func (m *Match) IsGroup() bool {
+	return len(m.Participants) > 2
+}
+
+// Participant returns the participant entry for the given user, or nil
+func (m *Match) Participant(userID uint) *MatchParticipant {
+	for _, p := range m.Participants {
+		if p.UserID == userID {
+			return p
+		}
+	}
+	return nil
+}
+
+// ActiveParticipants returns participants who have not rejected the match
+func (m *Match) ActiveParticipants() []*MatchParticipant {
+	var active []*MatchParticipant
+	for _, p := range m.Participants {
+		if p.Status != ParticipantStatusRejected {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// IsActiveParticipant reports whether the user takes part in the match and has not rejected it
+func (m *Match) IsActiveParticipant(userID uint) bool {
+	p := m.Participant(userID)
+	return p != nil && p.Status != ParticipantStatusRejected
+}
+
+// AllAccepted reports whether every active participant has accepted
+func (m *Match) AllAccepted() bool {
+	active := m.ActiveParticipants()
+	if len(active) < 2 {
+		return false
+	}
+	for _, p := range active {
+		if p.Status != ParticipantStatusAccepted {
+			return false
+		}
+	}
+	return true
+}
+
+// ParticipantUserIDs returns the user IDs of all participants
+func (m *Match) ParticipantUserIDs() []uint {
+	ids := make([]uint, 0, len(m.Participants))
+	for _, p := range m.Participants {
+		ids = append(ids, p.UserID)
+	}
+	return ids
+}
+
+// MatchHistory records that two users met. Group cuppas add one row per pair of members.
 type MatchHistory struct {
 	ID        uint      `gorm:"primarykey" json:"id"`
+	MatchID   *uint     `gorm:"index" json:"matchId,omitempty"`
 	User1ID   uint      `gorm:"not null;index:idx_match_history" json:"user1Id"`
 	User2ID   uint      `gorm:"not null;index:idx_match_history" json:"user2Id"`
 	MatchedAt time.Time `gorm:"not null" json:"matchedAt"`
@@ -101,3 +258,7 @@ type MatchFeedback struct {
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
+
+type RejectMatchInput struct {
+	Reason RejectionReason `json:"reason,omitempty" binding:"omitempty,oneof=bad_timing dont_want_to_meet on_leave"`
+}
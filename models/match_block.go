@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type MatchBlockSource string
+
+const (
+	// MatchBlockSourceUser is a personal "never match me with" entry, visible only to its creator
+	MatchBlockSourceUser MatchBlockSource = "user"
+	// MatchBlockSourceAdmin is a forbidden pair managed by the organisation's admins
+	MatchBlockSourceAdmin MatchBlockSource = "admin"
+	// MatchBlockSourceRejection is a soft exclusion recorded when a user rejects a match because
+	// they don't want to meet the other person. It is never shown to anyone and expires.
+	MatchBlockSourceRejection MatchBlockSource = "rejection"
+)
+
+// MatchBlock prevents two users from being matched together, for good or until ExpiresAt
+type MatchBlock struct {
+	ID             uint             `gorm:"primarykey" json:"id"`
+	OrganisationID uint             `gorm:"not null;index" json:"organisationId"`
+	UserID         uint             `gorm:"not null;index;uniqueIndex:idx_match_block_pair" json:"userId"`
+	User           *User            `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	BlockedUserID  uint             `gorm:"not null;index;uniqueIndex:idx_match_block_pair" json:"blockedUserId"`
+	BlockedUser    *User            `gorm:"foreignKey:BlockedUserID" json:"blockedUser,omitempty"`
+	Source         MatchBlockSource `gorm:"type:varchar(20);not null;uniqueIndex:idx_match_block_pair" json:"source"`
+	CreatedByID    uint             `gorm:"not null" json:"-"`
+	ExpiresAt      *time.Time       `json:"expiresAt,omitempty"`
+	CreatedAt      time.Time        `json:"createdAt"`
+}
+
+type BlockUserInput struct {
+	UserID uint `json:"userId" binding:"required"`
+}
+
+type ForbiddenPairInput struct {
+	User1ID uint `json:"user1Id" binding:"required"`
+	User2ID uint `json:"user2Id" binding:"required"`
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// NotificationEvent names something users are notified about
+type NotificationEvent string
+
+const (
+	// NotificationUserInvited is sent to a user an admin has just added
+	NotificationUserInvited NotificationEvent = "user_invited"
+	// NotificationMatchCreated is sent to every participant of a new match
+	NotificationMatchCreated NotificationEvent = "match_created"
+	// NotificationAcceptanceReminder is sent to participants who haven't answered a match
+	// some days after it was created
+	NotificationAcceptanceReminder NotificationEvent = "acceptance_reminder"
+	// NotificationPartnerAccepted is sent to the other participants when someone accepts
+	NotificationPartnerAccepted NotificationEvent = "partner_accepted"
+	// NotificationMatchConfirmed is sent to every participant once everyone has accepted
+	NotificationMatchConfirmed NotificationEvent = "match_confirmed"
+	// NotificationMatchCancelled is sent when a confirmed match will not take place
+	NotificationMatchCancelled NotificationEvent = "match_cancelled"
+	// NotificationRescheduleProposed is sent when someone proposes new times
+	NotificationRescheduleProposed NotificationEvent = "reschedule_proposed"
+	// NotificationRescheduleAccepted is sent when a proposed time is accepted
+	NotificationRescheduleAccepted NotificationEvent = "reschedule_accepted"
+	// NotificationFeedbackReminder is sent to participants who haven't given feedback
+	// before the feedback window closes
+	NotificationFeedbackReminder NotificationEvent = "feedback_reminder"
+)
+
+// NotificationEvents lists every event, in the order they happen to a user
+var NotificationEvents = []NotificationEvent{
+	NotificationUserInvited,
+	NotificationMatchCreated,
+	NotificationAcceptanceReminder,
+	NotificationPartnerAccepted,
+	NotificationMatchConfirmed,
+	NotificationMatchCancelled,
+	NotificationRescheduleProposed,
+	NotificationRescheduleAccepted,
+	NotificationFeedbackReminder,
+}
+
+// IsPersonal reports whether the event is about one user falling behind. Posted to a shared
+// channel it would single them out, so chat and webhook channels only get personal events
+// they list explicitly.
+func (e NotificationEvent) IsPersonal() bool {
+	return e == NotificationAcceptanceReminder || e == NotificationFeedbackReminder
+}
+
+// IsValid reports whether the event is a known notification event
+func (e NotificationEvent) IsValid() bool {
+	for _, event := range NotificationEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannelKind is how a channel delivers notifications
+type NotificationChannelKind string
+
+const (
+	// NotificationChannelEmail emails each recipient
+	NotificationChannelEmail NotificationChannelKind = "email"
+	// NotificationChannelSlack posts to a Slack incoming webhook
+	NotificationChannelSlack NotificationChannelKind = "slack"
+	// NotificationChannelTeams posts to a Microsoft Teams incoming webhook
+	NotificationChannelTeams NotificationChannelKind = "teams"
+	// NotificationChannelWebhook posts the notification as JSON to any HTTP endpoint
+	NotificationChannelWebhook NotificationChannelKind = "webhook"
+)
+
+// NotificationEventList is stored as JSONB
+type NotificationEventList []NotificationEvent
+
+// Scan implements sql.Scanner interface
+func (l *NotificationEventList) Scan(value interface{}) error {
+	if value == nil {
+		*l = NotificationEventList{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer interface
+func (l NotificationEventList) Value() (driver.Value, error) {
+	if l == nil {
+		return json.Marshal([]NotificationEvent{})
+	}
+	return json.Marshal(l)
+}
+
+// NotificationChannel routes an organisation's notifications to one destination.
+// Organisations without channels have every event emailed.
+type NotificationChannel struct {
+	ID             uint                    `gorm:"primarykey" json:"id"`
+	OrganisationID uint                    `gorm:"not null;index" json:"organisationId"`
+	Kind           NotificationChannelKind `gorm:"type:varchar(20);not null" json:"kind"`
+	Name           string                  `gorm:"type:varchar(255);not null" json:"name"`
+	// URL is the webhook address; email channels have none
+	URL string `gorm:"type:varchar(2048)" json:"url,omitempty"`
+	// Secret signs generic webhook requests and is never returned
+	Secret string `gorm:"type:varchar(255)" json:"-"`
+	// Events lists the events routed to the channel; empty routes every event, except that
+	// chat and webhook channels leave out personal events
+	Events    NotificationEventList `gorm:"type:jsonb" json:"events"`
+	CreatedAt time.Time             `json:"createdAt"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+}
+
+// Routes reports whether the event is delivered through the channel
+func (c *NotificationChannel) Routes(event NotificationEvent) bool {
+	if len(c.Events) == 0 {
+		return c.Kind == NotificationChannelEmail || !event.IsPersonal()
+	}
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateNotificationChannelInput struct {
+	Kind   NotificationChannelKind `json:"kind" binding:"required,oneof=email slack teams webhook"`
+	Name   string                  `json:"name" binding:"required,max=255"`
+	URL    string                  `json:"url" binding:"max=2048"`
+	Secret string                  `json:"secret" binding:"max=255"`
+	Events []NotificationEvent     `json:"events"`
+}
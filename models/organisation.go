@@ -1,16 +1,102 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// RematchPolicy controls when two colleagues who already had a cuppa may be matched again
+type RematchPolicy string
+
+const (
+	// RematchPolicyNever never pairs the same people twice
+	RematchPolicyNever RematchPolicy = "never"
+	// RematchPolicyAfterDays allows a repeat once RematchCooldownDays have passed
+	RematchPolicyAfterDays RematchPolicy = "after_days"
+	// RematchPolicyAfterEveryone allows a repeat only when neither person has anyone new left to meet
+	RematchPolicyAfterEveryone RematchPolicy = "after_everyone"
+)
+
+// ScoringWeights maps match scoring strategy names to their relative weight
+// Format: {"tag_overlap": 2, "random": 1}
+// Valid strategies: tag_overlap, tag_diversity, seniority_spread, past_rating, random
+type ScoringWeights map[string]float64
+
+// Scan implements sql.Scanner interface
+func (w *ScoringWeights) Scan(value interface{}) error {
+	if value == nil {
+		*w = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, w)
+}
+
+// Value implements driver.Valuer interface
+func (w ScoringWeights) Value() (driver.Value, error) {
+	if w == nil {
+		return nil, nil
+	}
+	return json.Marshal(w)
+}
+
 type Organisation struct {
-	ID         uint           `gorm:"primarykey" json:"id"`
-	Name       string         `gorm:"type:varchar(255);not null;uniqueIndex" json:"name" binding:"required"`
-	CompanyUrl string         `gorm:"type:varchar(500)" json:"companyUrl,omitempty"`
-	CreatedAt  time.Time      `json:"createdAt"`
-	UpdatedAt  time.Time      `json:"updatedAt"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID         uint   `gorm:"primarykey" json:"id"`
+	Name       string `gorm:"type:varchar(255);not null;uniqueIndex" json:"name" binding:"required"`
+	CompanyUrl string `gorm:"type:varchar(500)" json:"companyUrl,omitempty"`
+	// MatchGroupSize is the number of people per cuppa: 2 for pairs, 3-5 for group cuppas
+	MatchGroupSize      int           `gorm:"not null;default:2" json:"matchGroupSize"`
+	RematchPolicy       RematchPolicy `gorm:"type:varchar(20);not null;default:'never'" json:"rematchPolicy"`
+	RematchCooldownDays int           `gorm:"not null;default:180" json:"rematchCooldownDays"`
+	// Working hours (hour of day, end exclusive) and cuppa length used to schedule matches
+	WorkingHoursStart    int `gorm:"not null;default:9" json:"workingHoursStart"`
+	WorkingHoursEnd      int `gorm:"not null;default:17" json:"workingHoursEnd"`
+	CuppaDurationMinutes int `gorm:"not null;default:30" json:"cuppaDurationMinutes"`
+	// TimeZone is the IANA zone of the working hours and the default for members without one
+	TimeZone string `gorm:"type:varchar(64);not null;default:'UTC'" json:"timeZone"`
+	// ScoringWeights selects and weights match scoring strategies (tag overlap only when empty)
+	ScoringWeights ScoringWeights `gorm:"type:jsonb" json:"scoringWeights,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// GroupSize returns the configured cuppa size, falling back to pairs
+func (o *Organisation) GroupSize() int {
+	if o.MatchGroupSize < 2 {
+		return 2
+	}
+	return o.MatchGroupSize
+}
+
+// WorkingHours returns the start and end hour of the working day, falling back to 9-17
+func (o *Organisation) WorkingHours() (int, int) {
+	if o.WorkingHoursEnd <= o.WorkingHoursStart {
+		return 9, 17
+	}
+	return o.WorkingHoursStart, o.WorkingHoursEnd
+}
+
+// CuppaDuration returns how long a cuppa lasts, falling back to 30 minutes
+func (o *Organisation) CuppaDuration() time.Duration {
+	if o.CuppaDurationMinutes <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(o.CuppaDurationMinutes) * time.Minute
+}
+
+// Location returns the organisation's time zone, falling back to UTC when unset or unknown
+func (o *Organisation) Location() *time.Location {
+	if o.TimeZone != "" {
+		if loc, err := LoadLocation(o.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// OutboxStatus is where an outbox message is in its delivery
+type OutboxStatus string
+
+const (
+	// OutboxStatusPending messages are waiting for their next delivery attempt
+	OutboxStatusPending OutboxStatus = "pending"
+	// OutboxStatusSent messages were delivered
+	OutboxStatusSent OutboxStatus = "sent"
+	// OutboxStatusDead messages ran out of attempts, or can never be delivered, and wait
+	// for an admin to retry them
+	OutboxStatusDead OutboxStatus = "dead"
+)
+
+// IsValid reports whether the status is a known outbox status
+func (s OutboxStatus) IsValid() bool {
+	return s == OutboxStatusPending || s == OutboxStatusSent || s == OutboxStatusDead
+}
+
+// OutboxPayload is a JSON document stored as JSONB and returned as-is
+type OutboxPayload []byte
+
+// Scan implements sql.Scanner interface
+func (p *OutboxPayload) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = nil
+	case []byte:
+		*p = append(OutboxPayload(nil), v...)
+	case string:
+		*p = OutboxPayload(v)
+	default:
+		return errors.New("unsupported outbox payload type")
+	}
+	return nil
+}
+
+// Value implements driver.Valuer interface
+func (p OutboxPayload) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return "null", nil
+	}
+	return string(p), nil
+}
+
+// MarshalJSON embeds the payload instead of encoding it as base64
+func (p OutboxPayload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+// OutboxMessage is one notification waiting to be delivered, or delivered, through one
+// channel. Messages are written in the same transaction as the change they describe and
+// delivered by a background worker, so a failing provider delays them instead of losing them.
+type OutboxMessage struct {
+	ID             uint              `gorm:"primarykey" json:"id"`
+	OrganisationID uint              `gorm:"not null;index" json:"organisationId"`
+	Event          NotificationEvent `gorm:"type:varchar(50);not null" json:"event"`
+	// ChannelID is the organisation's channel; nil is the default email channel
+	ChannelID   *uint                   `json:"channelId"`
+	ChannelKind NotificationChannelKind `gorm:"type:varchar(20);not null" json:"channelKind"`
+	Recipient   string                  `gorm:"type:varchar(255)" json:"recipient"`
+	// Payload is the notification as JSON
+	Payload       OutboxPayload `gorm:"type:jsonb;not null" json:"payload"`
+	Status        OutboxStatus  `gorm:"type:varchar(20);not null;default:pending" json:"status"`
+	Attempts      int           `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time     `gorm:"not null" json:"nextAttemptAt"`
+	// LeasedUntil is when a worker's claim on the message runs out; nil while no worker is
+	// delivering it
+	LeasedUntil *time.Time `json:"leasedUntil"`
+	LastError   string     `gorm:"type:text" json:"lastError,omitempty"`
+	SentAt      *time.Time `json:"sentAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
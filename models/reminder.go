@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// ReminderKind names a reminder campaign
+type ReminderKind string
+
+const (
+	// ReminderKindAcceptance nudges participants who haven't answered a pending match
+	ReminderKindAcceptance ReminderKind = "acceptance"
+	// ReminderKindFeedback nudges participants who haven't rated their cuppa before the
+	// feedback window closes
+	ReminderKindFeedback ReminderKind = "feedback"
+)
+
+// Reminder policy defaults, used by organisations that have not set a policy
+const (
+	DefaultAcceptanceReminderHoursBefore = 24
+	DefaultFeedbackReminderHoursBefore   = 24
+)
+
+// ReminderPolicy is an organisation's reminder campaigns: when to nudge participants who
+// haven't accepted a match, and how long before the feedback window closes to ask for feedback
+type ReminderPolicy struct {
+	ID             uint `gorm:"primarykey" json:"id"`
+	OrganisationID uint `gorm:"not null;uniqueIndex" json:"organisationId"`
+	// Acceptance reminders go to participants who haven't accepted a match
+	// AcceptanceReminderHoursBefore the pending match expires
+	AcceptanceReminderEnabled     bool `gorm:"not null" json:"acceptanceReminderEnabled"`
+	AcceptanceReminderHoursBefore int  `gorm:"not null;default:24" json:"acceptanceReminderHoursBefore"`
+	// Feedback reminders go to participants who haven't given feedback
+	// FeedbackReminderHoursBefore the match's ExpiresAt
+	FeedbackReminderEnabled     bool      `gorm:"not null" json:"feedbackReminderEnabled"`
+	FeedbackReminderHoursBefore int       `gorm:"not null;default:24" json:"feedbackReminderHoursBefore"`
+	CreatedAt                   time.Time `json:"createdAt"`
+	UpdatedAt                   time.Time `json:"updatedAt"`
+}
+
+// DefaultReminderPolicy is the policy of an organisation that has not set one
+func DefaultReminderPolicy(organisationID uint) *ReminderPolicy {
+	return &ReminderPolicy{
+		OrganisationID:                organisationID,
+		AcceptanceReminderEnabled:     true,
+		AcceptanceReminderHoursBefore: DefaultAcceptanceReminderHoursBefore,
+		FeedbackReminderEnabled:       true,
+		FeedbackReminderHoursBefore:   DefaultFeedbackReminderHoursBefore,
+	}
+}
+
+// MatchReminder records a reminder sent to a participant, so each campaign reminds a
+// participant of a match at most once
+type MatchReminder struct {
+	ID      uint         `gorm:"primarykey" json:"id"`
+	MatchID uint         `gorm:"not null;uniqueIndex:idx_match_reminder" json:"matchId"`
+	UserID  uint         `gorm:"not null;uniqueIndex:idx_match_reminder" json:"userId"`
+	Kind    ReminderKind `gorm:"type:varchar(20);not null;uniqueIndex:idx_match_reminder" json:"kind"`
+	SentAt  time.Time    `gorm:"not null" json:"sentAt"`
+}
+
+type UpdateReminderPolicyInput struct {
+	AcceptanceReminderEnabled     bool `json:"acceptanceReminderEnabled"`
+	AcceptanceReminderHoursBefore int  `json:"acceptanceReminderHoursBefore" binding:"required,min=1"`
+	FeedbackReminderEnabled       bool `json:"feedbackReminderEnabled"`
+	FeedbackReminderHoursBefore   int  `json:"feedbackReminderHoursBefore" binding:"required,min=1,max=168"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+type RescheduleStatus string
+
+const (
+	// RescheduleStatusPending is waiting for another participant to pick a slot or counter-propose
+	RescheduleStatusPending RescheduleStatus = "pending"
+	// RescheduleStatusAccepted means a slot was picked and the match moved to it
+	RescheduleStatusAccepted RescheduleStatus = "accepted"
+	// RescheduleStatusCountered means another participant answered with slots of their own
+	RescheduleStatusCountered RescheduleStatus = "countered"
+)
+
+// MaxRescheduleSlots is the most alternative slots a single proposal may offer
+const MaxRescheduleSlots = 3
+
+// ProposedSlot is a concrete time window offered in a reschedule proposal
+type ProposedSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ProposedSlots is stored as JSONB
+type ProposedSlots []ProposedSlot
+
+// Scan implements sql.Scanner interface
+func (s *ProposedSlots) Scan(value interface{}) error {
+	if value == nil {
+		*s = ProposedSlots{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// Value implements driver.Valuer interface
+func (s ProposedSlots) Value() (driver.Value, error) {
+	if s == nil {
+		return json.Marshal([]ProposedSlot{})
+	}
+	return json.Marshal(s)
+}
+
+// RescheduleProposal offers alternative slots for an accepted match. A match keeps every
+// proposal made for it; at most one is pending at a time.
+type RescheduleProposal struct {
+	ID            uint             `gorm:"primarykey" json:"id"`
+	MatchID       uint             `gorm:"not null;index" json:"matchId"`
+	ProposedByID  uint             `gorm:"not null" json:"proposedById"`
+	ProposedBy    *User            `gorm:"foreignKey:ProposedByID" json:"proposedBy,omitempty"`
+	Slots         ProposedSlots    `gorm:"type:jsonb;not null" json:"slots"`
+	Status        RescheduleStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	AcceptedStart *time.Time       `json:"acceptedStart,omitempty"`
+	RespondedByID *uint            `json:"respondedById,omitempty"`
+	RespondedAt   *time.Time       `json:"respondedAt,omitempty"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	UpdatedAt     time.Time        `json:"updatedAt"`
+}
+
+type ProposeRescheduleInput struct {
+	// Slots are the start times on offer; each cuppa lasts the organisation's cuppa duration
+	Slots []time.Time `json:"slots" binding:"required,min=1,max=3"`
+}
+
+type AcceptRescheduleInput struct {
+	Start time.Time `json:"start" binding:"required"`
+}
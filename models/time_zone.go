@@ -0,0 +1,34 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTimeZone is used for organisations that have not chosen a time zone
+const DefaultTimeZone = "UTC"
+
+// locations caches loaded time zones by IANA name
+var locations sync.Map
+
+// LoadLocation loads an IANA time zone such as "Europe/London", caching the result
+func LoadLocation(name string) (*time.Location, error) {
+	if cached, ok := locations.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// IsValidTimeZone reports whether name is a known IANA time zone
+func IsValidTimeZone(name string) bool {
+	if name == "" {
+		return false
+	}
+	_, err := LoadLocation(name)
+	return err == nil
+}
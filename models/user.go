@@ -13,6 +13,21 @@ const (
 	AccountTypeAdmin AccountType = "Admin"
 )
 
+// MatchCadence is how often a user wants a new cuppa
+type MatchCadence string
+
+const (
+	MatchCadenceWeekly      MatchCadence = "weekly"
+	MatchCadenceFortnightly MatchCadence = "fortnightly"
+	MatchCadenceMonthly     MatchCadence = "monthly"
+)
+
+// Seniority levels range from MinSeniorityLevel (most junior) to MaxSeniorityLevel
+const (
+	MinSeniorityLevel = 1
+	MaxSeniorityLevel = 10
+)
+
 type User struct {
 	ID                   uint                     `gorm:"primarykey" json:"id"`
 	FirstName            string                   `gorm:"type:varchar(100)" json:"firstName,omitempty"`
@@ -24,13 +39,46 @@ type User struct {
 	Tags                 []Tag                    `gorm:"many2many:user_tags;" json:"tags,omitempty"`
 	IsConfirmed          bool                     `gorm:"default:false" json:"isConfirmed"`
 	AverageRating        *float64                 `gorm:"type:decimal(3,2)" json:"averageRating,omitempty"`
+	SeniorityLevel       *int                     `gorm:"type:smallint" json:"seniorityLevel,omitempty"`
+	MatchCadence         MatchCadence             `gorm:"type:varchar(20);not null;default:'weekly'" json:"matchCadence"`
+	PausedUntil          *time.Time               `json:"pausedUntil,omitempty"`
+	TimeZone             string                   `gorm:"type:varchar(64)" json:"timeZone,omitempty"`
+	Office               string                   `gorm:"type:varchar(100)" json:"office,omitempty"`
 	AvailabilityConfig   *UserAvailabilityConfig  `gorm:"foreignKey:UserID" json:"availabilityConfig,omitempty"`
 	RefreshToken         *string                  `gorm:"type:text" json:"-"`
+	CalendarFeedToken    *string                  `gorm:"type:varchar(64);uniqueIndex" json:"-"`
 	CreatedAt            time.Time                `json:"createdAt"`
 	UpdatedAt            time.Time                `json:"updatedAt"`
 	DeletedAt            gorm.DeletedAt           `gorm:"index" json:"-"`
 }
 
+// NextMatchAfter returns when a user whose last cuppa was at lastMatchedAt is due a new one
+func (u *User) NextMatchAfter(lastMatchedAt time.Time) time.Time {
+	switch u.MatchCadence {
+	case MatchCadenceFortnightly:
+		return lastMatchedAt.AddDate(0, 0, 14)
+	case MatchCadenceMonthly:
+		return lastMatchedAt.AddDate(0, 1, 0)
+	default:
+		return lastMatchedAt.AddDate(0, 0, 7)
+	}
+}
+
+// IsPaused reports whether the user has paused matching at the given time
+func (u *User) IsPaused(now time.Time) bool {
+	return u.PausedUntil != nil && now.Before(*u.PausedUntil)
+}
+
+// Location returns the user's time zone, falling back to the organisation's when unset
+func (u *User) Location(org *Organisation) *time.Location {
+	if u.TimeZone != "" {
+		if loc, err := LoadLocation(u.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return org.Location()
+}
+
 type RegisterInput struct {
 	FirstName      string      `json:"firstName,omitempty"`
 	LastName       string      `json:"lastName,omitempty"`
@@ -68,10 +116,21 @@ type ConfirmUserInput struct {
 	UserID uint `json:"userId" binding:"required"`
 }
 
+
+// UpsertOrganisationInput creates an organisation or updates an existing one; omitted fields
+// keep their current value. Working hours are pointers so that a start of 0 (midnight) can be set.
 type UpsertOrganisationInput struct {
-	ID         uint   `json:"id"`
-	Name       string `json:"name" binding:"required"`
-	CompanyUrl string `json:"companyUrl,omitempty"`
+	ID                   uint           `json:"id"`
+	Name                 string         `json:"name" binding:"required"`
+	CompanyUrl           string         `json:"companyUrl,omitempty"`
+	MatchGroupSize       int            `json:"matchGroupSize,omitempty" binding:"omitempty,min=2,max=5"`
+	RematchPolicy        RematchPolicy  `json:"rematchPolicy,omitempty" binding:"omitempty,oneof=never after_days after_everyone"`
+	RematchCooldownDays  int            `json:"rematchCooldownDays,omitempty" binding:"omitempty,min=1"`
+	WorkingHoursStart    *int           `json:"workingHoursStart,omitempty" binding:"omitempty,min=0,max=23"`
+	WorkingHoursEnd      *int           `json:"workingHoursEnd,omitempty" binding:"omitempty,min=1,max=24"`
+	CuppaDurationMinutes int            `json:"cuppaDurationMinutes,omitempty" binding:"omitempty,min=10,max=240"`
+	TimeZone             string         `json:"timeZone,omitempty"`
+	ScoringWeights       ScoringWeights `json:"scoringWeights,omitempty" binding:"omitempty,dive,keys,oneof=tag_overlap tag_diversity seniority_spread past_rating random,endkeys,min=0"`
 }
 
 type CreateUserInput struct {
@@ -83,3 +142,38 @@ type CreateUserInput struct {
 type UpdateTagsInput struct {
 	Tags []string `json:"tags" binding:"required"`
 }
+
+type UpdateSeniorityInput struct {
+	SeniorityLevel *int `json:"seniorityLevel" binding:"required,min=1,max=10"`
+}
+
+type UpdateMatchPreferencesInput struct {
+	MatchCadence MatchCadence `json:"matchCadence" binding:"required,oneof=weekly fortnightly monthly"`
+	// PausedUntil pauses matching until the given time; omit or send null to resume
+	PausedUntil *time.Time `json:"pausedUntil"`
+}
+
+type UpdateTimeZoneInput struct {
+	// TimeZone is an IANA name such as "Europe/London"; empty uses the organisation's time zone
+	TimeZone string `json:"timeZone"`
+}
+
+type UpdateOfficeInput struct {
+	// Office selects the user's regional holiday calendars; empty clears it
+	Office string `json:"office" binding:"max=100"`
+}
+
+// CalendarFeed is the secret address of a user's subscribable cuppa calendar
+type CalendarFeed struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// MatchingStatus describes when a user will next be considered for a match
+type MatchingStatus struct {
+	MatchCadence   MatchCadence `json:"matchCadence"`
+	Paused         bool         `json:"paused"`
+	PausedUntil    *time.Time   `json:"pausedUntil,omitempty"`
+	LastMatchedAt  *time.Time   `json:"lastMatchedAt,omitempty"`
+	NextEligibleAt *time.Time   `json:"nextEligibleAt,omitempty"`
+}
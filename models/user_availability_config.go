@@ -1,13 +1,18 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 // UserAvailabilityConfig represents user's system-wide availability configuration
-// This configuration is used for matching users with compatible schedules
+// This configuration is used for matching users with compatible schedules.
+// TimeRanges hold the weekly availability; the half-day flags mirror them for older clients.
 type UserAvailabilityConfig struct {
 	ID                 uint           `gorm:"primarykey" json:"id"`
 	UserID             uint           `gorm:"uniqueIndex;not null" json:"userId"`
@@ -26,207 +31,457 @@ type UserAvailabilityConfig struct {
 	SaturdayAfternoon  bool           `gorm:"default:false" json:"saturdayAfternoon"`
 	SundayMorning      bool           `gorm:"default:false" json:"sundayMorning"`
 	SundayAfternoon    bool           `gorm:"default:false" json:"sundayAfternoon"`
+	TimeRanges         TimeRanges     `gorm:"type:jsonb" json:"timeRanges"`
 	CreatedAt          time.Time      `json:"createdAt"`
 	UpdatedAt          time.Time      `json:"updatedAt"`
 	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
-// GetAvailableSlots returns a list of available time slots in a readable format
+// GetAvailableSlots returns the weekly availability in a readable format, e.g. "Tuesday 12:00-13:30"
 func (uac *UserAvailabilityConfig) GetAvailableSlots() []string {
 	slots := []string{}
-	
-	if uac.MondayMorning {
-		slots = append(slots, "Monday morning")
+	for _, w := range uac.Windows() {
+		slots = append(slots, w.String())
 	}
-	if uac.MondayAfternoon {
-		slots = append(slots, "Monday afternoon")
-	}
-	if uac.TuesdayMorning {
-		slots = append(slots, "Tuesday morning")
-	}
-	if uac.TuesdayAfternoon {
-		slots = append(slots, "Tuesday afternoon")
-	}
-	if uac.WednesdayMorning {
-		slots = append(slots, "Wednesday morning")
+	return slots
+}
+
+// Half-day periods of the legacy availability grid. Mornings end and afternoons start at noon.
+const (
+	PeriodMorning   = "morning"
+	PeriodAfternoon = "afternoon"
+)
+
+const (
+	minutesPerDay = 24 * 60
+	noonMinute    = 12 * 60
+)
+
+var weekdaysByName = map[string]time.Weekday{
+	"Monday":    time.Monday,
+	"Tuesday":   time.Tuesday,
+	"Wednesday": time.Wednesday,
+	"Thursday":  time.Thursday,
+	"Friday":    time.Friday,
+	"Saturday":  time.Saturday,
+	"Sunday":    time.Sunday,
+}
+
+// TimeRange is a weekly availability range as sent and stored, e.g.
+// {"day": "Tuesday", "start": "12:00", "end": "13:30"}. End may be "24:00".
+type TimeRange struct {
+	Day   string `json:"day" binding:"required"`
+	Start string `json:"start" binding:"required"`
+	End   string `json:"end" binding:"required"`
+}
+
+// TimeRanges is stored as JSONB
+type TimeRanges []TimeRange
+
+// Scan implements sql.Scanner interface
+func (tr *TimeRanges) Scan(value interface{}) error {
+	if value == nil {
+		*tr = TimeRanges{}
+		return nil
 	}
-	if uac.WednesdayAfternoon {
-		slots = append(slots, "Wednesday afternoon")
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
 	}
-	if uac.ThursdayMorning {
-		slots = append(slots, "Thursday morning")
+	return json.Unmarshal(bytes, tr)
+}
+
+// Value implements driver.Valuer interface
+func (tr TimeRanges) Value() (driver.Value, error) {
+	if tr == nil {
+		return json.Marshal([]TimeRange{})
 	}
-	if uac.ThursdayAfternoon {
-		slots = append(slots, "Thursday afternoon")
+	return json.Marshal(tr)
+}
+
+// WeeklyWindow is a window on a weekday, in minutes since midnight
+type WeeklyWindow struct {
+	Day   time.Weekday
+	Start int
+	End   int
+}
+
+// Duration returns the length of the window, or 0 if it is empty
+func (w WeeklyWindow) Duration() time.Duration {
+	if w.End <= w.Start {
+		return 0
 	}
-	if uac.FridayMorning {
-		slots = append(slots, "Friday morning")
+	return time.Duration(w.End-w.Start) * time.Minute
+}
+
+// Clip returns the part of the window between the given minutes of the day
+func (w WeeklyWindow) Clip(start, end int) WeeklyWindow {
+	if w.Start < start {
+		w.Start = start
 	}
-	if uac.FridayAfternoon {
-		slots = append(slots, "Friday afternoon")
+	if w.End > end {
+		w.End = end
 	}
-	if uac.SaturdayMorning {
-		slots = append(slots, "Saturday morning")
+	return w
+}
+
+// String formats the window, e.g. "Tuesday 12:00-13:30"
+func (w WeeklyWindow) String() string {
+	return fmt.Sprintf("%s %s", w.Day, w.Hours())
+}
+
+// Hours formats the times of the window, e.g. "12:00-13:30"
+func (w WeeklyWindow) Hours() string {
+	return fmt.Sprintf("%s-%s", formatClock(w.Start), formatClock(w.End))
+}
+
+// NewTimeRanges converts weekly windows to time ranges, merging them first
+func NewTimeRanges(windows []WeeklyWindow) TimeRanges {
+	windows = normalizeWindows(windows)
+	ranges := make(TimeRanges, len(windows))
+	for i, w := range windows {
+		ranges[i] = TimeRange{Day: w.Day.String(), Start: formatClock(w.Start), End: formatClock(w.End)}
+	}
+	return ranges
+}
+
+// ParseTimeRanges validates time ranges and converts them to merged weekly windows
+func ParseTimeRanges(ranges []TimeRange) ([]WeeklyWindow, error) {
+	windows := make([]WeeklyWindow, 0, len(ranges))
+	for _, r := range ranges {
+		day, ok := weekdaysByName[r.Day]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", r.Day)
+		}
+		start, ok := parseClock(r.Start)
+		if !ok {
+			return nil, fmt.Errorf("invalid start time %q", r.Start)
+		}
+		end, ok := parseClock(r.End)
+		if !ok {
+			return nil, fmt.Errorf("invalid end time %q", r.End)
+		}
+		if end <= start {
+			return nil, fmt.Errorf("time range %s %s-%s must end after it starts", r.Day, r.Start, r.End)
+		}
+		windows = append(windows, WeeklyWindow{Day: day, Start: start, End: end})
 	}
-	if uac.SaturdayAfternoon {
-		slots = append(slots, "Saturday afternoon")
+	return normalizeWindows(windows), nil
+}
+
+// Windows returns the config's availability as merged weekly windows ordered Monday to Sunday.
+// Configs saved before time ranges existed are read from their half-day flags.
+func (uac *UserAvailabilityConfig) Windows() []WeeklyWindow {
+	if len(uac.TimeRanges) == 0 {
+		var windows []WeeklyWindow
+		for _, day := range weekOrder {
+			for _, period := range []string{PeriodMorning, PeriodAfternoon} {
+				if uac.IsAvailable(day, period) {
+					start, end := halfDay(period)
+					windows = append(windows, WeeklyWindow{Day: day, Start: start, End: end})
+				}
+			}
+		}
+		return normalizeWindows(windows)
 	}
-	if uac.SundayMorning {
-		slots = append(slots, "Sunday morning")
+
+	windows, err := ParseTimeRanges(uac.TimeRanges)
+	if err != nil {
+		return nil
 	}
-	if uac.SundayAfternoon {
-		slots = append(slots, "Sunday afternoon")
+	return windows
+}
+
+// SetWindows replaces the availability and keeps the half-day flags in sync: a half-day is
+// marked available when any window overlaps it
+func (uac *UserAvailabilityConfig) SetWindows(windows []WeeklyWindow) {
+	windows = normalizeWindows(windows)
+	uac.TimeRanges = NewTimeRanges(windows)
+
+	for _, day := range weekOrder {
+		for _, period := range []string{PeriodMorning, PeriodAfternoon} {
+			start, end := halfDay(period)
+			overlaps := false
+			for _, w := range windows {
+				if w.Day == day && w.Start < end && w.End > start {
+					overlaps = true
+					break
+				}
+			}
+			uac.setHalfDayFlag(day, period, overlaps)
+		}
 	}
-	
-	return slots
 }
 
-// ToAvailability converts UserAvailabilityConfig to Availability map format
-func (uac *UserAvailabilityConfig) ToAvailability() Availability {
-	availability := make(Availability)
-	
-	if uac.MondayMorning || uac.MondayAfternoon {
-		availability["Monday"] = []string{}
-		if uac.MondayMorning {
-			availability["Monday"] = append(availability["Monday"], "morning")
+// SetHalfDay marks a legacy half-day as available or unavailable, leaving the rest of the
+// week's time ranges untouched
+func (uac *UserAvailabilityConfig) SetHalfDay(day time.Weekday, period string, available bool) {
+	start, end := halfDay(period)
+	windows := uac.Windows()
+	if available {
+		windows = append(windows, WeeklyWindow{Day: day, Start: start, End: end})
+	} else {
+		windows = SubtractWindows(windows, []WeeklyWindow{{Day: day, Start: start, End: end}})
+	}
+	uac.SetWindows(windows)
+}
+
+// IsAvailable reports whether the given weekday and legacy half-day period are marked as available
+func (uac *UserAvailabilityConfig) IsAvailable(day time.Weekday, period string) bool {
+	morning := period == PeriodMorning
+	switch day {
+	case time.Monday:
+		return (morning && uac.MondayMorning) || (!morning && uac.MondayAfternoon)
+	case time.Tuesday:
+		return (morning && uac.TuesdayMorning) || (!morning && uac.TuesdayAfternoon)
+	case time.Wednesday:
+		return (morning && uac.WednesdayMorning) || (!morning && uac.WednesdayAfternoon)
+	case time.Thursday:
+		return (morning && uac.ThursdayMorning) || (!morning && uac.ThursdayAfternoon)
+	case time.Friday:
+		return (morning && uac.FridayMorning) || (!morning && uac.FridayAfternoon)
+	case time.Saturday:
+		return (morning && uac.SaturdayMorning) || (!morning && uac.SaturdayAfternoon)
+	case time.Sunday:
+		return (morning && uac.SundayMorning) || (!morning && uac.SundayAfternoon)
+	}
+	return false
+}
+
+func (uac *UserAvailabilityConfig) setHalfDayFlag(day time.Weekday, period string, available bool) {
+	morning := period == PeriodMorning
+	switch day {
+	case time.Monday:
+		if morning {
+			uac.MondayMorning = available
+		} else {
+			uac.MondayAfternoon = available
 		}
-		if uac.MondayAfternoon {
-			availability["Monday"] = append(availability["Monday"], "afternoon")
+	case time.Tuesday:
+		if morning {
+			uac.TuesdayMorning = available
+		} else {
+			uac.TuesdayAfternoon = available
 		}
-	}
-	
-	if uac.TuesdayMorning || uac.TuesdayAfternoon {
-		availability["Tuesday"] = []string{}
-		if uac.TuesdayMorning {
-			availability["Tuesday"] = append(availability["Tuesday"], "morning")
+	case time.Wednesday:
+		if morning {
+			uac.WednesdayMorning = available
+		} else {
+			uac.WednesdayAfternoon = available
 		}
-		if uac.TuesdayAfternoon {
-			availability["Tuesday"] = append(availability["Tuesday"], "afternoon")
+	case time.Thursday:
+		if morning {
+			uac.ThursdayMorning = available
+		} else {
+			uac.ThursdayAfternoon = available
 		}
-	}
-	
-	if uac.WednesdayMorning || uac.WednesdayAfternoon {
-		availability["Wednesday"] = []string{}
-		if uac.WednesdayMorning {
-			availability["Wednesday"] = append(availability["Wednesday"], "morning")
+	case time.Friday:
+		if morning {
+			uac.FridayMorning = available
+		} else {
+			uac.FridayAfternoon = available
+		}
+	case time.Saturday:
+		if morning {
+			uac.SaturdayMorning = available
+		} else {
+			uac.SaturdayAfternoon = available
 		}
-		if uac.WednesdayAfternoon {
-			availability["Wednesday"] = append(availability["Wednesday"], "afternoon")
+	case time.Sunday:
+		if morning {
+			uac.SundayMorning = available
+		} else {
+			uac.SundayAfternoon = available
 		}
 	}
-	
-	if uac.ThursdayMorning || uac.ThursdayAfternoon {
-		availability["Thursday"] = []string{}
-		if uac.ThursdayMorning {
-			availability["Thursday"] = append(availability["Thursday"], "morning")
+}
+
+// SubtractWindows returns the parts of the windows in a that no window in b covers
+func SubtractWindows(a, b []WeeklyWindow) []WeeklyWindow {
+	remaining := normalizeWindows(a)
+	for _, cut := range normalizeWindows(b) {
+		var next []WeeklyWindow
+		for _, w := range remaining {
+			if w.Day != cut.Day || w.End <= cut.Start || w.Start >= cut.End {
+				next = append(next, w)
+				continue
+			}
+			if w.Start < cut.Start {
+				next = append(next, WeeklyWindow{Day: w.Day, Start: w.Start, End: cut.Start})
+			}
+			if w.End > cut.End {
+				next = append(next, WeeklyWindow{Day: w.Day, Start: cut.End, End: w.End})
+			}
 		}
-		if uac.ThursdayAfternoon {
-			availability["Thursday"] = append(availability["Thursday"], "afternoon")
+		remaining = next
+	}
+	return remaining
+}
+
+// Interval is a span of absolute time
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the interval, or 0 if it is empty
+func (i Interval) Duration() time.Duration {
+	if !i.End.After(i.Start) {
+		return 0
+	}
+	return i.End.Sub(i.Start)
+}
+
+// WindowIntervals places weekly windows, read as wall-clock times in loc, on the calendar and
+// returns the parts that fall within [from, to) in chronological order
+func WindowIntervals(windows []WeeklyWindow, loc *time.Location, from, to time.Time) []Interval {
+	var intervals []Interval
+	local := from.In(loc)
+	for day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, w := range windows {
+			if w.Day != day.Weekday() {
+				continue
+			}
+			interval := Interval{Start: atMinute(day, w.Start), End: atMinute(day, w.End)}
+			if interval.Start.Before(from) {
+				interval.Start = from
+			}
+			if interval.End.After(to) {
+				interval.End = to
+			}
+			if interval.Duration() == 0 {
+				continue
+			}
+			// Join windows that run on past midnight into the next day
+			if last := len(intervals) - 1; last >= 0 && !interval.Start.After(intervals[last].End) {
+				if interval.End.After(intervals[last].End) {
+					intervals[last].End = interval.End
+				}
+				continue
+			}
+			intervals = append(intervals, interval)
 		}
 	}
-	
-	if uac.FridayMorning || uac.FridayAfternoon {
-		availability["Friday"] = []string{}
-		if uac.FridayMorning {
-			availability["Friday"] = append(availability["Friday"], "morning")
+	return intervals
+}
+
+// IntersectIntervals returns the times covered by both chronologically ordered lists
+func IntersectIntervals(a, b []Interval) []Interval {
+	var common []Interval
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
 		}
-		if uac.FridayAfternoon {
-			availability["Friday"] = append(availability["Friday"], "afternoon")
+		if b[j].End.Before(end) {
+			end = b[j].End
 		}
-	}
-	
-	if uac.SaturdayMorning || uac.SaturdayAfternoon {
-		availability["Saturday"] = []string{}
-		if uac.SaturdayMorning {
-			availability["Saturday"] = append(availability["Saturday"], "morning")
+		if end.After(start) {
+			common = append(common, Interval{Start: start, End: end})
 		}
-		if uac.SaturdayAfternoon {
-			availability["Saturday"] = append(availability["Saturday"], "afternoon")
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
 		}
 	}
-	
-	if uac.SundayMorning || uac.SundayAfternoon {
-		availability["Sunday"] = []string{}
-		if uac.SundayMorning {
-			availability["Sunday"] = append(availability["Sunday"], "morning")
+	return common
+}
+
+// SubtractIntervals returns the parts of a not covered by b; both lists must be in
+// chronological order and b must not overlap itself
+func SubtractIntervals(a, b []Interval) []Interval {
+	var remaining []Interval
+	j := 0
+	for _, interval := range a {
+		start := interval.Start
+		for j < len(b) && !b[j].End.After(start) {
+			j++
 		}
-		if uac.SundayAfternoon {
-			availability["Sunday"] = append(availability["Sunday"], "afternoon")
+		for k := j; k < len(b) && b[k].Start.Before(interval.End); k++ {
+			if b[k].Start.After(start) {
+				remaining = append(remaining, Interval{Start: start, End: b[k].Start})
+			}
+			if b[k].End.After(start) {
+				start = b[k].End
+			}
+		}
+		if interval.End.After(start) {
+			remaining = append(remaining, Interval{Start: start, End: interval.End})
 		}
 	}
-	
-	return availability
-}
-
-// HasCommonAvailability checks if two users have at least one common available time slot
-func HasCommonAvailability(config1, config2 *UserAvailabilityConfig) bool {
-	return (config1.MondayMorning && config2.MondayMorning) ||
-		(config1.MondayAfternoon && config2.MondayAfternoon) ||
-		(config1.TuesdayMorning && config2.TuesdayMorning) ||
-		(config1.TuesdayAfternoon && config2.TuesdayAfternoon) ||
-		(config1.WednesdayMorning && config2.WednesdayMorning) ||
-		(config1.WednesdayAfternoon && config2.WednesdayAfternoon) ||
-		(config1.ThursdayMorning && config2.ThursdayMorning) ||
-		(config1.ThursdayAfternoon && config2.ThursdayAfternoon) ||
-		(config1.FridayMorning && config2.FridayMorning) ||
-		(config1.FridayAfternoon && config2.FridayAfternoon) ||
-		(config1.SaturdayMorning && config2.SaturdayMorning) ||
-		(config1.SaturdayAfternoon && config2.SaturdayAfternoon) ||
-		(config1.SundayMorning && config2.SundayMorning) ||
-		(config1.SundayAfternoon && config2.SundayAfternoon)
-}
-
-// GetCommonSlots returns a list of common available time slots between two users
-func GetCommonSlots(config1, config2 *UserAvailabilityConfig) []string {
-	slots := []string{}
-	
-	if config1.MondayMorning && config2.MondayMorning {
-		slots = append(slots, "Monday morning")
-	}
-	if config1.MondayAfternoon && config2.MondayAfternoon {
-		slots = append(slots, "Monday afternoon")
-	}
-	if config1.TuesdayMorning && config2.TuesdayMorning {
-		slots = append(slots, "Tuesday morning")
-	}
-	if config1.TuesdayAfternoon && config2.TuesdayAfternoon {
-		slots = append(slots, "Tuesday afternoon")
-	}
-	if config1.WednesdayMorning && config2.WednesdayMorning {
-		slots = append(slots, "Wednesday morning")
-	}
-	if config1.WednesdayAfternoon && config2.WednesdayAfternoon {
-		slots = append(slots, "Wednesday afternoon")
-	}
-	if config1.ThursdayMorning && config2.ThursdayMorning {
-		slots = append(slots, "Thursday morning")
-	}
-	if config1.ThursdayAfternoon && config2.ThursdayAfternoon {
-		slots = append(slots, "Thursday afternoon")
-	}
-	if config1.FridayMorning && config2.FridayMorning {
-		slots = append(slots, "Friday morning")
-	}
-	if config1.FridayAfternoon && config2.FridayAfternoon {
-		slots = append(slots, "Friday afternoon")
+	return remaining
+}
+
+// weekOrder lists weekdays Monday first, the order availability is shown in
+var weekOrder = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// halfDay returns the minutes covered by a legacy half-day period
+func halfDay(period string) (int, int) {
+	if period == PeriodMorning {
+		return 0, noonMinute
 	}
-	if config1.SaturdayMorning && config2.SaturdayMorning {
-		slots = append(slots, "Saturday morning")
+	return noonMinute, minutesPerDay
+}
+
+// normalizeWindows sorts windows Monday to Sunday and merges overlapping or touching windows
+func normalizeWindows(windows []WeeklyWindow) []WeeklyWindow {
+	sorted := make([]WeeklyWindow, 0, len(windows))
+	for _, w := range windows {
+		if w.Duration() > 0 {
+			sorted = append(sorted, w)
+		}
 	}
-	if config1.SaturdayAfternoon && config2.SaturdayAfternoon {
-		slots = append(slots, "Saturday afternoon")
+	sort.Slice(sorted, func(i, j int) bool {
+		di, dj := weekIndex(sorted[i].Day), weekIndex(sorted[j].Day)
+		if di != dj {
+			return di < dj
+		}
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	var merged []WeeklyWindow
+	for _, w := range sorted {
+		last := len(merged) - 1
+		if last >= 0 && merged[last].Day == w.Day && w.Start <= merged[last].End {
+			if w.End > merged[last].End {
+				merged[last].End = w.End
+			}
+			continue
+		}
+		merged = append(merged, w)
 	}
-	if config1.SundayMorning && config2.SundayMorning {
-		slots = append(slots, "Sunday morning")
+	return merged
+}
+
+// weekIndex is the position of the weekday in a Monday-first week
+func weekIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// parseClock parses "15:04" (or "24:00") into minutes since midnight
+func parseClock(value string) (int, bool) {
+	if value == "24:00" {
+		return minutesPerDay, true
 	}
-	if config1.SundayAfternoon && config2.SundayAfternoon {
-		slots = append(slots, "Sunday afternoon")
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
 	}
-	
-	return slots
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// atMinute returns the time the given number of minutes after midnight on day's wall clock
+func atMinute(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minutes, 0, 0, day.Location())
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
 }
 
-// CreateAvailabilityConfigInput represents input for creating availability configuration
+// CreateAvailabilityConfigInput represents input for creating availability configuration.
+// TimeRanges take precedence; the half-day flags are accepted from older clients.
 type CreateAvailabilityConfigInput struct {
 	MondayMorning      bool `json:"mondayMorning"`
 	MondayAfternoon    bool `json:"mondayAfternoon"`
@@ -242,9 +497,12 @@ type CreateAvailabilityConfigInput struct {
 	SaturdayAfternoon  bool `json:"saturdayAfternoon"`
 	SundayMorning      bool `json:"sundayMorning"`
 	SundayAfternoon    bool `json:"sundayAfternoon"`
+
+	TimeRanges []TimeRange `json:"timeRanges,omitempty" binding:"omitempty,dive"`
 }
 
-// UpdateAvailabilityConfigInput represents input for updating availability configuration
+// UpdateAvailabilityConfigInput represents input for updating availability configuration.
+// TimeRanges, when given, replace the whole week; each half-day flag changes only its half-day.
 type UpdateAvailabilityConfigInput struct {
 	MondayMorning      *bool `json:"mondayMorning,omitempty"`
 	MondayAfternoon    *bool `json:"mondayAfternoon,omitempty"`
@@ -260,4 +518,27 @@ type UpdateAvailabilityConfigInput struct {
 	SaturdayAfternoon  *bool `json:"saturdayAfternoon,omitempty"`
 	SundayMorning      *bool `json:"sundayMorning,omitempty"`
 	SundayAfternoon    *bool `json:"sundayAfternoon,omitempty"`
+
+	TimeRanges []TimeRange `json:"timeRanges,omitempty" binding:"omitempty,dive"`
+}
+
+// ImportAvailabilityInput represents the form fields sent with an uploaded calendar file
+type ImportAvailabilityInput struct {
+	// Apply saves the proposed availability instead of only previewing it
+	Apply bool `form:"apply"`
+}
+
+// AvailabilityImport is the availability proposed from an uploaded calendar: the weekly
+// ranges found free in most sampled weeks, and how they differ from the saved availability
+type AvailabilityImport struct {
+	TimeRanges TimeRanges `json:"timeRanges"`
+	Added      TimeRanges `json:"added"`
+	Removed    TimeRanges `json:"removed"`
+	// SampledFrom and SampledTo bound the weeks the calendar was read over
+	SampledFrom time.Time `json:"sampledFrom"`
+	SampledTo   time.Time `json:"sampledTo"`
+	BusyEvents  int       `json:"busyEvents"`
+	// Applied is set when the proposal was saved; Config is then the saved configuration
+	Applied bool                    `json:"applied"`
+	Config  *UserAvailabilityConfig `json:"config,omitempty"`
 }
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"virtual-cuppa-be/models"
+
+	"gorm.io/gorm"
+)
+
+type AvailabilityOverrideRepository interface {
+	Create(override *models.AvailabilityOverride) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.AvailabilityOverride, error)
+	FindByUserID(userID uint, from time.Time) ([]*models.AvailabilityOverride, error)
+	FindByUserIDsBetween(userIDs []uint, from, to time.Time) ([]*models.AvailabilityOverride, error)
+	DeleteEndedBefore(userID uint, date time.Time) error
+}
+
+type availabilityOverrideRepository struct {
+	db *gorm.DB
+}
+
+func NewAvailabilityOverrideRepository(db *gorm.DB) AvailabilityOverrideRepository {
+	return &availabilityOverrideRepository{db: db}
+}
+
+func (r *availabilityOverrideRepository) Create(override *models.AvailabilityOverride) error {
+	return r.db.Create(override).Error
+}
+
+func (r *availabilityOverrideRepository) Delete(id uint) error {
+	return r.db.Delete(&models.AvailabilityOverride{}, id).Error
+}
+
+func (r *availabilityOverrideRepository) FindByID(id uint) (*models.AvailabilityOverride, error) {
+	var override models.AvailabilityOverride
+	err := r.db.First(&override, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &override, nil
+}
+
+// FindByUserID returns the user's overrides that have not ended before from, soonest first
+func (r *availabilityOverrideRepository) FindByUserID(userID uint, from time.Time) ([]*models.AvailabilityOverride, error) {
+	var overrides []*models.AvailabilityOverride
+	err := r.db.Where("user_id = ? AND end_date >= ?", userID, from.Format("2006-01-02")).
+		Order("start_date ASC, id ASC").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+// FindByUserIDsBetween returns the given users' overrides touching any day in [from, to]
+func (r *availabilityOverrideRepository) FindByUserIDsBetween(userIDs []uint, from, to time.Time) ([]*models.AvailabilityOverride, error) {
+	var overrides []*models.AvailabilityOverride
+	err := r.db.Where("user_id IN ? AND start_date <= ? AND end_date >= ?", userIDs, to.Format("2006-01-02"), from.Format("2006-01-02")).
+		Find(&overrides).Error
+	return overrides, err
+}
+
+// DeleteEndedBefore removes the user's overrides whose last day is before date
+func (r *availabilityOverrideRepository) DeleteEndedBefore(userID uint, date time.Time) error {
+	return r.db.Where("user_id = ? AND end_date < ?", userID, date.Format("2006-01-02")).
+		Delete(&models.AvailabilityOverride{}).Error
+}
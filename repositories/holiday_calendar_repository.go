@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+	"virtual-cuppa-be/models"
+
+	"gorm.io/gorm"
+)
+
+type HolidayCalendarRepository interface {
+	Create(calendar *models.HolidayCalendar) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.HolidayCalendar, error)
+	FindByOrganisation(organisationID uint) ([]*models.HolidayCalendar, error)
+	FindByOrganisationBetween(organisationID uint, from, to time.Time) ([]*models.HolidayCalendar, error)
+}
+
+type holidayCalendarRepository struct {
+	db *gorm.DB
+}
+
+func NewHolidayCalendarRepository(db *gorm.DB) HolidayCalendarRepository {
+	return &holidayCalendarRepository{db: db}
+}
+
+// Create saves the calendar together with its dates
+func (r *holidayCalendarRepository) Create(calendar *models.HolidayCalendar) error {
+	return r.db.Create(calendar).Error
+}
+
+// Delete removes the calendar; its dates are removed by the foreign key cascade
+func (r *holidayCalendarRepository) Delete(id uint) error {
+	return r.db.Delete(&models.HolidayCalendar{}, id).Error
+}
+
+func (r *holidayCalendarRepository) FindByID(id uint) (*models.HolidayCalendar, error) {
+	var calendar models.HolidayCalendar
+	err := r.db.Preload("Dates", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date ASC")
+	}).First(&calendar, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+func (r *holidayCalendarRepository) FindByOrganisation(organisationID uint) ([]*models.HolidayCalendar, error) {
+	var calendars []*models.HolidayCalendar
+	err := r.db.Preload("Dates", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date ASC")
+	}).Where("organisation_id = ?", organisationID).
+		Order("name ASC").
+		Find(&calendars).Error
+	return calendars, err
+}
+
+// FindByOrganisationBetween returns the organisation's calendars with only the dates in [from, to]
+func (r *holidayCalendarRepository) FindByOrganisationBetween(organisationID uint, from, to time.Time) ([]*models.HolidayCalendar, error) {
+	var calendars []*models.HolidayCalendar
+	err := r.db.Preload("Dates", func(db *gorm.DB) *gorm.DB {
+		return db.Where("date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).Order("date ASC")
+	}).Where("organisation_id = ?", organisationID).
+		Find(&calendars).Error
+	return calendars, err
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+	"virtual-cuppa-be/models"
+
+	"gorm.io/gorm"
+)
+
+type MatchBlockRepository interface {
+	Create(block *models.MatchBlock) error
+	Update(block *models.MatchBlock) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.MatchBlock, error)
+	FindOne(userID, blockedUserID uint, source models.MatchBlockSource) (*models.MatchBlock, error)
+	FindByUser(userID uint, source models.MatchBlockSource) ([]*models.MatchBlock, error)
+	FindByOrganisation(organisationID uint, source models.MatchBlockSource) ([]*models.MatchBlock, error)
+	FindAllByOrganisation(organisationID uint) ([]*models.MatchBlock, error)
+}
+
+type matchBlockRepository struct {
+	db *gorm.DB
+}
+
+func NewMatchBlockRepository(db *gorm.DB) MatchBlockRepository {
+	return &matchBlockRepository{db: db}
+}
+
+func (r *matchBlockRepository) Create(block *models.MatchBlock) error {
+	return r.db.Create(block).Error
+}
+
+func (r *matchBlockRepository) Update(block *models.MatchBlock) error {
+	return r.db.Save(block).Error
+}
+
+func (r *matchBlockRepository) Delete(id uint) error {
+	return r.db.Delete(&models.MatchBlock{}, id).Error
+}
+
+func (r *matchBlockRepository) FindByID(id uint) (*models.MatchBlock, error) {
+	var block models.MatchBlock
+	err := r.db.First(&block, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *matchBlockRepository) FindOne(userID, blockedUserID uint, source models.MatchBlockSource) (*models.MatchBlock, error) {
+	var block models.MatchBlock
+	err := r.db.Where("user_id = ? AND blocked_user_id = ? AND source = ?", userID, blockedUserID, source).
+		First(&block).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *matchBlockRepository) FindByUser(userID uint, source models.MatchBlockSource) ([]*models.MatchBlock, error) {
+	var blocks []*models.MatchBlock
+	err := r.db.Preload("BlockedUser").
+		Where("user_id = ? AND source = ?", userID, source).
+		Order("created_at DESC").
+		Find(&blocks).Error
+	return blocks, err
+}
+
+func (r *matchBlockRepository) FindByOrganisation(organisationID uint, source models.MatchBlockSource) ([]*models.MatchBlock, error) {
+	var blocks []*models.MatchBlock
+	err := r.db.Preload("User").Preload("BlockedUser").
+		Where("organisation_id = ? AND source = ?", organisationID, source).
+		Order("created_at DESC").
+		Find(&blocks).Error
+	return blocks, err
+}
+
+// FindAllByOrganisation returns unexpired blocks of every source, for use by match generation only
+func (r *matchBlockRepository) FindAllByOrganisation(organisationID uint) ([]*models.MatchBlock, error) {
+	var blocks []*models.MatchBlock
+	err := r.db.Where("organisation_id = ? AND (expires_at IS NULL OR expires_at > ?)", organisationID, time.Now()).
+		Find(&blocks).Error
+	return blocks, err
+}
@@ -11,6 +11,7 @@ type MatchHistoryRepository interface {
 	Create(history *models.MatchHistory) error
 	WasRecentlyMatched(user1ID, user2ID uint, days int) (bool, error)
 	WasEverMatched(user1ID, user2ID uint) (bool, error)
+	FindLatestAmongUsers(userIDs []uint) ([]*models.MatchHistory, error)
 }
 
 type matchHistoryRepository struct {
@@ -47,3 +48,20 @@ func (r *matchHistoryRepository) WasEverMatched(user1ID, user2ID uint) (bool, er
 	
 	return count > 0, err
 }
+
+// FindLatestAmongUsers returns one row per pair of the given users who have met,
+// with MatchedAt set to the most recent meeting
+func (r *matchHistoryRepository) FindLatestAmongUsers(userIDs []uint) ([]*models.MatchHistory, error) {
+	var histories []*models.MatchHistory
+	if len(userIDs) == 0 {
+		return histories, nil
+	}
+
+	err := r.db.Model(&models.MatchHistory{}).
+		Select("user1_id, user2_id, MAX(matched_at) AS matched_at").
+		Where("user1_id IN ? AND user2_id IN ?", userIDs, userIDs).
+		Group("user1_id, user2_id").
+		Find(&histories).Error
+
+	return histories, err
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"virtual-cuppa-be/models"
+
+	"gorm.io/gorm"
+)
+
+type MatchReminderRepository interface {
+	Create(reminder *models.MatchReminder) error
+	FindByMatchIDs(matchIDs []uint, kind models.ReminderKind) ([]*models.MatchReminder, error)
+}
+
+type matchReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewMatchReminderRepository(db *gorm.DB) MatchReminderRepository {
+	return &matchReminderRepository{db: db}
+}
+
+func (r *matchReminderRepository) Create(reminder *models.MatchReminder) error {
+	return r.db.Create(reminder).Error
+}
+
+func (r *matchReminderRepository) FindByMatchIDs(matchIDs []uint, kind models.ReminderKind) ([]*models.MatchReminder, error) {
+	var reminders []*models.MatchReminder
+	if len(matchIDs) == 0 {
+		return reminders, nil
+	}
+	err := r.db.Where("match_id IN ? AND kind = ?", matchIDs, kind).Find(&reminders).Error
+	return reminders, err
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+)
+
+// TestReminderCampaignQueries checks the matches each reminder campaign reads: pending
+// matches created before the cutoff, and matches waiting for feedback whose window closes in
+// [from, to), with the participants and feedback the campaigns filter on
+func TestReminderCampaignQueries(t *testing.T) {
+	tx := openTestDB(t)
+	org, users := createTestOrganisation(t, tx, "UTC")
+	other, otherUsers := createTestOrganisation(t, tx, "UTC")
+
+	repo := NewMatchRepository(tx)
+	now := time.Now().UTC().Truncate(time.Second)
+	create := func(org *models.Organisation, users []*models.User, status models.MatchStatus, createdAt time.Time, expiresAt *time.Time) *models.Match {
+		t.Helper()
+		match := &models.Match{
+			OrganisationID: org.ID,
+			User1ID:        users[0].ID,
+			User2ID:        users[1].ID,
+			Status:         status,
+			ScheduledDate:  now,
+			ExpiresAt:      expiresAt,
+			CreatedAt:      createdAt,
+			Participants: []*models.MatchParticipant{
+				{UserID: users[0].ID, Status: models.ParticipantStatusAccepted},
+				{UserID: users[1].ID, Status: models.ParticipantStatusPending},
+			},
+		}
+		if err := repo.Create(match); err != nil {
+			t.Fatalf("create match: %v", err)
+		}
+		return match
+	}
+	at := func(offset time.Duration) *time.Time {
+		value := now.Add(offset)
+		return &value
+	}
+
+	cutoff := now.Add(-48 * time.Hour)
+	due := create(org, users, models.MatchStatusPending, cutoff.Add(-time.Minute), nil)
+	create(org, users, models.MatchStatusPending, cutoff, nil)
+	create(org, users, models.MatchStatusAccepted, cutoff.Add(-time.Minute), nil)
+	create(other, otherUsers, models.MatchStatusPending, cutoff.Add(-time.Minute), nil)
+
+	pending, err := repo.FindPendingByOrganisationCreatedBefore(org.ID, cutoff)
+	if err != nil {
+		t.Fatalf("find pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != due.ID {
+		t.Fatalf("pending matches = %v, want match %d", pending, due.ID)
+	}
+	if len(pending[0].Participants) != 2 || pending[0].Participants[0].User == nil {
+		t.Errorf("participants = %v, want both with their users", pending[0].Participants)
+	}
+
+	closing := create(org, users, models.MatchStatusWaitingForFeedback, now, at(time.Hour))
+	create(org, users, models.MatchStatusWaitingForFeedback, now, at(24*time.Hour))
+	create(org, users, models.MatchStatusWaitingForFeedback, now, at(-time.Minute))
+	create(org, users, models.MatchStatusExpired, now, at(time.Hour))
+	create(other, otherUsers, models.MatchStatusWaitingForFeedback, now, at(time.Hour))
+	if err := tx.Create(&models.MatchFeedback{MatchID: closing.ID, UserID: users[0].ID, Rating: 4}).Error; err != nil {
+		t.Fatalf("create feedback: %v", err)
+	}
+
+	waiting, err := repo.FindWaitingForFeedbackByOrganisationExpiringBetween(org.ID, now, now.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("find waiting for feedback: %v", err)
+	}
+	if len(waiting) != 1 || waiting[0].ID != closing.ID {
+		t.Fatalf("matches waiting for feedback = %v, want match %d", waiting, closing.ID)
+	}
+	if len(waiting[0].Feedbacks) != 1 || waiting[0].Feedbacks[0].UserID != users[0].ID {
+		t.Errorf("feedbacks = %v, want the one given", waiting[0].Feedbacks)
+	}
+}
+
+// TestReminderRecordedOncePerCampaign checks a participant can be reminded of a match once by
+// each campaign, so concurrent scheduler runs can't both send a reminder
+func TestReminderRecordedOncePerCampaign(t *testing.T) {
+	tx := openTestDB(t)
+	org, users := createTestOrganisation(t, tx, "UTC")
+
+	match := &models.Match{OrganisationID: org.ID, User1ID: users[0].ID, User2ID: users[1].ID, Status: models.MatchStatusPending, ScheduledDate: time.Now()}
+	if err := NewMatchRepository(tx).Create(match); err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+
+	repo := NewMatchReminderRepository(tx)
+	remind := func(kind models.ReminderKind) error {
+		return repo.Create(&models.MatchReminder{MatchID: match.ID, UserID: users[1].ID, Kind: kind, SentAt: time.Now()})
+	}
+	if err := remind(models.ReminderKindAcceptance); err != nil {
+		t.Fatalf("first acceptance reminder: %v", err)
+	}
+	if err := remind(models.ReminderKindFeedback); err != nil {
+		t.Fatalf("feedback reminder: %v", err)
+	}
+
+	// The failed insert aborts the transaction, so it runs in a savepoint
+	tx.SavePoint("duplicate")
+	if err := remind(models.ReminderKindAcceptance); err == nil {
+		t.Error("second acceptance reminder: got no error")
+	}
+	tx.RollbackTo("duplicate")
+
+	reminders, err := repo.FindByMatchIDs([]uint{match.ID}, models.ReminderKindAcceptance)
+	if err != nil {
+		t.Fatalf("find reminders: %v", err)
+	}
+	if len(reminders) != 1 {
+		t.Errorf("acceptance reminders = %d, want 1", len(reminders))
+	}
+}
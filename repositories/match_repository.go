@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"time"
 	"virtual-cuppa-be/models"
 
 	"gorm.io/gorm"
@@ -15,12 +16,37 @@ type MatchRepository interface {
 	Update(match *models.Match) error
 	Delete(id uint) error
 	HasPendingMatch(userID uint) (bool, error)
+	FindUserIDsWithPendingMatch(organisationID uint) ([]uint, error)
+	UpdateParticipant(participant *models.MatchParticipant) error
+	FindPendingCreatedBefore(cutoff time.Time) ([]*models.Match, error)
+	FindWaitingForFeedbackExpiredBefore(cutoff time.Time) ([]*models.Match, error)
+	Expire(id uint, status models.MatchStatus, expiredAt time.Time, reason string) (bool, error)
+	FindPendingByOrganisationCreatedBefore(organisationID uint, cutoff time.Time) ([]*models.Match, error)
+	FindWaitingForFeedbackByOrganisationExpiringBetween(organisationID uint, from, to time.Time) ([]*models.Match, error)
+	FindScheduledStartsBetween(organisationID uint, from, to time.Time) ([]time.Time, error)
+	FindLastMatchedAt(userIDs []uint) ([]*UserLastMatch, error)
 	
 	// Availability methods
 	CreateAvailability(availability *models.MatchAvailability) error
 	UpdateAvailability(availability *models.MatchAvailability) error
 	FindAvailabilityByMatchAndUser(matchID, userID uint) (*models.MatchAvailability, error)
 	FindAvailabilitiesByMatch(matchID uint) ([]*models.MatchAvailability, error)
+
+	// Run methods
+	CreateRun(run *models.MatchRun) error
+	UpdateRun(run *models.MatchRun) error
+}
+
+// participantMatchIDs selects matches the user takes part in (and has not rejected)
+const participantMatchIDs = "id IN (SELECT match_id FROM match_participants WHERE user_id = ? AND status <> 'rejected')"
+
+// anyParticipantMatchIDs selects every match the user was ever a participant of
+const anyParticipantMatchIDs = "id IN (SELECT match_id FROM match_participants WHERE user_id = ?)"
+
+// UserLastMatch is when a user was last given a cuppa that went ahead
+type UserLastMatch struct {
+	UserID    uint
+	MatchedAt time.Time
 }
 
 type matchRepository struct {
@@ -37,7 +63,8 @@ func (r *matchRepository) Create(match *models.Match) error {
 
 func (r *matchRepository) FindByID(id uint) (*models.Match, error) {
 	var match models.Match
-	err := r.db.Preload("User1").Preload("User2").Preload("Availabilities").Preload("Feedbacks.User").First(&match, id).Error
+	err := r.db.Preload("User1").Preload("User2").Preload("Participants.User").
+		Preload("Availabilities").Preload("Feedbacks.User").First(&match, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -48,11 +75,11 @@ func (r *matchRepository) FindCurrentByUserID(userID uint) (*models.Match, error
 	var match models.Match
 	err := r.db.Preload("User1.Tags").Preload("User2.Tags").
 		Preload("User1.AvailabilityConfig").Preload("User2.AvailabilityConfig").
+		Preload("Participants.User.Tags").Preload("Participants.User.AvailabilityConfig").
 		Preload("Availabilities").
 		Preload("Feedbacks.User").
-		Where(`(user1_id = ? OR user2_id = ?) AND 
-		       (status = ? OR status = ?)`, 
-			userID, userID, models.MatchStatusPending, models.MatchStatusWaitingForFeedback).
+		Where(participantMatchIDs+" AND (status = ? OR status = ?)",
+			userID, models.MatchStatusPending, models.MatchStatusWaitingForFeedback).
 		Order("created_at DESC").
 		First(&match).Error
 	if err != nil {
@@ -65,7 +92,9 @@ func (r *matchRepository) FindByOrganisation(organisationID uint) ([]*models.Mat
 	var matches []*models.Match
 	err := r.db.Preload("User1.Tags").Preload("User2.Tags").
 		Preload("User1.AvailabilityConfig").Preload("User2.AvailabilityConfig").
+		Preload("Participants.User.Tags").
 		Preload("Availabilities.User").Preload("Feedbacks.User").
+		Preload("Run").
 		Where("organisation_id = ?", organisationID).
 		Order("created_at DESC").
 		Find(&matches).Error
@@ -76,8 +105,9 @@ func (r *matchRepository) FindByUserID(userID uint) ([]*models.Match, error) {
 	var matches []*models.Match
 	err := r.db.Preload("User1.Tags").Preload("User2.Tags").
 		Preload("User1.AvailabilityConfig").Preload("User2.AvailabilityConfig").
+		Preload("Participants.User.Tags").
 		Preload("Availabilities.User").Preload("Feedbacks.User").
-		Where("user1_id = ? OR user2_id = ?", userID, userID).
+		Where(anyParticipantMatchIDs, userID).
 		Order("created_at DESC").
 		Find(&matches).Error
 	return matches, err
@@ -95,16 +125,116 @@ func (r *matchRepository) HasPendingMatch(userID uint) (bool, error) {
 	var count int64
 	// User has pending match if:
 	// 1. Match status is pending OR waiting_for_feedback
-	// 2. User hasn't completed their part yet (either not accepted, or accepted but not given feedback)
+	// 2. User is still an active participant (a group member who rejected has left the match)
 	err := r.db.Model(&models.Match{}).
-		Where(`((user1_id = ? AND (status = ? OR status = ?)) OR 
-		       (user2_id = ? AND (status = ? OR status = ?)))`, 
-			userID, models.MatchStatusPending, models.MatchStatusWaitingForFeedback,
+		Where(participantMatchIDs+" AND (status = ? OR status = ?)",
 			userID, models.MatchStatusPending, models.MatchStatusWaitingForFeedback).
 		Count(&count).Error
 	return count > 0, err
 }
 
+// FindUserIDsWithPendingMatch is the bulk form of HasPendingMatch for a whole organisation
+func (r *matchRepository) FindUserIDsWithPendingMatch(organisationID uint) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Model(&models.MatchParticipant{}).
+		Joins("JOIN matches ON matches.id = match_participants.match_id").
+		Where("matches.organisation_id = ? AND matches.deleted_at IS NULL AND (matches.status = ? OR matches.status = ?) AND match_participants.status <> ?",
+			organisationID, models.MatchStatusPending, models.MatchStatusWaitingForFeedback, models.ParticipantStatusRejected).
+		Distinct().
+		Pluck("match_participants.user_id", &userIDs).Error
+	return userIDs, err
+}
+
+func (r *matchRepository) UpdateParticipant(participant *models.MatchParticipant) error {
+	return r.db.Save(participant).Error
+}
+
+func (r *matchRepository) FindPendingCreatedBefore(cutoff time.Time) ([]*models.Match, error) {
+	var matches []*models.Match
+	err := r.db.Preload("Participants").
+		Where("status = ? AND created_at < ?", models.MatchStatusPending, cutoff).
+		Find(&matches).Error
+	return matches, err
+}
+
+// FindPendingByOrganisationCreatedBefore returns the organisation's pending matches created
+// before cutoff, with their participants' users
+func (r *matchRepository) FindPendingByOrganisationCreatedBefore(organisationID uint, cutoff time.Time) ([]*models.Match, error) {
+	var matches []*models.Match
+	err := r.db.Preload("Participants.User").
+		Where("organisation_id = ? AND status = ? AND created_at < ?", organisationID, models.MatchStatusPending, cutoff).
+		Find(&matches).Error
+	return matches, err
+}
+
+// FindWaitingForFeedbackByOrganisationExpiringBetween returns the organisation's matches
+// waiting for feedback whose feedback window closes in [from, to), with their participants'
+// users and the feedback given so far
+func (r *matchRepository) FindWaitingForFeedbackByOrganisationExpiringBetween(organisationID uint, from, to time.Time) ([]*models.Match, error) {
+	var matches []*models.Match
+	err := r.db.Preload("Participants.User").Preload("Feedbacks").
+		Where("organisation_id = ? AND status = ? AND expires_at >= ? AND expires_at < ?",
+			organisationID, models.MatchStatusWaitingForFeedback, from, to).
+		Find(&matches).Error
+	return matches, err
+}
+
+// FindScheduledStartsBetween returns the start times of the organisation's open matches
+// scheduled in [from, to), used to spread new cuppas across the scheduling window
+func (r *matchRepository) FindScheduledStartsBetween(organisationID uint, from, to time.Time) ([]time.Time, error) {
+	var starts []time.Time
+	err := r.db.Model(&models.Match{}).
+		Where("organisation_id = ? AND scheduled_start >= ? AND scheduled_start < ? AND status IN ?",
+			organisationID, from, to,
+			[]models.MatchStatus{models.MatchStatusPending, models.MatchStatusAccepted, models.MatchStatusWaitingForFeedback}).
+		Pluck("scheduled_start", &starts).Error
+	return starts, err
+}
+
+// FindLastMatchedAt returns, for each of the given users who has had one, the creation time
+// of their latest cuppa that went ahead. Matches the user rejected or that expired before
+// everyone accepted are not counted.
+func (r *matchRepository) FindLastMatchedAt(userIDs []uint) ([]*UserLastMatch, error) {
+	var lastMatches []*UserLastMatch
+	if len(userIDs) == 0 {
+		return lastMatches, nil
+	}
+
+	err := r.db.Model(&models.MatchParticipant{}).
+		Select("match_participants.user_id, MAX(matches.created_at) AS matched_at").
+		Joins("JOIN matches ON matches.id = match_participants.match_id").
+		Where("match_participants.user_id IN ? AND match_participants.status <> ? AND matches.deleted_at IS NULL",
+			userIDs, models.ParticipantStatusRejected).
+		Where("matches.status IN ? OR (matches.status = ? AND matches.expiry_reason = ?)",
+			[]models.MatchStatus{models.MatchStatusAccepted, models.MatchStatusWaitingForFeedback, models.MatchStatusCompleted},
+			models.MatchStatusExpired, models.ExpiryReasonFeedbackWindowElapsed).
+		Group("match_participants.user_id").
+		Scan(&lastMatches).Error
+	return lastMatches, err
+}
+
+func (r *matchRepository) FindWaitingForFeedbackExpiredBefore(cutoff time.Time) ([]*models.Match, error) {
+	var matches []*models.Match
+	err := r.db.Preload("Participants").
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at < ?", models.MatchStatusWaitingForFeedback, cutoff).
+		Find(&matches).Error
+	return matches, err
+}
+
+// Expire moves the match to expired only while it still has the given status, so a change
+// made since it was read (such as the last participant accepting) wins. It reports whether
+// the match was expired.
+func (r *matchRepository) Expire(id uint, status models.MatchStatus, expiredAt time.Time, reason string) (bool, error) {
+	result := r.db.Model(&models.Match{}).
+		Where("id = ? AND status = ?", id, status).
+		Updates(map[string]interface{}{
+			"status":        models.MatchStatusExpired,
+			"expired_at":    expiredAt,
+			"expiry_reason": reason,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
 func (r *matchRepository) CreateAvailability(availability *models.MatchAvailability) error {
 	return r.db.Create(availability).Error
 }
@@ -127,3 +257,11 @@ func (r *matchRepository) FindAvailabilitiesByMatch(matchID uint) ([]*models.Mat
 	err := r.db.Preload("User").Where("match_id = ?", matchID).Find(&availabilities).Error
 	return availabilities, err
 }
+
+func (r *matchRepository) CreateRun(run *models.MatchRun) error {
+	return r.db.Create(run).Error
+}
+
+func (r *matchRepository) UpdateRun(run *models.MatchRun) error {
+	return r.db.Save(run).Error
+}
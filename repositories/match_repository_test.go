@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+	"virtual-cuppa-be/config"
+	"virtual-cuppa-be/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the Postgres database in TEST_DATABASE_URL, migrates it and returns
+// a transaction that is rolled back when the test ends. Tests are skipped without a database.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := config.RunMigrations(sqlDB, "../migrations"); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	tx := db.Begin()
+	t.Cleanup(func() {
+		tx.Rollback()
+		sqlDB.Close()
+	})
+	return tx
+}
+
+// createTestOrganisation creates an organisation in the time zone with two confirmed users
+func createTestOrganisation(t *testing.T, tx *gorm.DB, timeZone string) (*models.Organisation, []*models.User) {
+	t.Helper()
+	suffix := time.Now().UnixNano()
+	org := &models.Organisation{Name: fmt.Sprintf("Test organisation %d", suffix), TimeZone: timeZone}
+	if err := tx.Create(org).Error; err != nil {
+		t.Fatalf("create organisation: %v", err)
+	}
+	users := make([]*models.User, 2)
+	for i := range users {
+		users[i] = &models.User{Email: fmt.Sprintf("test-%d-%d@example.com", suffix, i), OrganisationID: &org.ID, IsConfirmed: true}
+		if err := tx.Create(users[i]).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	return org, users
+}
+
+// TestScheduledSlotRoundTripsInOrganisationTimeZone saves a match scheduled on the clock of a
+// non-UTC organisation and checks the slot reads back as the same instant
+func TestScheduledSlotRoundTripsInOrganisationTimeZone(t *testing.T) {
+	tx := openTestDB(t)
+
+	org, users := createTestOrganisation(t, tx, "Asia/Kolkata")
+
+	loc := org.Location()
+	day := time.Now().In(loc).AddDate(0, 0, 3)
+	start := time.Date(day.Year(), day.Month(), day.Day(), 14, 30, 0, 0, loc)
+	end := start.Add(30 * time.Minute)
+	expiresAt := end.AddDate(0, 0, 5)
+
+	repo := NewMatchRepository(tx)
+	match := &models.Match{
+		OrganisationID: org.ID,
+		User1ID:        users[0].ID,
+		User2ID:        users[1].ID,
+		Status:         models.MatchStatusPending,
+		ScheduledDate:  time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc),
+		ScheduledTime:  start.Format("15:04"),
+		ScheduledStart: &start,
+		ScheduledEnd:   &end,
+		ExpiresAt:      &expiresAt,
+	}
+	if err := repo.Create(match); err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+
+	saved, err := repo.FindByID(match.ID)
+	if err != nil {
+		t.Fatalf("find match: %v", err)
+	}
+	if saved.ScheduledStart == nil || !saved.ScheduledStart.Equal(start) {
+		t.Errorf("scheduled start = %v, want %v", saved.ScheduledStart, start)
+	}
+	if saved.ScheduledEnd == nil || !saved.ScheduledEnd.Equal(end) {
+		t.Errorf("scheduled end = %v, want %v", saved.ScheduledEnd, end)
+	}
+	if saved.ExpiresAt == nil || !saved.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("expires at = %v, want %v", saved.ExpiresAt, expiresAt)
+	}
+
+	// The organisation's local day must find the slot; the UTC day before it must not
+	dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	starts, err := repo.FindScheduledStartsBetween(org.ID, dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("find scheduled starts: %v", err)
+	}
+	if len(starts) != 1 || !starts[0].Equal(start) {
+		t.Errorf("scheduled starts on %s = %v, want [%v]", dayStart.Format("2006-01-02"), starts, start)
+	}
+	starts, err = repo.FindScheduledStartsBetween(org.ID, start.Add(-24*time.Hour), start.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("find scheduled starts: %v", err)
+	}
+	if len(starts) != 0 {
+		t.Errorf("scheduled starts before the slot = %v, want none", starts)
+	}
+}
+
+// TestExpireLeavesMatchesThatMovedOn checks expiry doesn't overwrite a match whose status
+// changed after the sweeper read it
+func TestExpireLeavesMatchesThatMovedOn(t *testing.T) {
+	tx := openTestDB(t)
+	org, users := createTestOrganisation(t, tx, "UTC")
+
+	repo := NewMatchRepository(tx)
+	match := &models.Match{
+		OrganisationID: org.ID,
+		User1ID:        users[0].ID,
+		User2ID:        users[1].ID,
+		Status:         models.MatchStatusPending,
+		ScheduledDate:  time.Now(),
+	}
+	if err := repo.Create(match); err != nil {
+		t.Fatalf("create match: %v", err)
+	}
+
+	// Everyone accepted after the sweeper read the match as pending
+	match.Status = models.MatchStatusWaitingForFeedback
+	if err := repo.Update(match); err != nil {
+		t.Fatalf("update match: %v", err)
+	}
+	now := time.Now()
+	expired, err := repo.Expire(match.ID, models.MatchStatusPending, now, models.ExpiryReasonAcceptanceWindowElapsed)
+	if err != nil || expired {
+		t.Fatalf("expire as pending = %v, %v; want not expired", expired, err)
+	}
+	saved, err := repo.FindByID(match.ID)
+	if err != nil {
+		t.Fatalf("find match: %v", err)
+	}
+	if saved.Status != models.MatchStatusWaitingForFeedback || saved.ExpiredAt != nil {
+		t.Errorf("match is %s (expired at %v), want still waiting for feedback", saved.Status, saved.ExpiredAt)
+	}
+
+	expired, err = repo.Expire(match.ID, models.MatchStatusWaitingForFeedback, now, models.ExpiryReasonFeedbackWindowElapsed)
+	if err != nil || !expired {
+		t.Fatalf("expire as waiting for feedback = %v, %v; want expired", expired, err)
+	}
+	saved, err = repo.FindByID(match.ID)
+	if err != nil {
+		t.Fatalf("find match: %v", err)
+	}
+	if saved.Status != models.MatchStatusExpired || saved.ExpiryReason != models.ExpiryReasonFeedbackWindowElapsed {
+		t.Errorf("match is %s (%q), want expired after the feedback window", saved.Status, saved.ExpiryReason)
+	}
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"errors"
+
+	"virtual-cuppa-be/models"
+
+	"gorm.io/gorm"
+)
+
+type NotificationChannelRepository interface {
+	Create(channel *models.NotificationChannel) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.NotificationChannel, error)
+	FindByOrganisation(organisationID uint) ([]*models.NotificationChannel, error)
+}
+
+type notificationChannelRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationChannelRepository(db *gorm.DB) NotificationChannelRepository {
+	return &notificationChannelRepository{db: db}
+}
+
+func (r *notificationChannelRepository) Create(channel *models.NotificationChannel) error {
+	return r.db.Create(channel).Error
+}
+
+func (r *notificationChannelRepository) Delete(id uint) error {
+	return r.db.Delete(&models.NotificationChannel{}, id).Error
+}
+
+func (r *notificationChannelRepository) FindByID(id uint) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	err := r.db.First(&channel, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (r *notificationChannelRepository) FindByOrganisation(organisationID uint) ([]*models.NotificationChannel, error) {
+	var channels []*models.NotificationChannel
+	err := r.db.Where("organisation_id = ?", organisationID).Order("id ASC").Find(&channels).Error
+	return channels, err
+}
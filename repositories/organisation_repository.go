@@ -57,17 +57,29 @@ func (r *organisationRepository) Update(organisation *models.Organisation) error
 	return r.db.Save(organisation).Error
 }
 
+// Upsert creates the organisation, or overwrites every field of the existing one with the same
+// ID, zero values included
 func (r *organisationRepository) Upsert(organisation *models.Organisation) error {
 	var existing models.Organisation
 	err := r.db.Where("id = ?", organisation.ID).First(&existing).Error
 	
 	if err == gorm.ErrRecordNotFound {
-		return r.db.Create(organisation).Error
+		// Create writes a column's default in place of a zero value, so a working day starting
+		// at midnight is written afterwards
+		workingHoursStart := organisation.WorkingHoursStart
+		if err := r.db.Create(organisation).Error; err != nil {
+			return err
+		}
+		if workingHoursStart == organisation.WorkingHoursStart {
+			return nil
+		}
+		organisation.WorkingHoursStart = workingHoursStart
+		return r.db.Model(organisation).Update("working_hours_start", workingHoursStart).Error
 	}
 	
 	if err != nil {
 		return err
 	}
 	
-	return r.db.Model(&existing).Updates(organisation).Error
+	return r.db.Model(&existing).Select("*").Omit("CreatedAt").Updates(organisation).Error
 }
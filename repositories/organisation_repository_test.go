@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+)
+
+// TestUpsertSavesZeroValues checks a working day starting at midnight is kept on create and
+// on update
+func TestUpsertSavesZeroValues(t *testing.T) {
+	tx := openTestDB(t)
+	repo := NewOrganisationRepository(tx)
+
+	org := &models.Organisation{Name: fmt.Sprintf("Upsert test %d", time.Now().UnixNano()), WorkingHoursStart: 0, WorkingHoursEnd: 8}
+	if err := repo.Upsert(org); err != nil {
+		t.Fatalf("create organisation: %v", err)
+	}
+	saved, err := repo.FindByID(org.ID)
+	if err != nil {
+		t.Fatalf("find organisation: %v", err)
+	}
+	if saved.WorkingHoursStart != 0 || saved.WorkingHoursEnd != 8 {
+		t.Errorf("created hours = %d-%d, want 0-8", saved.WorkingHoursStart, saved.WorkingHoursEnd)
+	}
+
+	saved.WorkingHoursStart, saved.WorkingHoursEnd = 9, 17
+	if err := repo.Upsert(saved); err != nil {
+		t.Fatalf("update organisation: %v", err)
+	}
+	saved.WorkingHoursStart = 0
+	if err := repo.Upsert(saved); err != nil {
+		t.Fatalf("update organisation: %v", err)
+	}
+	saved, err = repo.FindByID(org.ID)
+	if err != nil {
+		t.Fatalf("find organisation: %v", err)
+	}
+	if saved.WorkingHoursStart != 0 || saved.WorkingHoursEnd != 17 {
+		t.Errorf("updated hours = %d-%d, want 0-17", saved.WorkingHoursStart, saved.WorkingHoursEnd)
+	}
+}
+
+// TestUpsertClearsScoringWeights checks weights set once can be cleared again
+func TestUpsertClearsScoringWeights(t *testing.T) {
+	tx := openTestDB(t)
+	repo := NewOrganisationRepository(tx)
+
+	org := &models.Organisation{Name: fmt.Sprintf("Weights test %d", time.Now().UnixNano()), WorkingHoursStart: 9, WorkingHoursEnd: 17, ScoringWeights: models.ScoringWeights{"random": 1}}
+	if err := repo.Upsert(org); err != nil {
+		t.Fatalf("create organisation: %v", err)
+	}
+	org.ScoringWeights = nil
+	if err := repo.Upsert(org); err != nil {
+		t.Fatalf("update organisation: %v", err)
+	}
+	saved, err := repo.FindByID(org.ID)
+	if err != nil {
+		t.Fatalf("find organisation: %v", err)
+	}
+	if len(saved.ScoringWeights) != 0 {
+		t.Errorf("weights = %v, want none", saved.ScoringWeights)
+	}
+}
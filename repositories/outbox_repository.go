@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"virtual-cuppa-be/models"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository interface {
+	Create(message *models.OutboxMessage) error
+	Update(message *models.OutboxMessage) error
+	FindByID(id uint) (*models.OutboxMessage, error)
+	FindByOrganisation(organisationID uint, status models.OutboxStatus, limit int) ([]*models.OutboxMessage, error)
+	FindDue(now time.Time, limit int) ([]*models.OutboxMessage, error)
+	Claim(id uint, now time.Time, leaseUntil time.Time) (bool, error)
+	Requeue(id uint, now time.Time) (bool, error)
+	DeleteSentBefore(cutoff time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(message *models.OutboxMessage) error {
+	return r.db.Create(message).Error
+}
+
+func (r *outboxRepository) Update(message *models.OutboxMessage) error {
+	return r.db.Save(message).Error
+}
+
+func (r *outboxRepository) FindByID(id uint) (*models.OutboxMessage, error) {
+	var message models.OutboxMessage
+	err := r.db.First(&message, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+// FindByOrganisation returns the newest messages of the organisation, optionally of one status
+func (r *outboxRepository) FindByOrganisation(organisationID uint, status models.OutboxStatus, limit int) ([]*models.OutboxMessage, error) {
+	var messages []*models.OutboxMessage
+	query := r.db.Where("organisation_id = ?", organisationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// FindDue returns pending messages whose next attempt is due, oldest first
+func (r *outboxRepository) FindDue(now time.Time, limit int) ([]*models.OutboxMessage, error) {
+	var messages []*models.OutboxMessage
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, now).
+		Order("next_attempt_at ASC").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// Claim leases a due message to the caller by moving its next attempt to leaseUntil. It
+// reports false when another worker claimed the message first.
+func (r *outboxRepository) Claim(id uint, now time.Time, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&models.OutboxMessage{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, models.OutboxStatusPending, now).
+		Updates(map[string]interface{}{"next_attempt_at": leaseUntil, "leased_until": leaseUntil})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Requeue makes a dead message, or a pending one no worker holds a lease on, due now with
+// a fresh set of attempts. It reports false when the message was sent or is being delivered.
+func (r *outboxRepository) Requeue(id uint, now time.Time) (bool, error) {
+	result := r.db.Model(&models.OutboxMessage{}).
+		Where("id = ? AND (status = ? OR (status = ? AND (leased_until IS NULL OR leased_until <= ?)))",
+			id, models.OutboxStatusDead, models.OutboxStatusPending, now).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"leased_until":    nil,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *outboxRepository) DeleteSentBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("status = ? AND sent_at < ?", models.OutboxStatusSent, cutoff).Delete(&models.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
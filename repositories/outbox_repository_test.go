@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+)
+
+// TestRequeueLeavesLeasedMessagesAlone checks a retry can't reset a message a worker is
+// delivering, but can once the worker's lease has run out
+func TestRequeueLeavesLeasedMessagesAlone(t *testing.T) {
+	tx := openTestDB(t)
+	repo := NewOutboxRepository(tx)
+
+	now := time.Now()
+	newMessage := func(status models.OutboxStatus) *models.OutboxMessage {
+		message := &models.OutboxMessage{
+			Event:         models.NotificationMatchCreated,
+			ChannelKind:   models.NotificationChannelEmail,
+			Recipient:     "ada@example.com",
+			Payload:       models.OutboxPayload(`{}`),
+			Status:        status,
+			Attempts:      3,
+			NextAttemptAt: now.Add(-time.Minute),
+		}
+		if err := repo.Create(message); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+		return message
+	}
+
+	leased := newMessage(models.OutboxStatusPending)
+	if claimed, err := repo.Claim(leased.ID, now, now.Add(5*time.Minute)); err != nil || !claimed {
+		t.Fatalf("claim = %v, %v; want claimed", claimed, err)
+	}
+	if requeued, err := repo.Requeue(leased.ID, now.Add(time.Minute)); err != nil || requeued {
+		t.Errorf("requeue during the lease = %v, %v; want refused", requeued, err)
+	}
+	if requeued, err := repo.Requeue(leased.ID, now.Add(6*time.Minute)); err != nil || !requeued {
+		t.Errorf("requeue after the lease = %v, %v; want requeued", requeued, err)
+	}
+	saved, err := repo.FindByID(leased.ID)
+	if err != nil {
+		t.Fatalf("find message: %v", err)
+	}
+	if saved.Attempts != 0 || saved.LeasedUntil != nil {
+		t.Errorf("requeued message has %d attempts and lease %v, want 0 and none", saved.Attempts, saved.LeasedUntil)
+	}
+
+	dead := newMessage(models.OutboxStatusDead)
+	if requeued, err := repo.Requeue(dead.ID, now); err != nil || !requeued {
+		t.Errorf("requeue dead message = %v, %v; want requeued", requeued, err)
+	}
+
+	sent := newMessage(models.OutboxStatusSent)
+	if requeued, err := repo.Requeue(sent.ID, now); err != nil || requeued {
+		t.Errorf("requeue sent message = %v, %v; want refused", requeued, err)
+	}
+}
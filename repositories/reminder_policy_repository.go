@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"errors"
+
+	"virtual-cuppa-be/models"
+
+	"gorm.io/gorm"
+)
+
+type ReminderPolicyRepository interface {
+	Save(policy *models.ReminderPolicy) error
+	FindByOrganisation(organisationID uint) (*models.ReminderPolicy, error)
+}
+
+type reminderPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderPolicyRepository(db *gorm.DB) ReminderPolicyRepository {
+	return &reminderPolicyRepository{db: db}
+}
+
+func (r *reminderPolicyRepository) Save(policy *models.ReminderPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+func (r *reminderPolicyRepository) FindByOrganisation(organisationID uint) (*models.ReminderPolicy, error) {
+	var policy models.ReminderPolicy
+	err := r.db.Where("organisation_id = ?", organisationID).First(&policy).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"errors"
+	"virtual-cuppa-be/models"
+
+	"gorm.io/gorm"
+)
+
+type RescheduleProposalRepository interface {
+	Create(proposal *models.RescheduleProposal) error
+	Update(proposal *models.RescheduleProposal) error
+	FindByID(id uint) (*models.RescheduleProposal, error)
+	FindByMatch(matchID uint) ([]*models.RescheduleProposal, error)
+	FindPendingByMatch(matchID uint) (*models.RescheduleProposal, error)
+}
+
+type rescheduleProposalRepository struct {
+	db *gorm.DB
+}
+
+func NewRescheduleProposalRepository(db *gorm.DB) RescheduleProposalRepository {
+	return &rescheduleProposalRepository{db: db}
+}
+
+func (r *rescheduleProposalRepository) Create(proposal *models.RescheduleProposal) error {
+	return r.db.Create(proposal).Error
+}
+
+func (r *rescheduleProposalRepository) Update(proposal *models.RescheduleProposal) error {
+	return r.db.Save(proposal).Error
+}
+
+func (r *rescheduleProposalRepository) FindByID(id uint) (*models.RescheduleProposal, error) {
+	var proposal models.RescheduleProposal
+	err := r.db.First(&proposal, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+// FindByMatch returns the match's proposals, oldest first
+func (r *rescheduleProposalRepository) FindByMatch(matchID uint) ([]*models.RescheduleProposal, error) {
+	var proposals []*models.RescheduleProposal
+	err := r.db.Preload("ProposedBy").
+		Where("match_id = ?", matchID).
+		Order("created_at ASC").
+		Find(&proposals).Error
+	return proposals, err
+}
+
+func (r *rescheduleProposalRepository) FindPendingByMatch(matchID uint) (*models.RescheduleProposal, error) {
+	var proposal models.RescheduleProposal
+	err := r.db.Where("match_id = ? AND status = ?", matchID, models.RescheduleStatusPending).
+		First(&proposal).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &proposal, nil
+}
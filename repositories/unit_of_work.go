@@ -0,0 +1,38 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// TxRepositories are repositories bound to one transaction
+type TxRepositories struct {
+	Users               UserRepository
+	Matches             MatchRepository
+	Reminders           MatchReminderRepository
+	RescheduleProposals RescheduleProposalRepository
+	Outbox              OutboxRepository
+}
+
+// UnitOfWork commits a state change together with the outbox messages that describe it
+type UnitOfWork interface {
+	// Do runs fn in a transaction, committed when fn returns nil and rolled back otherwise
+	Do(fn func(repos *TxRepositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(fn func(repos *TxRepositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&TxRepositories{
+			Users:               NewUserRepository(tx),
+			Matches:             NewMatchRepository(tx),
+			Reminders:           NewMatchReminderRepository(tx),
+			RescheduleProposals: NewRescheduleProposalRepository(tx),
+			Outbox:              NewOutboxRepository(tx),
+		})
+	})
+}
@@ -14,6 +14,7 @@ type UserRepository interface {
 	FindByEmail(email string) (*models.User, error)
 	FindByID(id uint) (*models.User, error)
 	FindByRefreshToken(token string) (*models.User, error)
+	FindByCalendarFeedToken(token string) (*models.User, error)
 	FindByOrganisation(organisationID uint) ([]*models.User, error)
 	Update(user *models.User) error
 	Delete(id uint) error
@@ -71,6 +72,18 @@ func (r *userRepository) FindByRefreshToken(token string) (*models.User, error)
 	return &user, nil
 }
 
+func (r *userRepository) FindByCalendarFeedToken(token string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("calendar_feed_token = ?", token).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *userRepository) FindByOrganisation(organisationID uint) ([]*models.User, error) {
 	var users []*models.User
 	err := r.db.Preload("Tags").Where("organisation_id = ?", organisationID).Find(&users).Error
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"virtual-cuppa-be/services"
+)
+
+// MatchExpiryScheduler periodically expires matches nobody acted on so that the
+// users involved become eligible for new matches again
+type MatchExpiryScheduler struct {
+	matchService     services.MatchService
+	acceptanceWindow time.Duration
+	interval         time.Duration
+	stopChan         chan bool
+	ticker           *time.Ticker
+}
+
+func NewMatchExpiryScheduler(
+	matchService services.MatchService,
+	acceptanceWindow time.Duration,
+	interval time.Duration,
+) *MatchExpiryScheduler {
+	return &MatchExpiryScheduler{
+		matchService:     matchService,
+		acceptanceWindow: acceptanceWindow,
+		interval:         interval,
+		stopChan:         make(chan bool),
+	}
+}
+
+// Start begins the sweeper that expires stale matches on every interval
+func (s *MatchExpiryScheduler) Start() {
+	log.Printf("Match expiry scheduler started - pending matches expire after %s, checking every %s",
+		s.acceptanceWindow, s.interval)
+
+	go s.expireStaleMatches()
+
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.expireStaleMatches()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				log.Println("Match expiry scheduler stopped")
+				return
+			}
+		}
+	}()
+}
+
+func (s *MatchExpiryScheduler) expireStaleMatches() {
+	count, err := s.matchService.ExpireStaleMatches(s.acceptanceWindow)
+	if err != nil {
+		log.Printf("Error expiring stale matches: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Match expiry completed: %d matches expired", count)
+	}
+}
+
+func (s *MatchExpiryScheduler) Stop() {
+	s.stopChan <- true
+}
+
+// RunNow triggers an immediate expiry sweep
+func (s *MatchExpiryScheduler) RunNow() {
+	log.Println("Manual match expiry triggered")
+	go s.expireStaleMatches()
+}
@@ -9,20 +9,23 @@ import (
 )
 
 type MatchScheduler struct {
-	matchService services.MatchService
-	orgRepo      repositories.OrganisationRepository
-	stopChan     chan bool
-	ticker       *time.Ticker
+	matchService    services.MatchService
+	calendarService services.HolidayCalendarService
+	orgRepo         repositories.OrganisationRepository
+	stopChan        chan bool
+	ticker          *time.Ticker
 }
 
 func NewMatchScheduler(
 	matchService services.MatchService,
+	calendarService services.HolidayCalendarService,
 	orgRepo repositories.OrganisationRepository,
 ) *MatchScheduler {
 	return &MatchScheduler{
-		matchService: matchService,
-		orgRepo:      orgRepo,
-		stopChan:     make(chan bool),
+		matchService:    matchService,
+		calendarService: calendarService,
+		orgRepo:         orgRepo,
+		stopChan:        make(chan bool),
 	}
 }
 
@@ -64,7 +67,18 @@ func (s *MatchScheduler) checkAndGenerateMatches() {
 	successfulOrgs := 0
 
 	for _, org := range orgs {
-		count, err := s.matchService.GenerateMatchesForOrganisation(org.ID)
+		// No matching at all while the company is shut down
+		blackedOut, err := s.calendarService.IsBlackedOut(org.ID, time.Now())
+		if err != nil {
+			log.Printf("Error checking blackout periods for organisation %s (ID: %d): %v", org.Name, org.ID, err)
+			continue
+		}
+		if blackedOut {
+			log.Printf("Organisation %s (ID: %d): In a blackout period, skipping match generation", org.Name, org.ID)
+			continue
+		}
+
+		result, err := s.matchService.GenerateMatchesForOrganisation(org.ID)
 		if err != nil {
 			if err == services.ErrNoUsersToMatch {
 				log.Printf("Organisation %s (ID: %d): Not enough users to generate matches", org.Name, org.ID)
@@ -74,9 +88,10 @@ func (s *MatchScheduler) checkAndGenerateMatches() {
 			continue
 		}
 
-		totalMatches += count
+		totalMatches += result.MatchesCreated
 		successfulOrgs++
-		log.Printf("Organisation %s (ID: %d): Generated %d matches", org.Name, org.ID, count)
+		log.Printf("Organisation %s (ID: %d): Generated %d matches, %d of %d eligible users left unmatched",
+			org.Name, org.ID, result.MatchesCreated, len(result.Unmatched), result.EligibleUsers)
 	}
 
 	log.Printf("Match generation completed: %d matches created across %d organisations (total: %d organisations)", 
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"virtual-cuppa-be/services"
+)
+
+// OutboxWorker periodically delivers queued notifications, retrying failed ones with backoff
+type OutboxWorker struct {
+	outboxService services.OutboxService
+	interval      time.Duration
+	stopChan      chan bool
+	ticker        *time.Ticker
+}
+
+func NewOutboxWorker(outboxService services.OutboxService, interval time.Duration) *OutboxWorker {
+	return &OutboxWorker{
+		outboxService: outboxService,
+		interval:      interval,
+		stopChan:      make(chan bool),
+	}
+}
+
+// Start begins delivering due messages on every interval
+func (w *OutboxWorker) Start() {
+	log.Printf("Outbox worker started - delivering every %s", w.interval)
+
+	go w.deliverDue()
+
+	w.ticker = time.NewTicker(w.interval)
+
+	go func() {
+		for {
+			select {
+			case <-w.ticker.C:
+				w.deliverDue()
+			case <-w.stopChan:
+				w.ticker.Stop()
+				log.Println("Outbox worker stopped")
+				return
+			}
+		}
+	}()
+}
+
+func (w *OutboxWorker) deliverDue() {
+	sent, failed, err := w.outboxService.DeliverDue()
+	if err != nil {
+		log.Printf("Error delivering outbox messages: %v", err)
+		return
+	}
+	if sent > 0 || failed > 0 {
+		log.Printf("Outbox delivery completed: %d sent, %d failed", sent, failed)
+	}
+}
+
+func (w *OutboxWorker) Stop() {
+	w.stopChan <- true
+}
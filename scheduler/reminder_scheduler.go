@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"virtual-cuppa-be/services"
+)
+
+// ReminderScheduler periodically sends the reminders that are due under each organisation's
+// reminder policy
+type ReminderScheduler struct {
+	reminderService services.ReminderService
+	interval        time.Duration
+	stopChan        chan bool
+	ticker          *time.Ticker
+}
+
+func NewReminderScheduler(reminderService services.ReminderService, interval time.Duration) *ReminderScheduler {
+	return &ReminderScheduler{
+		reminderService: reminderService,
+		interval:        interval,
+		stopChan:        make(chan bool),
+	}
+}
+
+// Start sends due reminders now and on every interval
+func (s *ReminderScheduler) Start() {
+	log.Printf("Reminder scheduler started - checking every %s", s.interval)
+
+	go s.sendDueReminders()
+
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.sendDueReminders()
+			case <-s.stopChan:
+				s.ticker.Stop()
+				log.Println("Reminder scheduler stopped")
+				return
+			}
+		}
+	}()
+}
+
+func (s *ReminderScheduler) sendDueReminders() {
+	count, err := s.reminderService.SendDueReminders()
+	if err != nil {
+		log.Printf("Error sending reminders: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Reminders completed: %d reminders queued", count)
+	}
+}
+
+func (s *ReminderScheduler) Stop() {
+	s.stopChan <- true
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+// maxOverrideDays bounds how long a single override may run
+const maxOverrideDays = 366
+
+var (
+	ErrOverrideNotFound = errors.New("availability override not found")
+	ErrInvalidOverride  = errors.New("invalid availability override")
+)
+
+type AvailabilityOverrideService interface {
+	GetOverrides(userID uint) ([]*models.AvailabilityOverride, error)
+	CreateOverride(userID uint, input *models.CreateAvailabilityOverrideInput) (*models.AvailabilityOverride, error)
+	DeleteOverride(userID uint, overrideID uint) error
+}
+
+type availabilityOverrideService struct {
+	overrideRepo repositories.AvailabilityOverrideRepository
+	userRepo     repositories.UserRepository
+	orgRepo      repositories.OrganisationRepository
+}
+
+func NewAvailabilityOverrideService(
+	overrideRepo repositories.AvailabilityOverrideRepository,
+	userRepo repositories.UserRepository,
+	orgRepo repositories.OrganisationRepository,
+) AvailabilityOverrideService {
+	return &availabilityOverrideService{
+		overrideRepo: overrideRepo,
+		userRepo:     userRepo,
+		orgRepo:      orgRepo,
+	}
+}
+
+// GetOverrides returns the user's current and upcoming overrides. Expired ones are
+// removed on the way.
+func (s *availabilityOverrideService) GetOverrides(userID uint) ([]*models.AvailabilityOverride, error) {
+	today, err := s.today(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.overrideRepo.DeleteEndedBefore(userID, today); err != nil {
+		fmt.Printf("ERROR: Failed to remove expired availability overrides for user %d: %v\n", userID, err)
+	}
+	return s.overrideRepo.FindByUserID(userID, today)
+}
+
+func (s *availabilityOverrideService) CreateOverride(userID uint, input *models.CreateAvailabilityOverrideInput) (*models.AvailabilityOverride, error) {
+	today, err := s.today(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := time.Parse("2006-01-02", input.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start date %q", ErrInvalidOverride, input.StartDate)
+	}
+	endDate := startDate
+	if input.EndDate != "" {
+		endDate, err = time.Parse("2006-01-02", input.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid end date %q", ErrInvalidOverride, input.EndDate)
+		}
+	}
+	switch {
+	case endDate.Before(startDate):
+		return nil, fmt.Errorf("%w: end date must not be before start date", ErrInvalidOverride)
+	case endDate.Before(today):
+		return nil, fmt.Errorf("%w: override has already ended", ErrInvalidOverride)
+	case endDate.Sub(startDate) >= maxOverrideDays*24*time.Hour:
+		return nil, fmt.Errorf("%w: override may cover at most %d days", ErrInvalidOverride, maxOverrideDays)
+	}
+
+	override := &models.AvailabilityOverride{
+		UserID:    userID,
+		Kind:      input.Kind,
+		StartDate: startDate,
+		EndDate:   endDate,
+		StartTime: input.StartTime,
+		EndTime:   input.EndTime,
+		Note:      input.Note,
+	}
+	if _, _, err := override.DayWindow(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOverride, err)
+	}
+
+	if err := s.overrideRepo.Create(override); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+func (s *availabilityOverrideService) DeleteOverride(userID uint, overrideID uint) error {
+	override, err := s.overrideRepo.FindByID(overrideID)
+	if err != nil {
+		return err
+	}
+	if override == nil || override.UserID != userID {
+		return ErrOverrideNotFound
+	}
+	return s.overrideRepo.Delete(overrideID)
+}
+
+// today returns the current date on the user's own clock, as a UTC date like stored dates
+func (s *availabilityOverrideService) today(userID uint) (time.Time, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if user == nil {
+		return time.Time{}, ErrUserNotFound
+	}
+
+	org := &models.Organisation{}
+	if user.OrganisationID != nil {
+		found, err := s.orgRepo.FindByID(*user.OrganisationID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if found != nil {
+			org = found
+		}
+	}
+
+	now := time.Now().In(user.Location(org))
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+}
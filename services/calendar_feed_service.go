@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+	"virtual-cuppa-be/utils"
+)
+
+var ErrInvalidFeedToken = errors.New("invalid calendar feed token")
+
+// feedPath is where calendar feeds are served, followed by the user's token
+const feedPath = "/api/calendar-feed/"
+
+type CalendarFeedService interface {
+	GetFeed(userID uint) (*models.CalendarFeed, error)
+	RotateFeedToken(userID uint) (*models.CalendarFeed, error)
+	RenderFeed(token string) ([]byte, error)
+}
+
+type calendarFeedService struct {
+	userRepo  repositories.UserRepository
+	orgRepo   repositories.OrganisationRepository
+	matchRepo repositories.MatchRepository
+	apiURL    string
+}
+
+func NewCalendarFeedService(userRepo repositories.UserRepository, orgRepo repositories.OrganisationRepository, matchRepo repositories.MatchRepository) CalendarFeedService {
+	return &calendarFeedService{
+		userRepo:  userRepo,
+		orgRepo:   orgRepo,
+		matchRepo: matchRepo,
+		apiURL:    strings.TrimRight(os.Getenv("API_URL"), "/"),
+	}
+}
+
+// GetFeed returns the user's feed address, creating its token on first use
+func (s *calendarFeedService) GetFeed(userID uint) (*models.CalendarFeed, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if user.CalendarFeedToken == nil {
+		return s.issueToken(user)
+	}
+	return s.feed(*user.CalendarFeedToken), nil
+}
+
+// RotateFeedToken replaces the user's feed token; the old address stops working at once
+func (s *calendarFeedService) RotateFeedToken(userID uint) (*models.CalendarFeed, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return s.issueToken(user)
+}
+
+// RenderFeed returns the calendar of the user owning the token: every scheduled cuppa, past
+// and upcoming, on the user's own clock. Cuppas that are off are kept as cancelled events so
+// that subscribed clients remove them.
+func (s *calendarFeedService) RenderFeed(token string) ([]byte, error) {
+	if token == "" {
+		return nil, ErrInvalidFeedToken
+	}
+	user, err := s.userRepo.FindByCalendarFeedToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidFeedToken
+	}
+
+	loc := time.UTC
+	if user.OrganisationID != nil {
+		org, err := s.orgRepo.FindByID(*user.OrganisationID)
+		if err != nil {
+			return nil, err
+		}
+		if org != nil {
+			loc = user.Location(org)
+		}
+	}
+
+	matches, err := s.matchRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var scheduled []*models.Match
+	for _, match := range matches {
+		if match.ScheduledStart != nil && match.ScheduledEnd != nil {
+			scheduled = append(scheduled, match)
+		}
+	}
+
+	now := time.Now()
+	var b icsBuilder
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:-//Virtual Cuppa//Feed//EN")
+	b.line("CALSCALE:GREGORIAN")
+	b.line("X-WR-CALNAME:Virtual Cuppa")
+	b.line("X-WR-TIMEZONE:" + loc.String())
+	if len(scheduled) > 0 {
+		from, to := *scheduled[0].ScheduledStart, *scheduled[0].ScheduledEnd
+		for _, match := range scheduled[1:] {
+			if match.ScheduledStart.Before(from) {
+				from = *match.ScheduledStart
+			}
+			if match.ScheduledEnd.After(to) {
+				to = *match.ScheduledEnd
+			}
+		}
+		writeVTimezone(&b, loc, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	}
+	for _, match := range scheduled {
+		writeMatchEvent(&b, match, isCancelledFor(match, user.ID), loc, now)
+	}
+	b.line("END:VCALENDAR")
+	return b.bytes(), nil
+}
+
+func (s *calendarFeedService) issueToken(user *models.User) (*models.CalendarFeed, error) {
+	token, err := utils.GenerateFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	user.CalendarFeedToken = &token
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return s.feed(token), nil
+}
+
+func (s *calendarFeedService) feed(token string) *models.CalendarFeed {
+	return &models.CalendarFeed{
+		URL:   s.apiURL + feedPath + token,
+		Token: token,
+	}
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"sort"
+	"time"
+	"virtual-cuppa-be/models"
+)
+
+// candidatePool holds everything match generation needs to know about an organisation,
+// loaded with a handful of bulk queries so that pair evaluation runs in memory
+type candidatePool struct {
+	org         *models.Organisation
+	users       []*models.User
+	usersByID   map[uint]*models.User
+	pending     map[uint]bool
+	configs     map[uint]*models.UserAvailabilityConfig
+	lastMatched map[pairKey]time.Time
+	// lastCuppa is when each user last had a cuppa, for their match cadence
+	lastCuppa map[uint]time.Time
+	blocked   map[pairKey]bool
+	// calendars hold the organisation's holidays around the scheduling range
+	calendars []*models.HolidayCalendar
+	// overrides hold each user's dated availability changes around the scheduling range
+	overrides map[uint][]*models.AvailabilityOverride
+	// free caches when each user is free within the scheduling range
+	free map[uint][]models.Interval
+	now  time.Time
+}
+
+// loadCandidatePool loads the organisation's users, their availability configs and overrides,
+// who is already in an open match, when everyone last had a cuppa, the organisation's match
+// history, blocked pairs and holidays
+func (s *matchService) loadCandidatePool(organisationID uint) (*candidatePool, error) {
+	org, err := s.orgRepo.FindByID(organisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.FindByOrganisation(organisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &candidatePool{
+		org:         org,
+		users:       users,
+		usersByID:   make(map[uint]*models.User, len(users)),
+		pending:     make(map[uint]bool),
+		configs:     make(map[uint]*models.UserAvailabilityConfig),
+		lastMatched: make(map[pairKey]time.Time),
+		lastCuppa:   make(map[uint]time.Time),
+		blocked:     make(map[pairKey]bool),
+		overrides:   make(map[uint][]*models.AvailabilityOverride),
+		free:        make(map[uint][]models.Interval),
+		now:         time.Now(),
+	}
+	if len(users) == 0 {
+		return pool, nil
+	}
+
+	userIDs := make([]uint, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+		pool.usersByID[user.ID] = user
+	}
+
+	pendingIDs, err := s.matchRepo.FindUserIDsWithPendingMatch(organisationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range pendingIDs {
+		pool.pending[id] = true
+	}
+
+	lastMatches, err := s.matchRepo.FindLastMatchedAt(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, lastMatch := range lastMatches {
+		pool.lastCuppa[lastMatch.UserID] = lastMatch.MatchedAt
+	}
+
+	configs, err := s.availConfigRepo.FindByUserIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, config := range configs {
+		pool.configs[config.UserID] = config
+	}
+
+	histories, err := s.matchHistoryRepo.FindLatestAmongUsers(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, history := range histories {
+		key := makePairKey(history.User1ID, history.User2ID)
+		if history.MatchedAt.After(pool.lastMatched[key]) {
+			pool.lastMatched[key] = history.MatchedAt
+		}
+	}
+
+	blocks, err := s.blockRepo.FindAllByOrganisation(organisationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		pool.blocked[makePairKey(block.UserID, block.BlockedUserID)] = true
+	}
+
+	// Widen the range by a day each side: users' own time zones can shift their dates
+	from, to := schedulingRange(org, pool.now)
+	pool.calendars, err = s.calendarRepo.FindByOrganisationBetween(organisationID, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := s.overrideRepo.FindByUserIDsBetween(userIDs, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		pool.overrides[override.UserID] = append(pool.overrides[override.UserID], override)
+	}
+
+	return pool, nil
+}
+
+// exclusionReason returns why a user cannot be given a new match, or "" if they can.
+// Eligible users are confirmed non-admins who have not paused matching, have no open match,
+// are due a cuppa under their cadence and have set up availability with some free time left
+// in the scheduling range.
+func (p *candidatePool) exclusionReason(user *models.User) UnmatchedReason {
+	switch {
+	case user.AccountType == models.AccountTypeAdmin:
+		return UnmatchedAdmin
+	case !user.IsConfirmed:
+		return UnmatchedUnconfirmed
+	case user.IsPaused(p.now):
+		return UnmatchedPaused
+	case p.pending[user.ID]:
+		return UnmatchedPendingMatch
+	case p.notDue(user):
+		return UnmatchedCadence
+	case p.configs[user.ID] == nil:
+		return UnmatchedNoAvailabilityConfig
+	case len(p.freeTimes(user.ID)) == 0:
+		return UnmatchedNoFreeTime
+	}
+	return ""
+}
+
+// notDue reports whether the user's last cuppa is too recent for their match cadence
+func (p *candidatePool) notDue(user *models.User) bool {
+	lastCuppa, ok := p.lastCuppa[user.ID]
+	return ok && p.now.Before(user.NextMatchAfter(lastCuppa))
+}
+
+// isEligible reports whether a user can be given a new match
+func (p *candidatePool) isEligible(user *models.User) bool {
+	return p.exclusionReason(user) == ""
+}
+
+// checkPair reports whether two users may be paired and whether the pairing would be a
+// repeat. Blocked pairs are never allowed; pairs who met before follow the organisation's
+// re-match policy. Repeats under RematchPolicyAfterEveryone are settled later by applyRematchPolicy.
+func (p *candidatePool) checkPair(user1ID, user2ID uint) (bool, bool) {
+	key := makePairKey(user1ID, user2ID)
+	if p.blocked[key] {
+		return false, false
+	}
+
+	matchedAt, wasMatched := p.lastMatched[key]
+	if !wasMatched {
+		return true, false
+	}
+
+	switch p.org.RematchPolicy {
+	case models.RematchPolicyAfterDays:
+		cutoff := p.now.AddDate(0, 0, -p.org.RematchCooldownDays)
+		return !matchedAt.After(cutoff), true
+	case models.RematchPolicyAfterEveryone:
+		return true, true
+	default:
+		return false, true
+	}
+}
+
+// canMeet reports whether the given users share a slot long enough for a cuppa
+func (p *candidatePool) canMeet(userIDs ...uint) bool {
+	return len(p.commonIntervals(userIDs)) > 0
+}
+
+// groupFits checks that a whole group shares a slot, not just every pair of members
+func (p *candidatePool) groupFits() groupFits {
+	return func(userIDs []uint) bool {
+		return p.canMeet(userIDs...)
+	}
+}
+
+// commonIntervals returns the intervals in the scheduling range in which all the given users
+// are free for a whole cuppa
+func (p *candidatePool) commonIntervals(userIDs []uint) []models.Interval {
+	free := make([][]models.Interval, len(userIDs))
+	for i, id := range userIDs {
+		free[i] = p.freeTimes(id)
+	}
+	return commonIntervals(p.org, free)
+}
+
+// freeTimes returns when the user is free in the scheduling range, in their own time zone,
+// with their dated overrides applied and skipping their holidays
+func (p *candidatePool) freeTimes(userID uint) []models.Interval {
+	if free, ok := p.free[userID]; ok {
+		return free
+	}
+
+	var free []models.Interval
+	config, user := p.configs[userID], p.usersByID[userID]
+	if config != nil && user != nil {
+		from, to := schedulingRange(p.org, p.now)
+		loc := user.Location(p.org)
+		free = freeIntervals(p.org, availability{config: config, loc: loc}, from, to)
+		free = applyOverrides(p.org, loc, free, p.overrides[userID], from, to)
+		free = models.SubtractIntervals(free, closedDays(p.calendars, user, loc))
+	}
+	p.free[userID] = free
+	return free
+}
+
+// blackedOut reports whether today, on the organisation's calendar, is in an
+// organisation-wide blackout period, when no new matches are made
+func (p *candidatePool) blackedOut() bool {
+	return isBlackoutDay(p.calendars, p.now.In(p.org.Location()))
+}
+
+// closedDays returns the days closed for the user by the organisation's and their office's
+// holiday calendars, as whole days on their own clock in chronological order
+func closedDays(calendars []*models.HolidayCalendar, user *models.User, loc *time.Location) []models.Interval {
+	closed := make(map[string]bool)
+	for _, calendar := range calendars {
+		if !calendar.AppliesTo(user) {
+			continue
+		}
+		for _, date := range calendar.Dates {
+			closed[date.DateKey()] = true
+		}
+	}
+
+	keys := make([]string, 0, len(closed))
+	for key := range closed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	days := make([]models.Interval, 0, len(keys))
+	for _, key := range keys {
+		date, _ := time.Parse("2006-01-02", key)
+		start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+		days = append(days, models.Interval{Start: start, End: start.AddDate(0, 0, 1)})
+	}
+	return days
+}
+
+// explain builds the stored explanation for a group: the tags all members share, the times
+// they are all free in the scheduling range (on the organisation's clock) and how the group scored
+func (p *candidatePool) explain(group matchGroup, runID *uint) *models.MatchExplanation {
+	explanation := &models.MatchExplanation{
+		SharedTags:     []string{},
+		CommonSlots:    []string{},
+		ScoreBreakdown: group.breakdown,
+		Repeat:         group.repeat,
+		RunID:          runID,
+	}
+
+	tagCounts := make(map[string]int)
+	var tagOrder []string
+	for _, id := range group.userIDs {
+		user := p.usersByID[id]
+		if user == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, tag := range user.Tags {
+			if seen[tag.Name] {
+				continue
+			}
+			seen[tag.Name] = true
+			if tagCounts[tag.Name] == 0 {
+				tagOrder = append(tagOrder, tag.Name)
+			}
+			tagCounts[tag.Name]++
+		}
+	}
+	for _, name := range tagOrder {
+		if tagCounts[name] == len(group.userIDs) {
+			explanation.SharedTags = append(explanation.SharedTags, name)
+		}
+	}
+
+	for _, interval := range p.commonIntervals(group.userIDs) {
+		explanation.CommonSlots = append(explanation.CommonSlots, formatInterval(interval, p.org.Location()))
+	}
+
+	return explanation
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+// The in-memory repositories below implement just the methods match planning calls; the
+// embedded interfaces are nil, so any other call panics. Every call counts as one query.
+
+type queryCounter struct {
+	queries int
+}
+
+func (c *queryCounter) query() {
+	c.queries++
+}
+
+type memOrgRepo struct {
+	repositories.OrganisationRepository
+	*queryCounter
+	org *models.Organisation
+}
+
+func (r *memOrgRepo) FindByID(id uint) (*models.Organisation, error) {
+	r.query()
+	return r.org, nil
+}
+
+type memUserRepo struct {
+	repositories.UserRepository
+	*queryCounter
+	users []*models.User
+}
+
+func (r *memUserRepo) FindByID(id uint) (*models.User, error) {
+	r.query()
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memUserRepo) FindByOrganisation(organisationID uint) ([]*models.User, error) {
+	r.query()
+	return r.users, nil
+}
+
+type memPlanningMatchRepo struct {
+	repositories.MatchRepository
+	*queryCounter
+	pending map[uint]bool
+}
+
+func (r *memPlanningMatchRepo) HasPendingMatch(userID uint) (bool, error) {
+	r.query()
+	return r.pending[userID], nil
+}
+
+func (r *memPlanningMatchRepo) FindUserIDsWithPendingMatch(organisationID uint) ([]uint, error) {
+	r.query()
+	var ids []uint
+	for id := range r.pending {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *memPlanningMatchRepo) FindLastMatchedAt(userIDs []uint) ([]*repositories.UserLastMatch, error) {
+	r.query()
+	return nil, nil
+}
+
+type memHistoryRepo struct {
+	repositories.MatchHistoryRepository
+	*queryCounter
+	histories []*models.MatchHistory
+	byPair    map[pairKey]time.Time
+}
+
+func (r *memHistoryRepo) WasEverMatched(user1ID, user2ID uint) (bool, error) {
+	r.query()
+	_, ok := r.byPair[makePairKey(user1ID, user2ID)]
+	return ok, nil
+}
+
+func (r *memHistoryRepo) WasRecentlyMatched(user1ID, user2ID uint, days int) (bool, error) {
+	r.query()
+	matchedAt, ok := r.byPair[makePairKey(user1ID, user2ID)]
+	return ok && matchedAt.After(time.Now().AddDate(0, 0, -days)), nil
+}
+
+func (r *memHistoryRepo) FindLatestAmongUsers(userIDs []uint) ([]*models.MatchHistory, error) {
+	r.query()
+	return r.histories, nil
+}
+
+type memConfigRepo struct {
+	repositories.UserAvailabilityConfigRepository
+	*queryCounter
+	configs map[uint]*models.UserAvailabilityConfig
+}
+
+func (r *memConfigRepo) FindByUserID(userID uint) (*models.UserAvailabilityConfig, error) {
+	r.query()
+	return r.configs[userID], nil
+}
+
+func (r *memConfigRepo) FindByUserIDs(userIDs []uint) ([]*models.UserAvailabilityConfig, error) {
+	r.query()
+	configs := make([]*models.UserAvailabilityConfig, 0, len(userIDs))
+	for _, id := range userIDs {
+		if config := r.configs[id]; config != nil {
+			configs = append(configs, config)
+		}
+	}
+	return configs, nil
+}
+
+type memBlockRepo struct {
+	repositories.MatchBlockRepository
+	*queryCounter
+}
+
+func (r *memBlockRepo) FindAllByOrganisation(organisationID uint) ([]*models.MatchBlock, error) {
+	r.query()
+	return nil, nil
+}
+
+type memCalendarRepo struct {
+	repositories.HolidayCalendarRepository
+	*queryCounter
+	calendars []*models.HolidayCalendar
+}
+
+func (r *memCalendarRepo) FindByOrganisationBetween(organisationID uint, from, to time.Time) ([]*models.HolidayCalendar, error) {
+	r.query()
+	return r.calendars, nil
+}
+
+type memOverrideRepo struct {
+	repositories.AvailabilityOverrideRepository
+	*queryCounter
+	overrides []*models.AvailabilityOverride
+}
+
+func (r *memOverrideRepo) FindByUserIDsBetween(userIDs []uint, from, to time.Time) ([]*models.AvailabilityOverride, error) {
+	r.query()
+	return r.overrides, nil
+}
+
+var benchmarkDays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}
+
+// newPlanningService builds a match service over an organisation of the given size. Each
+// user is free for an hour or two on two weekdays, has a few of twenty tags, one in ten
+// has an open match, and everyone has already met a handful of colleagues.
+func newPlanningService(size int) (*matchService, *queryCounter) {
+	rng := rand.New(rand.NewSource(1))
+	counter := &queryCounter{}
+	org := &models.Organisation{ID: 1, Name: "Benchmark", TimeZone: "Europe/London"}
+
+	users := make([]*models.User, size)
+	configs := make(map[uint]*models.UserAvailabilityConfig, size)
+	pending := make(map[uint]bool)
+	for i := range users {
+		id := uint(i + 1)
+		orgID := org.ID
+		user := &models.User{
+			ID:             id,
+			FirstName:      "User",
+			LastName:       fmt.Sprint(id),
+			Email:          fmt.Sprintf("user%d@example.com", id),
+			AccountType:    models.AccountTypeUser,
+			OrganisationID: &orgID,
+			IsConfirmed:    true,
+			MatchCadence:   models.MatchCadenceWeekly,
+		}
+		for _, tag := range rng.Perm(20)[:3] {
+			user.Tags = append(user.Tags, models.Tag{ID: uint(tag + 1), Name: fmt.Sprintf("tag-%d", tag)})
+		}
+		users[i] = user
+
+		var ranges models.TimeRanges
+		for _, day := range rng.Perm(len(benchmarkDays))[:2] {
+			start := 9 + rng.Intn(6)
+			ranges = append(ranges, models.TimeRange{
+				Day:   benchmarkDays[day],
+				Start: fmt.Sprintf("%02d:00", start),
+				End:   fmt.Sprintf("%02d:00", start+1+rng.Intn(2)),
+			})
+		}
+		configs[id] = &models.UserAvailabilityConfig{UserID: id, TimeRanges: ranges}
+
+		if rng.Intn(10) == 0 {
+			pending[id] = true
+		}
+	}
+
+	history := &memHistoryRepo{queryCounter: counter, byPair: make(map[pairKey]time.Time)}
+	for _, user := range users {
+		for k := 0; k < 5; k++ {
+			other := uint(rng.Intn(size) + 1)
+			if other == user.ID {
+				continue
+			}
+			matchedAt := time.Now().AddDate(0, 0, -rng.Intn(365))
+			history.byPair[makePairKey(user.ID, other)] = matchedAt
+			history.histories = append(history.histories, &models.MatchHistory{User1ID: user.ID, User2ID: other, MatchedAt: matchedAt})
+		}
+	}
+
+	s := &matchService{
+		matchRepo:        &memPlanningMatchRepo{queryCounter: counter, pending: pending},
+		matchHistoryRepo: history,
+		userRepo:         &memUserRepo{queryCounter: counter, users: users},
+		availConfigRepo:  &memConfigRepo{queryCounter: counter, configs: configs},
+		orgRepo:          &memOrgRepo{queryCounter: counter, org: org},
+		blockRepo:        &memBlockRepo{queryCounter: counter},
+		calendarRepo:     &memCalendarRepo{queryCounter: counter},
+		overrideRepo:     &memOverrideRepo{queryCounter: counter},
+	}
+	return s, counter
+}
+
+// loadCandidatePoolPerPair loads the pool the way match generation did before candidates
+// were loaded in bulk: a pending match and availability query per user and a history query
+// per pair of eligible users.
+func (s *matchService) loadCandidatePoolPerPair(organisationID uint) (*candidatePool, error) {
+	org, err := s.orgRepo.FindByID(organisationID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.FindByOrganisation(organisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &candidatePool{
+		org:         org,
+		users:       users,
+		usersByID:   make(map[uint]*models.User, len(users)),
+		pending:     make(map[uint]bool),
+		configs:     make(map[uint]*models.UserAvailabilityConfig),
+		lastMatched: make(map[pairKey]time.Time),
+		lastCuppa:   make(map[uint]time.Time),
+		blocked:     make(map[pairKey]bool),
+		overrides:   make(map[uint][]*models.AvailabilityOverride),
+		free:        make(map[uint][]models.Interval),
+		now:         time.Now(),
+	}
+
+	var eligible []uint
+	for _, user := range users {
+		pool.usersByID[user.ID] = user
+		hasPending, err := s.matchRepo.HasPendingMatch(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hasPending {
+			pool.pending[user.ID] = true
+			continue
+		}
+		config, err := s.availConfigRepo.FindByUserID(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if config != nil {
+			pool.configs[user.ID] = config
+			eligible = append(eligible, user.ID)
+		}
+	}
+
+	for i := 0; i < len(eligible); i++ {
+		for j := i + 1; j < len(eligible); j++ {
+			wasMatched, err := s.matchHistoryRepo.WasEverMatched(eligible[i], eligible[j])
+			if err != nil {
+				return nil, err
+			}
+			if wasMatched {
+				pool.lastMatched[makePairKey(eligible[i], eligible[j])] = pool.now
+			}
+		}
+	}
+	return pool, nil
+}
+
+// BenchmarkPlanMatches compares planning a run from the bulk-loaded candidate pool with the
+// old per-pair query path. Both plan in memory; queries/op is what a database would see.
+func BenchmarkPlanMatches(b *testing.B) {
+	loaders := []struct {
+		name string
+		load func(s *matchService) (*candidatePool, error)
+	}{
+		{"bulk", func(s *matchService) (*candidatePool, error) { return s.loadCandidatePool(1) }},
+		{"per-pair", func(s *matchService) (*candidatePool, error) { return s.loadCandidatePoolPerPair(1) }},
+	}
+	for _, size := range []int{200, 2000} {
+		for _, loader := range loaders {
+			b.Run(fmt.Sprintf("%s/users=%d", loader.name, size), func(b *testing.B) {
+				s, counter := newPlanningService(size)
+				counter.queries = 0
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					pool, err := loader.load(s)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if plan := s.planMatches(pool); len(plan.groups) == 0 {
+						b.Fatal("no matches planned")
+					}
+				}
+				b.ReportMetric(float64(counter.queries)/float64(b.N), "queries/op")
+			})
+		}
+	}
+}
+
+// TestTryGenerateMatchForUserSkipsBlackout checks a per-user re-match makes no match on an
+// organisation-wide blackout day; the in-memory match repository would panic on Create
+func TestTryGenerateMatchForUserSkipsBlackout(t *testing.T) {
+	s, _ := newPlanningService(20)
+	today := time.Now().In(s.orgRepo.(*memOrgRepo).org.Location())
+	s.calendarRepo.(*memCalendarRepo).calendars = []*models.HolidayCalendar{{
+		OrganisationID: 1,
+		Name:           "Shutdown",
+		Kind:           models.CalendarKindBlackout,
+		Dates:          []models.CalendarDate{{Date: time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)}},
+	}}
+
+	pool, err := s.loadCandidatePool(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pool.blackedOut() {
+		t.Fatal("blackout day not detected")
+	}
+	for _, user := range pool.users {
+		if err := s.TryGenerateMatchForUser(user.ID); err != nil {
+			t.Fatalf("user %d: %v", user.ID, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 
@@ -11,24 +12,45 @@ import (
 type EmailService interface {
 	SendConfirmCode(toEmail string, toName string, confirmCode string) error
 	SendInvitation(toEmail string, toName string, organisationName string) error
-	SendMatchAccepted(toEmail string, toName string, matchName string, matchEmail string, availabilitySlots []AvailabilitySlot) error
+	SendMatchCreated(toEmail string, toName string, participantNames []string, sharedTags []string, meetingTime string, acceptURL string, rejectURL string) error
+	SendAcceptanceReminder(toEmail string, toName string, participantNames []string, meetingTime string, acceptURL string, rejectURL string) error
+	SendMatchAccepted(toEmail string, toName string, matchName string, matchEmail string, meetingTime string, availabilitySlots []AvailabilitySlot) error
+	SendRescheduleProposed(toEmail string, toName string, proposerName string, slots []string, counter bool) error
+	SendRescheduleAccepted(toEmail string, toName string, accepterName string, slot string, invite []byte) error
+	SendMatchConfirmed(toEmail string, toName string, participantNames []string, meetingTime string, invite []byte) error
+	SendMatchCancelled(toEmail string, toName string, meetingTime string, cancellation []byte) error
+	SendFeedbackReminder(toEmail string, toName string, participantNames []string, meetingTime string, feedbackDeadline string) error
 }
 
 type emailService struct {
-	apiKey                  string
-	confirmCodeTemplateID   string
-	userInvitationTemplateID string
-	matchAcceptedTemplateID string
-	appURL                  string
+	apiKey                       string
+	confirmCodeTemplateID        string
+	userInvitationTemplateID     string
+	matchCreatedTemplateID       string
+	matchAcceptedTemplateID      string
+	rescheduleProposedTemplateID string
+	rescheduleAcceptedTemplateID string
+	matchConfirmedTemplateID     string
+	matchCancelledTemplateID     string
+	acceptanceReminderTemplateID string
+	feedbackReminderTemplateID   string
+	appURL                       string
 }
 
 func NewEmailService() EmailService {
 	return &emailService{
-		apiKey:                  os.Getenv("SENDGRID_API_KEY"),
-		confirmCodeTemplateID:   os.Getenv("CONFIRM_CODE_TEMPLATE_ID"),
-		userInvitationTemplateID: os.Getenv("USER_INVITATION_TEMPLATE_ID"),
-		matchAcceptedTemplateID: os.Getenv("MATCH_ACCEPTED_TEMPLATE_ID"),
-		appURL:                  os.Getenv("APP_URL"),
+		apiKey:                       os.Getenv("SENDGRID_API_KEY"),
+		confirmCodeTemplateID:        os.Getenv("CONFIRM_CODE_TEMPLATE_ID"),
+		userInvitationTemplateID:     os.Getenv("USER_INVITATION_TEMPLATE_ID"),
+		matchCreatedTemplateID:       os.Getenv("MATCH_CREATED_TEMPLATE_ID"),
+		matchAcceptedTemplateID:      os.Getenv("MATCH_ACCEPTED_TEMPLATE_ID"),
+		rescheduleProposedTemplateID: os.Getenv("RESCHEDULE_PROPOSED_TEMPLATE_ID"),
+		rescheduleAcceptedTemplateID: os.Getenv("RESCHEDULE_ACCEPTED_TEMPLATE_ID"),
+		matchConfirmedTemplateID:     os.Getenv("MATCH_CONFIRMED_TEMPLATE_ID"),
+		matchCancelledTemplateID:     os.Getenv("MATCH_CANCELLED_TEMPLATE_ID"),
+		acceptanceReminderTemplateID: os.Getenv("ACCEPTANCE_REMINDER_TEMPLATE_ID"),
+		feedbackReminderTemplateID:   os.Getenv("FEEDBACK_REMINDER_TEMPLATE_ID"),
+		appURL:                       os.Getenv("APP_URL"),
 	}
 }
 
@@ -98,7 +120,86 @@ func (s *emailService) SendInvitation(toEmail string, toName string, organisatio
 	return nil
 }
 
-func (s *emailService) SendMatchAccepted(toEmail string, toName string, matchName string, matchEmail string, availabilitySlots []AvailabilitySlot) error {
+// SendMatchCreated tells a participant about their new cuppa, with links to accept or reject
+// it without logging in
+func (s *emailService) SendMatchCreated(toEmail string, toName string, participantNames []string, sharedTags []string, meetingTime string, acceptURL string, rejectURL string) error {
+	if s.apiKey == "" || s.matchCreatedTemplateID == "" {
+		return fmt.Errorf("sendgrid not configured for new match notifications: API_KEY=%v, TEMPLATE_ID=%v", s.apiKey != "", s.matchCreatedTemplateID != "")
+	}
+
+	from := mail.NewEmail("Virtual Cuppa", "noreply@notacv.com")
+	to := mail.NewEmail(toName, toEmail)
+
+	message := mail.NewV3Mail()
+	message.SetFrom(from)
+	message.SetTemplateID(s.matchCreatedTemplateID)
+
+	personalization := mail.NewPersonalization()
+	personalization.AddTos(to)
+	personalization.SetDynamicTemplateData("UserName", toName)
+	personalization.SetDynamicTemplateData("ParticipantNames", participantNames)
+	personalization.SetDynamicTemplateData("SharedTags", sharedTags)
+	personalization.SetDynamicTemplateData("MeetingTime", meetingTime)
+	personalization.SetDynamicTemplateData("AcceptURL", acceptURL)
+	personalization.SetDynamicTemplateData("RejectURL", rejectURL)
+	personalization.SetDynamicTemplateData("AppURL", s.appURL)
+
+	message.AddPersonalizations(personalization)
+
+	client := sendgrid.NewSendClient(s.apiKey)
+	response, err := client.Send(message)
+
+	if err != nil {
+		return fmt.Errorf("failed to send new match notification to %s: %w", toEmail, err)
+	}
+
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("sendgrid error for new match notification to %s: status code %d, body: %s", toEmail, response.StatusCode, response.Body)
+	}
+
+	return nil
+}
+
+// SendAcceptanceReminder nudges a participant who hasn't answered their cuppa, with the same
+// links to accept or reject it as the new match email
+func (s *emailService) SendAcceptanceReminder(toEmail string, toName string, participantNames []string, meetingTime string, acceptURL string, rejectURL string) error {
+	if s.apiKey == "" || s.acceptanceReminderTemplateID == "" {
+		return fmt.Errorf("sendgrid not configured for acceptance reminders: API_KEY=%v, TEMPLATE_ID=%v", s.apiKey != "", s.acceptanceReminderTemplateID != "")
+	}
+
+	from := mail.NewEmail("Virtual Cuppa", "noreply@notacv.com")
+	to := mail.NewEmail(toName, toEmail)
+
+	message := mail.NewV3Mail()
+	message.SetFrom(from)
+	message.SetTemplateID(s.acceptanceReminderTemplateID)
+
+	personalization := mail.NewPersonalization()
+	personalization.AddTos(to)
+	personalization.SetDynamicTemplateData("UserName", toName)
+	personalization.SetDynamicTemplateData("ParticipantNames", participantNames)
+	personalization.SetDynamicTemplateData("MeetingTime", meetingTime)
+	personalization.SetDynamicTemplateData("AcceptURL", acceptURL)
+	personalization.SetDynamicTemplateData("RejectURL", rejectURL)
+	personalization.SetDynamicTemplateData("AppURL", s.appURL)
+
+	message.AddPersonalizations(personalization)
+
+	client := sendgrid.NewSendClient(s.apiKey)
+	response, err := client.Send(message)
+
+	if err != nil {
+		return fmt.Errorf("failed to send acceptance reminder to %s: %w", toEmail, err)
+	}
+
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("sendgrid error for acceptance reminder to %s: status code %d, body: %s", toEmail, response.StatusCode, response.Body)
+	}
+
+	return nil
+}
+
+func (s *emailService) SendMatchAccepted(toEmail string, toName string, matchName string, matchEmail string, meetingTime string, availabilitySlots []AvailabilitySlot) error {
 	if s.apiKey == "" || s.matchAcceptedTemplateID == "" {
 		return fmt.Errorf("sendgrid not configured for match accepted notifications: API_KEY=%v, TEMPLATE_ID=%v", s.apiKey != "", s.matchAcceptedTemplateID != "")
 	}
@@ -114,6 +215,7 @@ func (s *emailService) SendMatchAccepted(toEmail string, toName string, matchNam
 	personalization.AddTos(to)
 	personalization.SetDynamicTemplateData("MatchName", matchName)
 	personalization.SetDynamicTemplateData("MatchEmail", matchEmail)
+	personalization.SetDynamicTemplateData("MeetingTime", meetingTime)
 	personalization.SetDynamicTemplateData("AvailabilitySlots", availabilitySlots)
 	
 	message.AddPersonalizations(personalization)
@@ -123,6 +225,7 @@ func (s *emailService) SendMatchAccepted(toEmail string, toName string, matchNam
 	fmt.Printf("  To: %s (%s)\n", toEmail, toName)
 	fmt.Printf("  Template ID: %s\n", s.matchAcceptedTemplateID)
 	fmt.Printf("  MatchName: %s\n", matchName)
+	fmt.Printf("  MeetingTime: %s\n", meetingTime)
 	fmt.Printf("  AvailabilitySlots (%d items):\n", len(availabilitySlots))
 	for i, slot := range availabilitySlots {
 		fmt.Printf("    [%d] Day: %s, Period: %s\n", i, slot.Day, slot.Period)
@@ -143,3 +246,195 @@ func (s *emailService) SendMatchAccepted(toEmail string, toName string, matchNam
 	
 	return nil
 }
+
+func (s *emailService) SendRescheduleProposed(toEmail string, toName string, proposerName string, slots []string, counter bool) error {
+	if s.apiKey == "" || s.rescheduleProposedTemplateID == "" {
+		return fmt.Errorf("sendgrid not configured for reschedule proposals: API_KEY=%v, TEMPLATE_ID=%v", s.apiKey != "", s.rescheduleProposedTemplateID != "")
+	}
+
+	from := mail.NewEmail("Virtual Cuppa", "noreply@notacv.com")
+	to := mail.NewEmail(toName, toEmail)
+
+	message := mail.NewV3Mail()
+	message.SetFrom(from)
+	message.SetTemplateID(s.rescheduleProposedTemplateID)
+
+	personalization := mail.NewPersonalization()
+	personalization.AddTos(to)
+	personalization.SetDynamicTemplateData("ProposerName", proposerName)
+	personalization.SetDynamicTemplateData("Slots", slots)
+	personalization.SetDynamicTemplateData("Counter", counter)
+	personalization.SetDynamicTemplateData("AppURL", s.appURL)
+
+	message.AddPersonalizations(personalization)
+
+	client := sendgrid.NewSendClient(s.apiKey)
+	response, err := client.Send(message)
+
+	if err != nil {
+		return fmt.Errorf("failed to send reschedule proposal to %s: %w", toEmail, err)
+	}
+
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("sendgrid error for reschedule proposal to %s: status code %d, body: %s", toEmail, response.StatusCode, response.Body)
+	}
+
+	return nil
+}
+
+func (s *emailService) SendRescheduleAccepted(toEmail string, toName string, accepterName string, slot string, invite []byte) error {
+	if s.apiKey == "" || s.rescheduleAcceptedTemplateID == "" {
+		return fmt.Errorf("sendgrid not configured for reschedule confirmations: API_KEY=%v, TEMPLATE_ID=%v", s.apiKey != "", s.rescheduleAcceptedTemplateID != "")
+	}
+
+	from := mail.NewEmail("Virtual Cuppa", "noreply@notacv.com")
+	to := mail.NewEmail(toName, toEmail)
+
+	message := mail.NewV3Mail()
+	message.SetFrom(from)
+	message.SetTemplateID(s.rescheduleAcceptedTemplateID)
+
+	personalization := mail.NewPersonalization()
+	personalization.AddTos(to)
+	personalization.SetDynamicTemplateData("AccepterName", accepterName)
+	personalization.SetDynamicTemplateData("Slot", slot)
+	personalization.SetDynamicTemplateData("AppURL", s.appURL)
+
+	message.AddPersonalizations(personalization)
+	attachInvite(message, invite, "REQUEST")
+
+	client := sendgrid.NewSendClient(s.apiKey)
+	response, err := client.Send(message)
+
+	if err != nil {
+		return fmt.Errorf("failed to send reschedule confirmation to %s: %w", toEmail, err)
+	}
+
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("sendgrid error for reschedule confirmation to %s: status code %d, body: %s", toEmail, response.StatusCode, response.Body)
+	}
+
+	return nil
+}
+
+// SendMatchConfirmed tells a participant that everyone accepted, with the calendar invite attached
+func (s *emailService) SendMatchConfirmed(toEmail string, toName string, participantNames []string, meetingTime string, invite []byte) error {
+	if s.apiKey == "" || s.matchConfirmedTemplateID == "" {
+		return fmt.Errorf("sendgrid not configured for match confirmations: API_KEY=%v, TEMPLATE_ID=%v", s.apiKey != "", s.matchConfirmedTemplateID != "")
+	}
+
+	from := mail.NewEmail("Virtual Cuppa", "noreply@notacv.com")
+	to := mail.NewEmail(toName, toEmail)
+
+	message := mail.NewV3Mail()
+	message.SetFrom(from)
+	message.SetTemplateID(s.matchConfirmedTemplateID)
+
+	personalization := mail.NewPersonalization()
+	personalization.AddTos(to)
+	personalization.SetDynamicTemplateData("ParticipantNames", participantNames)
+	personalization.SetDynamicTemplateData("MeetingTime", meetingTime)
+	personalization.SetDynamicTemplateData("AppURL", s.appURL)
+
+	message.AddPersonalizations(personalization)
+	attachInvite(message, invite, "REQUEST")
+
+	client := sendgrid.NewSendClient(s.apiKey)
+	response, err := client.Send(message)
+
+	if err != nil {
+		return fmt.Errorf("failed to send match confirmation to %s: %w", toEmail, err)
+	}
+
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("sendgrid error for match confirmation to %s: status code %d, body: %s", toEmail, response.StatusCode, response.Body)
+	}
+
+	return nil
+}
+
+// SendMatchCancelled tells a participant that a confirmed cuppa is off, with a calendar
+// cancellation attached
+func (s *emailService) SendMatchCancelled(toEmail string, toName string, meetingTime string, cancellation []byte) error {
+	if s.apiKey == "" || s.matchCancelledTemplateID == "" {
+		return fmt.Errorf("sendgrid not configured for match cancellations: API_KEY=%v, TEMPLATE_ID=%v", s.apiKey != "", s.matchCancelledTemplateID != "")
+	}
+
+	from := mail.NewEmail("Virtual Cuppa", "noreply@notacv.com")
+	to := mail.NewEmail(toName, toEmail)
+
+	message := mail.NewV3Mail()
+	message.SetFrom(from)
+	message.SetTemplateID(s.matchCancelledTemplateID)
+
+	personalization := mail.NewPersonalization()
+	personalization.AddTos(to)
+	personalization.SetDynamicTemplateData("MeetingTime", meetingTime)
+	personalization.SetDynamicTemplateData("AppURL", s.appURL)
+
+	message.AddPersonalizations(personalization)
+	attachInvite(message, cancellation, "CANCEL")
+
+	client := sendgrid.NewSendClient(s.apiKey)
+	response, err := client.Send(message)
+
+	if err != nil {
+		return fmt.Errorf("failed to send match cancellation to %s: %w", toEmail, err)
+	}
+
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("sendgrid error for match cancellation to %s: status code %d, body: %s", toEmail, response.StatusCode, response.Body)
+	}
+
+	return nil
+}
+
+// attachInvite adds an iCalendar attachment that mail clients offer to add to the calendar
+func attachInvite(message *mail.SGMailV3, invite []byte, method string) {
+	if len(invite) == 0 {
+		return
+	}
+	attachment := mail.NewAttachment()
+	attachment.SetContent(base64.StdEncoding.EncodeToString(invite))
+	attachment.SetType("text/calendar; method=" + method)
+	attachment.SetFilename("cuppa.ics")
+	attachment.SetDisposition("attachment")
+	message.AddAttachment(attachment)
+}
+
+// SendFeedbackReminder asks a participant to rate their cuppa before the feedback window closes
+func (s *emailService) SendFeedbackReminder(toEmail string, toName string, participantNames []string, meetingTime string, feedbackDeadline string) error {
+	if s.apiKey == "" || s.feedbackReminderTemplateID == "" {
+		return fmt.Errorf("sendgrid not configured for feedback reminders: API_KEY=%v, TEMPLATE_ID=%v", s.apiKey != "", s.feedbackReminderTemplateID != "")
+	}
+
+	from := mail.NewEmail("Virtual Cuppa", "noreply@notacv.com")
+	to := mail.NewEmail(toName, toEmail)
+
+	message := mail.NewV3Mail()
+	message.SetFrom(from)
+	message.SetTemplateID(s.feedbackReminderTemplateID)
+
+	personalization := mail.NewPersonalization()
+	personalization.AddTos(to)
+	personalization.SetDynamicTemplateData("UserName", toName)
+	personalization.SetDynamicTemplateData("ParticipantNames", participantNames)
+	personalization.SetDynamicTemplateData("MeetingTime", meetingTime)
+	personalization.SetDynamicTemplateData("FeedbackDeadline", feedbackDeadline)
+	personalization.SetDynamicTemplateData("AppURL", s.appURL)
+
+	message.AddPersonalizations(personalization)
+
+	client := sendgrid.NewSendClient(s.apiKey)
+	response, err := client.Send(message)
+
+	if err != nil {
+		return fmt.Errorf("failed to send feedback reminder to %s: %w", toEmail, err)
+	}
+
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("sendgrid error for feedback reminder to %s: status code %d, body: %s", toEmail, response.StatusCode, response.Body)
+	}
+
+	return nil
+}
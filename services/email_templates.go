@@ -0,0 +1,166 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The files in email-templates/ used by each email. They are written for SendGrid dynamic
+// templates; the SMTP backend renders the same files locally.
+const (
+	confirmCodeTemplate        = "confirm-code-template.html"
+	userInvitationTemplate     = "user-invitation-template.html"
+	matchCreatedTemplate       = "match-created-template.html"
+	matchAcceptedTemplate      = "match-accepted-template.html"
+	rescheduleProposedTemplate = "reschedule-proposed-template.html"
+	rescheduleAcceptedTemplate = "reschedule-accepted-template.html"
+	matchConfirmedTemplate     = "match-confirmed-template.html"
+	matchCancelledTemplate     = "match-cancelled-template.html"
+	acceptanceReminderTemplate = "acceptance-reminder-template.html"
+	feedbackReminderTemplate   = "feedback-reminder-template.html"
+)
+
+var emailTemplateFiles = []string{
+	confirmCodeTemplate,
+	userInvitationTemplate,
+	matchCreatedTemplate,
+	matchAcceptedTemplate,
+	rescheduleProposedTemplate,
+	rescheduleAcceptedTemplate,
+	matchConfirmedTemplate,
+	matchCancelledTemplate,
+	acceptanceReminderTemplate,
+	feedbackReminderTemplate,
+}
+
+// emailTemplate is one file of email-templates/, parsed with html/template. Its <title> is
+// the subject line.
+type emailTemplate struct {
+	subject string
+	html    *template.Template
+}
+
+func getEmailTemplatesPath() string {
+	path := os.Getenv("EMAIL_TEMPLATES_PATH")
+	if path == "" {
+		path = "./email-templates"
+	}
+	return path
+}
+
+// loadEmailTemplates parses every email template in dir
+func loadEmailTemplates(dir string) (map[string]*emailTemplate, error) {
+	templates := make(map[string]*emailTemplate, len(emailTemplateFiles))
+	for _, name := range emailTemplateFiles {
+		source, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("could not read email template: %w", err)
+		}
+		converted, err := handlebarsToGo(string(source))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		parsed, err := template.New(name).Option("missingkey=zero").Parse(converted)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		subject := "Virtual Cuppa"
+		if m := titleElement.FindStringSubmatch(string(source)); m != nil {
+			subject = strings.TrimSpace(html.UnescapeString(m[1]))
+		}
+		templates[name] = &emailTemplate{subject: subject, html: parsed}
+	}
+	return templates, nil
+}
+
+// render returns the HTML body and its plain-text alternative
+func (t *emailTemplate) render(data map[string]interface{}) (string, string, error) {
+	var body bytes.Buffer
+	if err := t.html.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return body.String(), htmlToText(body.String()), nil
+}
+
+var handlebarsTag = regexp.MustCompile(`\{\{\s*([#/]?)([@\w.]+)(?:\s+([@\w.]+))?\s*\}\}`)
+
+// handlebarsToGo rewrites the Handlebars used by the templates ({{Name}}, {{#if}}, {{else}},
+// {{#each}}, {{this}} and {{@index}}) into Go template syntax
+func handlebarsToGo(source string) (string, error) {
+	var err error
+	converted := handlebarsTag.ReplaceAllStringFunc(source, func(tag string) string {
+		m := handlebarsTag.FindStringSubmatch(tag)
+		block, name, arg := m[1], m[2], m[3]
+		switch {
+		case block == "#" && name == "if" && arg != "":
+			return "{{if " + handlebarsValue(arg) + "}}"
+		case block == "#" && name == "each" && arg != "":
+			return "{{range $index, $item := " + handlebarsValue(arg) + "}}"
+		case block == "/" && (name == "if" || name == "each") && arg == "":
+			return "{{end}}"
+		case block == "" && name == "else" && arg == "":
+			return "{{else}}"
+		case block == "" && arg == "":
+			return "{{" + handlebarsValue(name) + "}}"
+		}
+		if err == nil {
+			err = fmt.Errorf("unsupported template tag %s", tag)
+		}
+		return tag
+	})
+	return converted, err
+}
+
+func handlebarsValue(name string) string {
+	switch {
+	case name == "this":
+		return "."
+	case strings.HasPrefix(name, "@"):
+		return "$" + name[1:]
+	}
+	return "." + name
+}
+
+var (
+	titleElement = regexp.MustCompile(`(?is)<title>(.*?)</title>`)
+	headElement  = regexp.MustCompile(`(?is)<head>.*?</head>`)
+	linkElement  = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a\s*>`)
+	lineBreak    = regexp.MustCompile(`(?i)<br\s*/?>|</(li|tr)\s*>`)
+	paragraphEnd = regexp.MustCompile(`(?i)</(p|div|h[1-6]|table)\s*>`)
+	anyTag       = regexp.MustCompile(`(?s)<[^>]*>`)
+	whitespace   = regexp.MustCompile(`\s+`)
+	blankLines   = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText derives the plain-text alternative of a rendered email. Links keep their
+// address in brackets unless the text already shows it.
+func htmlToText(body string) string {
+	text := headElement.ReplaceAllString(body, "")
+	text = whitespace.ReplaceAllString(text, " ")
+	text = linkElement.ReplaceAllStringFunc(text, func(link string) string {
+		m := linkElement.FindStringSubmatch(link)
+		href := html.UnescapeString(m[1])
+		label := strings.TrimSpace(html.UnescapeString(anyTag.ReplaceAllString(m[2], "")))
+		if label == "" || label == strings.TrimPrefix(href, "mailto:") {
+			return html.EscapeString(strings.TrimPrefix(href, "mailto:"))
+		}
+		return m[2] + " (" + html.EscapeString(href) + ")"
+	})
+	text = lineBreak.ReplaceAllString(text, "\n")
+	text = paragraphEnd.ReplaceAllString(text, "\n\n")
+	text = html.UnescapeString(anyTag.ReplaceAllString(text, ""))
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text) + "\n"
+}
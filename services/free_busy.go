@@ -0,0 +1,139 @@
+package services
+
+import (
+	"sort"
+	"time"
+	"virtual-cuppa-be/models"
+)
+
+const (
+	// freeBusyWeeks is how many upcoming weeks of an imported calendar are sampled
+	freeBusyWeeks = 4
+	// freeBusyMinFreeWeeks is in how many sampled weeks a time must be free to count as a
+	// recurring free window, so that one-off meetings don't remove it
+	freeBusyMinFreeWeeks = 3
+	// freeBusySlotMinutes is the granularity of proposed windows
+	freeBusySlotMinutes = 15
+)
+
+// freeBusyRange returns the weeks an imported calendar is sampled over: freeBusyWeeks whole
+// weeks from the next Monday on the user's clock
+func freeBusyRange(loc *time.Location, now time.Time) (time.Time, time.Time) {
+	local := now.In(loc)
+	daysToMonday := (8 - int(local.Weekday())) % 7
+	if daysToMonday == 0 {
+		daysToMonday = 7
+	}
+	from := time.Date(local.Year(), local.Month(), local.Day()+daysToMonday, 0, 0, 0, 0, loc)
+	return from, from.AddDate(0, 0, 7*freeBusyWeeks)
+}
+
+// busyIntervals returns the merged times within [from, to) that the events block, in
+// chronological order, along with how many events blocked any time. All-day events block
+// whole days on loc's clock. Recurring events are expanded, with modified occurrences
+// (RECURRENCE-ID) replacing the ones they were moved from. Floating times are on loc's clock.
+func busyIntervals(events []icsEvent, loc *time.Location, from, to time.Time) ([]models.Interval, int) {
+	onClock := make([]icsEvent, len(events))
+	for i, event := range events {
+		onClock[i] = event.onClock(loc)
+	}
+	events = onClock
+
+	moved := make(map[string][]time.Time)
+	for _, event := range events {
+		if !event.RecurrenceID.IsZero() {
+			moved[event.UID] = append(moved[event.UID], event.RecurrenceID)
+		}
+	}
+
+	var busy []models.Interval
+	busyEvents := 0
+	for _, event := range events {
+		if event.Free {
+			continue
+		}
+		if event.AllDay {
+			event.Start = time.Date(event.Start.Year(), event.Start.Month(), event.Start.Day(), 0, 0, 0, 0, loc)
+			event.End = time.Date(event.End.Year(), event.End.Month(), event.End.Day(), 0, 0, 0, 0, loc)
+		}
+		if event.RecurrenceID.IsZero() {
+			event.ExDates = append(event.ExDates, moved[event.UID]...)
+		}
+
+		length := event.End.Sub(event.Start)
+		starts := event.occurrences(from, to)
+		if len(starts) > 0 && length > 0 {
+			busyEvents++
+		}
+		for _, start := range starts {
+			if length > 0 {
+				busy = append(busy, models.Interval{Start: start, End: start.Add(length)})
+			}
+		}
+	}
+	return mergeIntervals(busy), busyEvents
+}
+
+// proposeWindows finds the weekday windows within working hours that are free in at least
+// freeBusyMinFreeWeeks of the sampled weeks and long enough for a cuppa. Weekends are left
+// out: an empty weekend in a work calendar says nothing about availability.
+func proposeWindows(org *models.Organisation, loc *time.Location, busy []models.Interval, from time.Time) []models.WeeklyWindow {
+	startHour, endHour := org.WorkingHours()
+	var windows []models.WeeklyWindow
+	for day := 0; day < 5; day++ {
+		weekday := from.AddDate(0, 0, day).Weekday()
+		current := -1
+		for minute := startHour * 60; minute < endHour*60; minute += freeBusySlotMinutes {
+			freeWeeks := 0
+			for week := 0; week < freeBusyWeeks; week++ {
+				date := from.AddDate(0, 0, 7*week+day)
+				slot := models.Interval{
+					Start: time.Date(date.Year(), date.Month(), date.Day(), 0, minute, 0, 0, loc),
+					End:   time.Date(date.Year(), date.Month(), date.Day(), 0, minute+freeBusySlotMinutes, 0, 0, loc),
+				}
+				if len(models.IntersectIntervals([]models.Interval{slot}, busy)) == 0 {
+					freeWeeks++
+				}
+			}
+
+			if freeWeeks < freeBusyMinFreeWeeks {
+				current = -1
+				continue
+			}
+			if current >= 0 {
+				windows[current].End = minute + freeBusySlotMinutes
+				continue
+			}
+			windows = append(windows, models.WeeklyWindow{Day: weekday, Start: minute, End: minute + freeBusySlotMinutes})
+			current = len(windows) - 1
+		}
+	}
+
+	var long []models.WeeklyWindow
+	for _, w := range windows {
+		if w.Duration() >= org.CuppaDuration() {
+			long = append(long, w)
+		}
+	}
+	return long
+}
+
+// mergeIntervals sorts intervals and joins those that overlap or touch
+func mergeIntervals(intervals []models.Interval) []models.Interval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+
+	var merged []models.Interval
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !interval.Start.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+)
+
+func TestOccurrences(t *testing.T) {
+	london, _ := time.LoadLocation("Europe/London")
+	at := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	// The sampled week is Monday 2 to Sunday 8 March 2026
+	from, to := at(2026, 3, 2, 0), at(2026, 3, 9, 0)
+	tests := []struct {
+		name  string
+		event icsEvent
+		from  time.Time
+		to    time.Time
+		want  []time.Time
+	}{
+		{
+			"single event",
+			icsEvent{Start: at(2026, 3, 3, 9), End: at(2026, 3, 3, 10)},
+			from, to,
+			[]time.Time{at(2026, 3, 3, 9)},
+		},
+		{
+			"weekly on two days",
+			icsEvent{Start: at(2026, 2, 2, 9), End: at(2026, 2, 2, 10), RRule: "FREQ=WEEKLY;BYDAY=MO,WE"},
+			from, to,
+			[]time.Time{at(2026, 3, 2, 9), at(2026, 3, 4, 9)},
+		},
+		{
+			"fortnightly",
+			icsEvent{Start: at(2026, 2, 23, 9), End: at(2026, 2, 23, 10), RRule: "FREQ=WEEKLY;INTERVAL=2"},
+			from, at(2026, 3, 23, 0),
+			[]time.Time{at(2026, 3, 9, 9)},
+		},
+		{
+			"every other day",
+			icsEvent{Start: at(2026, 3, 1, 9), End: at(2026, 3, 1, 10), RRule: "FREQ=DAILY;INTERVAL=2"},
+			from, to,
+			[]time.Time{at(2026, 3, 3, 9), at(2026, 3, 5, 9), at(2026, 3, 7, 9)},
+		},
+		{
+			"daily on weekdays with an exception",
+			icsEvent{
+				Start: at(2026, 3, 2, 9), End: at(2026, 3, 2, 10), RRule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+				ExDates: []time.Time{at(2026, 3, 4, 9)},
+			},
+			from, to,
+			[]time.Time{at(2026, 3, 2, 9), at(2026, 3, 3, 9), at(2026, 3, 5, 9), at(2026, 3, 6, 9)},
+		},
+		{
+			"count",
+			icsEvent{Start: at(2026, 3, 1, 9), End: at(2026, 3, 1, 10), RRule: "FREQ=DAILY;COUNT=3"},
+			from, to,
+			[]time.Time{at(2026, 3, 2, 9), at(2026, 3, 3, 9)},
+		},
+		{
+			"until a date includes its day",
+			icsEvent{Start: at(2026, 3, 2, 9), End: at(2026, 3, 2, 10), RRule: "FREQ=DAILY;UNTIL=20260303"},
+			from, to,
+			[]time.Time{at(2026, 3, 2, 9), at(2026, 3, 3, 9)},
+		},
+		{
+			"until a time includes it",
+			icsEvent{Start: at(2026, 3, 2, 9), End: at(2026, 3, 2, 10), RRule: "FREQ=DAILY;UNTIL=20260304T090000Z"},
+			from, to,
+			[]time.Time{at(2026, 3, 2, 9), at(2026, 3, 3, 9), at(2026, 3, 4, 9)},
+		},
+		{
+			"overlapping the start of the range",
+			icsEvent{Start: at(2026, 2, 1, 23), End: at(2026, 2, 2, 1), RRule: "FREQ=WEEKLY"},
+			from, to,
+			[]time.Time{at(2026, 3, 1, 23), at(2026, 3, 8, 23)},
+		},
+		{
+			"daily for years",
+			icsEvent{Start: at(2012, 1, 2, 9), End: at(2012, 1, 2, 10), RRule: "FREQ=DAILY;BYDAY=MO,FR"},
+			from, to,
+			[]time.Time{at(2026, 3, 2, 9), at(2026, 3, 6, 9)},
+		},
+		{
+			"weekly for a century",
+			icsEvent{Start: at(1926, 3, 3, 9), End: at(1926, 3, 3, 10), RRule: "FREQ=WEEKLY;BYDAY=TU"},
+			from, to,
+			[]time.Time{at(2026, 3, 3, 9)},
+		},
+		{
+			"count used up long ago",
+			icsEvent{Start: at(2012, 1, 2, 9), End: at(2012, 1, 2, 10), RRule: "FREQ=DAILY;COUNT=10"},
+			from, to,
+			nil,
+		},
+		{
+			"keeps the wall clock across DST",
+			icsEvent{Start: time.Date(2026, 3, 23, 9, 0, 0, 0, london), End: time.Date(2026, 3, 23, 10, 0, 0, 0, london), RRule: "FREQ=WEEKLY"},
+			at(2026, 3, 23, 0), at(2026, 4, 6, 0),
+			[]time.Time{time.Date(2026, 3, 23, 9, 0, 0, 0, london), time.Date(2026, 3, 30, 9, 0, 0, 0, london)},
+		},
+		{
+			"monthly counts once",
+			icsEvent{Start: at(2026, 3, 3, 9), End: at(2026, 3, 3, 10), RRule: "FREQ=MONTHLY"},
+			from, to,
+			[]time.Time{at(2026, 3, 3, 9)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.event.occurrences(tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("occurrences = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// weekly returns the busy intervals of an event on the given days of the four sampled weeks,
+// between the given times of day, skipping the weeks listed
+func weekly(from time.Time, days []int, start, end string, skipWeeks ...int) []models.Interval {
+	clock := func(date time.Time, value string) time.Time {
+		var hour, minute int
+		fmt.Sscanf(value, "%d:%d", &hour, &minute)
+		return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, from.Location())
+	}
+	skip := make(map[int]bool)
+	for _, week := range skipWeeks {
+		skip[week] = true
+	}
+	var busy []models.Interval
+	for week := 0; week < freeBusyWeeks; week++ {
+		if skip[week] {
+			continue
+		}
+		for _, day := range days {
+			date := from.AddDate(0, 0, 7*week+day)
+			busy = append(busy, models.Interval{
+				Start: clock(date, start),
+				End:   clock(date, end),
+			})
+		}
+	}
+	return busy
+}
+
+func TestProposeWindows(t *testing.T) {
+	warsaw, _ := time.LoadLocation("Europe/Warsaw")
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, warsaw)
+	org := &models.Organisation{WorkingHoursStart: 9, WorkingHoursEnd: 17}
+	weekdays := []int{0, 1, 2, 3, 4}
+	tests := []struct {
+		name string
+		busy []models.Interval
+		want string
+	}{
+		{"empty calendar", nil, "[Monday 09:00-17:00 Tuesday 09:00-17:00 Wednesday 09:00-17:00 Thursday 09:00-17:00 Friday 09:00-17:00]"},
+		{"recurring meetings", weekly(from, weekdays, "09:00", "12:00"), "[Monday 12:00-17:00 Tuesday 12:00-17:00 Wednesday 12:00-17:00 Thursday 12:00-17:00 Friday 12:00-17:00]"},
+		{"a one-off meeting is ignored", weekly(from, weekdays, "09:00", "17:00", 1, 2, 3), "[Monday 09:00-17:00 Tuesday 09:00-17:00 Wednesday 09:00-17:00 Thursday 09:00-17:00 Friday 09:00-17:00]"},
+		{"busy in two weeks of four", weekly(from, weekdays, "13:00", "17:00", 2, 3), "[Monday 09:00-13:00 Tuesday 09:00-13:00 Wednesday 09:00-13:00 Thursday 09:00-13:00 Friday 09:00-13:00]"},
+		{"gaps shorter than a cuppa are dropped", append(weekly(from, weekdays, "09:00", "12:00"), weekly(from, weekdays, "12:15", "16:30")...), "[Monday 16:30-17:00 Tuesday 16:30-17:00 Wednesday 16:30-17:00 Thursday 16:30-17:00 Friday 16:30-17:00]"},
+		{"fully booked", weekly(from, weekdays, "08:00", "18:00"), "[]"},
+		{"weekends are left out", weekly(from, []int{0, 1, 2, 3}, "00:00", "24:00"), "[Friday 09:00-17:00]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := proposeWindows(org, warsaw, mergeIntervals(tt.busy), from)
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("windows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestImportICSDiff checks the proposal from a calendar of long-running weekly meetings and
+// how it differs from the saved configuration
+func TestImportICSDiff(t *testing.T) {
+	ics := icsCalendar(
+		// Mornings have been busy since 2005; Thursdays are busy until 15:00
+		"BEGIN:VEVENT", "UID:mornings", "DTSTART;TZID=Europe/Warsaw:20050103T090000",
+		"DTEND;TZID=Europe/Warsaw:20050103T120000", "RRULE:FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:thursdays", "DTSTART;TZID=Europe/Warsaw:20050106T120000",
+		"DTEND;TZID=Europe/Warsaw:20050106T150000", "RRULE:FREQ=WEEKLY;BYDAY=TH", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:fridays", "DTSTART;TZID=Europe/Warsaw:20050107T120000",
+		"DTEND;TZID=Europe/Warsaw:20050107T170000", "RRULE:FREQ=WEEKLY;BYDAY=FR", "END:VEVENT",
+	)
+	saved := &models.UserAvailabilityConfig{UserID: 1, TimeRanges: models.TimeRanges{
+		{Day: "Monday", Start: "09:00", End: "17:00"},
+		{Day: "Friday", Start: "09:00", End: "10:00"},
+	}}
+	s := &userAvailabilityConfigService{
+		configRepo: &memConfigRepo{queryCounter: &queryCounter{}, configs: map[uint]*models.UserAvailabilityConfig{1: saved}},
+		userRepo:   &memUserRepo{queryCounter: &queryCounter{}, users: []*models.User{{ID: 1, TimeZone: "Europe/Warsaw"}}},
+		orgRepo:    &memOrgRepo{queryCounter: &queryCounter{}},
+	}
+
+	result, err := s.ImportICS(1, strings.NewReader(ics), false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	tests := []struct {
+		name string
+		got  models.TimeRanges
+		want string
+	}{
+		{"proposed", result.TimeRanges, "[{Monday 12:00 17:00} {Tuesday 12:00 17:00} {Wednesday 12:00 17:00} {Thursday 15:00 17:00}]"},
+		{"added", result.Added, "[{Tuesday 12:00 17:00} {Wednesday 12:00 17:00} {Thursday 15:00 17:00}]"},
+		{"removed", result.Removed, "[{Monday 09:00 12:00} {Friday 09:00 10:00}]"},
+	}
+	for _, tt := range tests {
+		if fmt.Sprint(tt.got) != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if result.BusyEvents != 3 || result.Applied {
+		t.Errorf("busy events %d, applied %v; want 3, not applied", result.BusyEvents, result.Applied)
+	}
+}
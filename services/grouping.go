@@ -0,0 +1,203 @@
+package services
+
+import "sort"
+
+// matchGroup is a set of users selected to meet together. Pairs are groups of two.
+type matchGroup struct {
+	userIDs []uint
+	score   float64
+	// breakdown is the per-strategy score, averaged over every pair of members
+	breakdown map[string]float64
+	// repeat is set when any two members have been matched before
+	repeat bool
+}
+
+// pairKey identifies an unordered pair of users
+type pairKey [2]uint
+
+func makePairKey(a, b uint) pairKey {
+	if a > b {
+		a, b = b, a
+	}
+	return pairKey{a, b}
+}
+
+// pairScores indexes candidate pairs by their unordered key
+func pairScores(pairs []userPair) map[pairKey]float64 {
+	scores := make(map[pairKey]float64, len(pairs))
+	for _, pair := range pairs {
+		scores[makePairKey(pair.user1ID, pair.user2ID)] = pair.score
+	}
+	return scores
+}
+
+// pairIndex indexes candidate pairs by their unordered key
+func pairIndex(pairs []userPair) map[pairKey]userPair {
+	index := make(map[pairKey]userPair, len(pairs))
+	for _, pair := range pairs {
+		index[makePairKey(pair.user1ID, pair.user2ID)] = pair
+	}
+	return index
+}
+
+// describe fills in the score breakdown and repeat flag of the group from its member pairs
+func (g *matchGroup) describe(index map[pairKey]userPair) {
+	g.breakdown = make(map[string]float64)
+	var count int
+	for i := 0; i < len(g.userIDs); i++ {
+		for j := i + 1; j < len(g.userIDs); j++ {
+			pair, ok := index[makePairKey(g.userIDs[i], g.userIDs[j])]
+			if !ok {
+				continue
+			}
+			for name, score := range pair.breakdown {
+				g.breakdown[name] += score
+			}
+			g.repeat = g.repeat || pair.repeat
+			count++
+		}
+	}
+	for name := range g.breakdown {
+		g.breakdown[name] /= float64(count)
+	}
+}
+
+// groupScore is the mean pair score over every pair of members
+func groupScore(userIDs []uint, scores map[pairKey]float64) float64 {
+	var total float64
+	var count int
+	for i := 0; i < len(userIDs); i++ {
+		for j := i + 1; j < len(userIDs); j++ {
+			total += scores[makePairKey(userIDs[i], userIDs[j])]
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// groupFits reports whether a full set of members can meet together (e.g. shares a slot)
+type groupFits func(userIDs []uint) bool
+
+// withMember returns a copy of group with id appended
+func withMember(group []uint, id uint) []uint {
+	return append(append(make([]uint, 0, len(group)+1), group...), id)
+}
+
+// growGroup starts from seed and repeatedly adds the candidate that is compatible
+// with every current member and has the highest summed score towards them
+func growGroup(seed uint, candidates []uint, scores map[pairKey]float64, size int, fits groupFits) []uint {
+	group := []uint{seed}
+	taken := map[uint]bool{seed: true}
+
+	for len(group) < size {
+		var best uint
+		bestScore := -1.0
+		for _, candidate := range candidates {
+			if taken[candidate] {
+				continue
+			}
+			total, compatible := 0.0, true
+			for _, member := range group {
+				score, ok := scores[makePairKey(member, candidate)]
+				if !ok {
+					compatible = false
+					break
+				}
+				total += score
+			}
+			if compatible && total > bestScore && (fits == nil || fits(withMember(group, candidate))) {
+				best, bestScore = candidate, total
+			}
+		}
+		if bestScore < 0 {
+			break
+		}
+		group = append(group, best)
+		taken[best] = true
+	}
+
+	return group
+}
+
+// formGroups partitions users into cuppa groups of up to groupSize members in which
+// every pair of members is compatible. Users with the fewest compatible partners are
+// seeded first so that they are not squeezed out by easier-to-place colleagues.
+func formGroups(userIDs []uint, pairs []userPair, groupSize int, fits groupFits) []matchGroup {
+	scores := pairScores(pairs)
+
+	degree := make(map[uint]int, len(userIDs))
+	for key := range scores {
+		degree[key[0]]++
+		degree[key[1]]++
+	}
+
+	order := append([]uint{}, userIDs...)
+	sort.SliceStable(order, func(i, j int) bool {
+		if degree[order[i]] != degree[order[j]] {
+			return degree[order[i]] < degree[order[j]]
+		}
+		return order[i] < order[j]
+	})
+
+	assigned := make(map[uint]bool, len(userIDs))
+	var groups [][]uint
+
+	for _, seed := range order {
+		if assigned[seed] || degree[seed] == 0 {
+			continue
+		}
+		var candidates []uint
+		for _, id := range order {
+			if !assigned[id] && id != seed {
+				candidates = append(candidates, id)
+			}
+		}
+		group := growGroup(seed, candidates, scores, groupSize, fits)
+		if len(group) < 2 {
+			continue
+		}
+		for _, id := range group {
+			assigned[id] = true
+		}
+		groups = append(groups, group)
+	}
+
+	// Place leftovers into groups that still have room and where they fit with everyone
+	for _, id := range order {
+		if assigned[id] {
+			continue
+		}
+		bestGroup := -1
+		bestScore := -1.0
+		for g, group := range groups {
+			if len(group) >= groupSize {
+				continue
+			}
+			total, compatible := 0.0, true
+			for _, member := range group {
+				score, ok := scores[makePairKey(member, id)]
+				if !ok {
+					compatible = false
+					break
+				}
+				total += score
+			}
+			if compatible && total > bestScore && (fits == nil || fits(withMember(group, id))) {
+				bestGroup, bestScore = g, total
+			}
+		}
+		if bestGroup >= 0 {
+			groups[bestGroup] = append(groups[bestGroup], id)
+			assigned[id] = true
+		}
+	}
+
+	result := make([]matchGroup, len(groups))
+	for i, group := range groups {
+		result[i] = matchGroup{userIDs: group, score: groupScore(group, scores)}
+	}
+	return result
+}
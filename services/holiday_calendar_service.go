@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+var (
+	ErrCalendarNotFound    = errors.New("holiday calendar not found")
+	ErrInvalidCalendarDate = errors.New("invalid calendar date, expected YYYY-MM-DD")
+	ErrEmptyCalendar       = errors.New("calendar has no dates")
+)
+
+// maxEventDays caps how many days a single imported event may close
+const maxEventDays = 366
+
+type HolidayCalendarService interface {
+	GetCalendars(organisationID uint) ([]*models.HolidayCalendar, error)
+	CreateCalendar(organisationID uint, input *models.CreateHolidayCalendarInput) (*models.HolidayCalendar, error)
+	ImportICS(organisationID uint, input *models.ImportHolidayCalendarInput, ics io.Reader) (*models.HolidayCalendar, error)
+	DeleteCalendar(organisationID uint, calendarID uint) error
+	IsBlackedOut(organisationID uint, now time.Time) (bool, error)
+}
+
+type holidayCalendarService struct {
+	calendarRepo repositories.HolidayCalendarRepository
+	orgRepo      repositories.OrganisationRepository
+}
+
+func NewHolidayCalendarService(calendarRepo repositories.HolidayCalendarRepository, orgRepo repositories.OrganisationRepository) HolidayCalendarService {
+	return &holidayCalendarService{
+		calendarRepo: calendarRepo,
+		orgRepo:      orgRepo,
+	}
+}
+
+func (s *holidayCalendarService) GetCalendars(organisationID uint) ([]*models.HolidayCalendar, error) {
+	return s.calendarRepo.FindByOrganisation(organisationID)
+}
+
+func (s *holidayCalendarService) CreateCalendar(organisationID uint, input *models.CreateHolidayCalendarInput) (*models.HolidayCalendar, error) {
+	dates := make([]models.CalendarDate, 0, len(input.Dates))
+	for _, d := range input.Dates {
+		date, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			return nil, ErrInvalidCalendarDate
+		}
+		dates = append(dates, models.CalendarDate{Date: date, Name: d.Name})
+	}
+	return s.create(organisationID, input.Name, input.Kind, input.Office, dates)
+}
+
+// ImportICS creates a calendar from the events of an iCalendar file, closing every day
+// an event touches
+func (s *holidayCalendarService) ImportICS(organisationID uint, input *models.ImportHolidayCalendarInput, ics io.Reader) (*models.HolidayCalendar, error) {
+	events, err := parseICS(ics)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []models.CalendarDate
+	for _, event := range events {
+		for _, day := range eventDays(event) {
+			dates = append(dates, models.CalendarDate{Date: day, Name: event.Summary})
+		}
+	}
+	return s.create(organisationID, input.Name, input.Kind, input.Office, dates)
+}
+
+func (s *holidayCalendarService) DeleteCalendar(organisationID uint, calendarID uint) error {
+	calendar, err := s.calendarRepo.FindByID(calendarID)
+	if err != nil {
+		return err
+	}
+	if calendar == nil || calendar.OrganisationID != organisationID {
+		return ErrCalendarNotFound
+	}
+	return s.calendarRepo.Delete(calendarID)
+}
+
+// IsBlackedOut reports whether today, on the organisation's calendar, is in an
+// organisation-wide blackout period
+func (s *holidayCalendarService) IsBlackedOut(organisationID uint, now time.Time) (bool, error) {
+	org, err := s.orgRepo.FindByID(organisationID)
+	if err != nil {
+		return false, err
+	}
+
+	today := now.In(org.Location())
+	calendars, err := s.calendarRepo.FindByOrganisationBetween(organisationID, today, today)
+	if err != nil {
+		return false, err
+	}
+	return isBlackoutDay(calendars, today), nil
+}
+
+// isBlackoutDay reports whether the calendars put the day, on its own clock, in an
+// organisation-wide blackout period
+func isBlackoutDay(calendars []*models.HolidayCalendar, day time.Time) bool {
+	key := day.Format("2006-01-02")
+	for _, calendar := range calendars {
+		if calendar.Kind != models.CalendarKindBlackout || calendar.Office != "" {
+			continue
+		}
+		for _, date := range calendar.Dates {
+			if date.DateKey() == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// create saves a calendar, dropping repeated days
+func (s *holidayCalendarService) create(organisationID uint, name string, kind models.CalendarKind, office string, dates []models.CalendarDate) (*models.HolidayCalendar, error) {
+	seen := make(map[string]bool)
+	unique := make([]models.CalendarDate, 0, len(dates))
+	for _, date := range dates {
+		if seen[date.DateKey()] {
+			continue
+		}
+		seen[date.DateKey()] = true
+		unique = append(unique, date)
+	}
+	if len(unique) == 0 {
+		return nil, ErrEmptyCalendar
+	}
+
+	if kind == "" {
+		kind = models.CalendarKindHoliday
+	}
+	calendar := &models.HolidayCalendar{
+		OrganisationID: organisationID,
+		Name:           name,
+		Kind:           kind,
+		Office:         office,
+		Dates:          unique,
+	}
+	if err := s.calendarRepo.Create(calendar); err != nil {
+		return nil, err
+	}
+	return calendar, nil
+}
+
+// eventDays returns the dates an event covers. All-day events end on their exclusive end
+// date; timed events cover every day from their start to their end on their own clock.
+func eventDays(event icsEvent) []time.Time {
+	first := time.Date(event.Start.Year(), event.Start.Month(), event.Start.Day(), 0, 0, 0, 0, time.UTC)
+	last := first
+	if event.AllDay {
+		if event.End.After(event.Start) {
+			end := event.End.AddDate(0, 0, -1)
+			last = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+		}
+	} else if event.End.After(event.Start) {
+		end := event.End.Add(-time.Nanosecond).In(event.Start.Location())
+		last = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	var days []time.Time
+	for day := first; !day.After(last) && len(days) < maxEventDays; day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
@@ -0,0 +1,631 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"virtual-cuppa-be/models"
+)
+
+var ErrInvalidICS = errors.New("invalid ICS file")
+
+// icsEvent is a VEVENT read from an iCalendar file. All-day events have date-only
+// starts and ends, with End exclusive as in the iCalendar format.
+type icsEvent struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+	AllDay  bool
+	// Floating is set for times without a time zone, or with a TZID that isn't an IANA
+	// zone such as Outlook's "W. Europe Standard Time". They are parsed as UTC and belong
+	// on the clock of whoever reads the calendar; see onClock.
+	Floating bool
+	// Free is set for events that don't block time: TRANSP:TRANSPARENT or cancelled
+	Free bool
+	// RRule, ExDates and RecurrenceID describe recurring events; see expandICSEvents
+	RRule        string
+	ExDates      []time.Time
+	RecurrenceID time.Time
+}
+
+// parseICS reads the events of an iCalendar (RFC 5545) file. Only the properties matching
+// needs are understood: UID, SUMMARY, DTSTART, DTEND, TRANSP, STATUS and the recurrence
+// properties, with UTC, TZID or floating times. Times in a zone Go doesn't know are read as
+// floating rather than failing the whole file.
+func parseICS(r io.Reader) ([]icsEvent, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []icsEvent
+	var current *icsEvent
+	var nested string
+	var duration time.Duration
+	sawCalendar := false
+	for _, line := range lines {
+		name, params, value, ok := splitICSLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && value == "VCALENDAR":
+			sawCalendar = true
+		case name == "BEGIN" && value == "VEVENT":
+			current = &icsEvent{}
+			nested, duration = "", 0
+		case name == "END" && value == "VEVENT":
+			if current == nil || current.Start.IsZero() {
+				return nil, ErrInvalidICS
+			}
+			if current.End.IsZero() {
+				// Without DTEND the event lasts its DURATION, or one day if it is all-day
+				current.End = current.Start.Add(duration)
+				if current.AllDay && duration == 0 {
+					current.End = current.Start.AddDate(0, 0, 1)
+				}
+			}
+			events = append(events, *current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "BEGIN":
+			// Nested components such as VALARM carry properties of their own
+			nested = value
+		case name == "END" && value == nested:
+			nested = ""
+		case nested != "":
+			continue
+		case name == "UID":
+			current.UID = value
+		case name == "TRANSP":
+			current.Free = current.Free || strings.EqualFold(value, "TRANSPARENT")
+		case name == "STATUS":
+			current.Free = current.Free || strings.EqualFold(value, "CANCELLED")
+		case name == "RRULE":
+			current.RRule = value
+		case name == "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				exDate, _, err := parseICSTime(v, params)
+				if err != nil {
+					return nil, ErrInvalidICS
+				}
+				current.ExDates = append(current.ExDates, exDate)
+			}
+		case name == "RECURRENCE-ID":
+			recurrenceID, _, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, ErrInvalidICS
+			}
+			current.RecurrenceID = recurrenceID
+		case name == "SUMMARY":
+			current.Summary = unescapeICSText(value)
+		case name == "DTSTART":
+			start, allDay, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, ErrInvalidICS
+			}
+			current.Start, current.AllDay = start, allDay
+			current.Floating = !allDay && isFloatingICSTime(value, params)
+		case name == "DURATION":
+			d, err := parseICSDuration(value)
+			if err != nil {
+				return nil, ErrInvalidICS
+			}
+			duration = d
+		case name == "DTEND":
+			end, _, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, ErrInvalidICS
+			}
+			current.End = end
+		}
+	}
+
+	if !sawCalendar || current != nil {
+		return nil, ErrInvalidICS
+	}
+	return events, nil
+}
+
+// unfoldICSLines joins folded lines (continuations start with a space or tab)
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ErrInvalidICS
+	}
+	return lines, nil
+}
+
+// splitICSLine splits "DTSTART;TZID=Europe/London:20260101T090000" into its name,
+// parameters and value
+func splitICSLine(line string) (string, map[string]string, string, bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", nil, "", false
+	}
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		if key, value, found := strings.Cut(param, "="); found {
+			params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// parseICSTime parses a DATE or DATE-TIME value, reporting whether it was a date
+func parseICSTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := models.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// isFloatingICSTime reports whether a DATE-TIME value is parsed as floating: it has neither a
+// UTC suffix nor a TZID naming an IANA zone
+func isFloatingICSTime(value string, params map[string]string) bool {
+	if strings.HasSuffix(value, "Z") {
+		return false
+	}
+	tzid := params["TZID"]
+	if tzid == "" {
+		return true
+	}
+	_, err := models.LoadLocation(tzid)
+	return err != nil
+}
+
+// onClock returns the event with floating times read as wall clock times in loc
+func (e icsEvent) onClock(loc *time.Location) icsEvent {
+	if !e.Floating {
+		return e
+	}
+	e.Start, e.End = wallClock(e.Start, loc), wallClock(e.End, loc)
+	exDates := make([]time.Time, len(e.ExDates))
+	for i, exDate := range e.ExDates {
+		exDates[i] = wallClock(exDate, loc)
+	}
+	e.ExDates = exDates
+	if !e.RecurrenceID.IsZero() {
+		e.RecurrenceID = wallClock(e.RecurrenceID, loc)
+	}
+	return e
+}
+
+// wallClock returns the time showing the same date and clock time as t in loc
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// parseICSDuration parses a positive DURATION value such as "PT1H30M" or "P1D"
+func parseICSDuration(value string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(value, "+"), "P")
+	if !ok || rest == "" {
+		return 0, ErrInvalidICS
+	}
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+	var total time.Duration
+	number := ""
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+		case c == 'T':
+			continue
+		default:
+			unit, known := units[c]
+			n, err := strconv.Atoi(number)
+			if !known || err != nil {
+				return 0, ErrInvalidICS
+			}
+			total += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, ErrInvalidICS
+	}
+	return total, nil
+}
+
+func unescapeICSText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// maxICSOccurrences bounds how many occurrences of one recurring event are generated
+const maxICSOccurrences = 5000
+
+var icsWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// icsRRule is the part of an RRULE that occurrences are expanded from
+type icsRRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	// Until is exclusive; a date-only UNTIL includes its whole day
+	Until time.Time
+	ByDay map[time.Weekday]bool
+}
+
+// parseICSRRule parses a recurrence rule such as "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20260601T000000Z"
+func parseICSRRule(value string) (*icsRRule, error) {
+	rule := &icsRRule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, v, found := strings.Cut(part, "=")
+		if !found {
+			return nil, ErrInvalidICS
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(v)
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, ErrInvalidICS
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, ErrInvalidICS
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, date, err := parseICSTime(v, nil)
+			if err != nil {
+				return nil, ErrInvalidICS
+			}
+			if date {
+				until = until.AddDate(0, 0, 1)
+			} else {
+				until = until.Add(time.Second)
+			}
+			rule.Until = until
+		case "BYDAY":
+			rule.ByDay = make(map[time.Weekday]bool)
+			for _, day := range strings.Split(v, ",") {
+				// Ordinals such as "1MO" only make sense monthly; the weekday is kept
+				weekday, ok := icsWeekdays[strings.ToUpper(strings.TrimLeft(day, "+-0123456789"))]
+				if !ok {
+					return nil, ErrInvalidICS
+				}
+				rule.ByDay[weekday] = true
+			}
+		}
+	}
+	if rule.Freq == "" {
+		return nil, ErrInvalidICS
+	}
+	return rule, nil
+}
+
+// occurrences returns the starts of the event's occurrences that overlap [from, to).
+// Daily and weekly rules are expanded with INTERVAL, COUNT, UNTIL, BYDAY and EXDATE; an
+// event with any other rule only counts once, at its DTSTART. Occurrences keep the wall
+// clock time of DTSTART across DST changes. Expansion starts at the period before from, so
+// long-running events are found however old they are; rules with a COUNT are walked from
+// DTSTART, as the occurrences before from count towards it.
+func (e icsEvent) occurrences(from, to time.Time) []time.Time {
+	length := e.End.Sub(e.Start)
+	overlaps := func(start time.Time) bool {
+		return start.Before(to) && start.Add(length).After(from)
+	}
+
+	var rule *icsRRule
+	if e.RRule != "" {
+		rule, _ = parseICSRRule(e.RRule)
+	}
+	if rule == nil || (rule.Freq != "DAILY" && rule.Freq != "WEEKLY") {
+		if overlaps(e.Start) {
+			return []time.Time{e.Start}
+		}
+		return nil
+	}
+
+	excluded := make(map[int64]bool, len(e.ExDates))
+	for _, exDate := range e.ExDates {
+		excluded[exDate.Unix()] = true
+	}
+
+	first := 0
+	if rule.Count == 0 {
+		periodDays := rule.Interval
+		if rule.Freq == "WEEKLY" {
+			periodDays *= 7
+		}
+		// One period of slack covers DST shifts and weeks starting before DTSTART's weekday
+		if elapsed := int(from.Add(-length).Sub(e.Start).Hours() / 24); elapsed > 0 {
+			first = max(elapsed/periodDays-1, 0)
+		}
+	}
+
+	var starts []time.Time
+	count := 0
+	for period := first; period < first+maxICSOccurrences; period++ {
+		var candidates []time.Time
+		if rule.Freq == "DAILY" {
+			day := e.Start.AddDate(0, 0, period*rule.Interval)
+			if rule.ByDay == nil || rule.ByDay[day.Weekday()] {
+				candidates = append(candidates, day)
+			}
+		} else {
+			week := e.Start.AddDate(0, 0, period*rule.Interval*7)
+			if rule.ByDay == nil {
+				candidates = append(candidates, week)
+			} else {
+				// Weeks start on Monday, the iCalendar default
+				monday := week.AddDate(0, 0, -((int(week.Weekday()) + 6) % 7))
+				for i := 0; i < 7; i++ {
+					day := monday.AddDate(0, 0, i)
+					if rule.ByDay[day.Weekday()] && !day.Before(e.Start) {
+						candidates = append(candidates, day)
+					}
+				}
+			}
+		}
+
+		for _, start := range candidates {
+			count++
+			if (rule.Count > 0 && count > rule.Count) || (!rule.Until.IsZero() && !start.Before(rule.Until)) || !start.Before(to) {
+				return starts
+			}
+			if !excluded[start.Unix()] && overlaps(start) {
+				starts = append(starts, start)
+			}
+		}
+	}
+	return starts
+}
+
+// iCalendar methods used for match invites
+const (
+	icsMethodRequest = "REQUEST"
+	icsMethodCancel  = "CANCEL"
+)
+
+// icsOrganizer is the address invites are sent from
+const icsOrganizer = "noreply@notacv.com"
+
+// matchInviteUID is the stable UID of a match's calendar event, so that updates and
+// cancellations replace the original invite
+func matchInviteUID(match *models.Match) string {
+	return fmt.Sprintf("match-%d@virtual-cuppa", match.ID)
+}
+
+// buildMatchInvite renders the match as an RFC 5545 calendar with a single event. REQUEST
+// invites list the active participants; CANCEL withdraws the event for everyone.
+func buildMatchInvite(match *models.Match, method string, now time.Time) []byte {
+	var b icsBuilder
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:-//Virtual Cuppa//Matches//EN")
+	b.line("CALSCALE:GREGORIAN")
+	b.line("METHOD:" + method)
+	writeMatchEvent(&b, match, method == icsMethodCancel, nil, now)
+	b.line("END:VCALENDAR")
+	return b.bytes()
+}
+
+// writeMatchEvent writes the VEVENT of a match. Times are written in UTC, or on the clock of
+// loc when the calendar carries a VTIMEZONE for it.
+func writeMatchEvent(b *icsBuilder, match *models.Match, cancelled bool, loc *time.Location, now time.Time) {
+	b.line("BEGIN:VEVENT")
+	b.line("UID:" + matchInviteUID(match))
+	b.line("SEQUENCE:" + strconv.Itoa(match.CalendarSequence))
+	b.line("DTSTAMP:" + formatICSTime(now))
+	b.line("DTSTART" + formatICSDateTime(*match.ScheduledStart, loc))
+	b.line("DTEND" + formatICSDateTime(*match.ScheduledEnd, loc))
+	b.line("SUMMARY:" + escapeICSText("Virtual Cuppa"))
+
+	var names []string
+	for _, p := range match.ActiveParticipants() {
+		if p.User != nil {
+			names = append(names, fullName(p.User))
+		}
+	}
+	b.line("DESCRIPTION:" + escapeICSText("Virtual cuppa with "+strings.Join(names, ", ")))
+	b.line("ORGANIZER;CN=Virtual Cuppa:mailto:" + icsOrganizer)
+
+	participants := match.ActiveParticipants()
+	if cancelled {
+		participants = match.Participants
+	}
+	for _, p := range participants {
+		if p.User == nil {
+			continue
+		}
+		partStat := "NEEDS-ACTION"
+		if p.Status == models.ParticipantStatusAccepted {
+			partStat = "ACCEPTED"
+		}
+		b.line(fmt.Sprintf("ATTENDEE;CN=%s;ROLE=REQ-PARTICIPANT;PARTSTAT=%s:mailto:%s",
+			quoteICSParam(fullName(p.User)), partStat, p.User.Email))
+	}
+
+	switch {
+	case cancelled:
+		b.line("STATUS:CANCELLED")
+	case match.Status == models.MatchStatusPending:
+		b.line("STATUS:TENTATIVE")
+	default:
+		b.line("STATUS:CONFIRMED")
+	}
+	b.line("END:VEVENT")
+}
+
+// icsBuilder writes content lines with CRLF endings, folded at 75 octets
+type icsBuilder struct {
+	buf bytes.Buffer
+}
+
+func (b *icsBuilder) line(content string) {
+	// Continuation lines start with a space, leaving room for 74 octets of content
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		// Never split a UTF-8 sequence
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.buf.WriteString(content[:cut])
+		b.buf.WriteString("\r\n ")
+		content = content[cut:]
+		limit = 74
+	}
+	b.buf.WriteString(content)
+	b.buf.WriteString("\r\n")
+}
+
+func (b *icsBuilder) bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// formatICSTime formats a UTC DATE-TIME value
+func formatICSTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// formatICSDateTime formats a DATE-TIME property value including its leading separator:
+// ":20260302T140000Z" in UTC or ";TZID=Europe/London:20260302T140000" on the clock of loc
+func formatICSDateTime(t time.Time, loc *time.Location) string {
+	if loc == nil || loc == time.UTC {
+		return ":" + formatICSTime(t)
+	}
+	return ";TZID=" + loc.String() + ":" + t.In(loc).Format("20060102T150405")
+}
+
+// writeVTimezone describes loc's UTC offsets between from and to, listing every offset change
+// as its own observance. Nothing is written for UTC, whose times need no VTIMEZONE.
+func writeVTimezone(b *icsBuilder, loc *time.Location, from, to time.Time) {
+	if loc == nil || loc == time.UTC {
+		return
+	}
+
+	b.line("BEGIN:VTIMEZONE")
+	b.line("TZID:" + loc.String())
+	name, offset := from.In(loc).Zone()
+	writeObservance(b, from.In(loc).IsDST(), from, offset, offset, name)
+	for _, change := range zoneTransitions(loc, from, to) {
+		name, next := change.In(loc).Zone()
+		writeObservance(b, change.In(loc).IsDST(), change, offset, next, name)
+		offset = next
+	}
+	b.line("END:VTIMEZONE")
+}
+
+// writeObservance writes a STANDARD or DAYLIGHT observance starting at the given instant,
+// whose DTSTART is the local time before the change as RFC 5545 requires
+func writeObservance(b *icsBuilder, daylight bool, start time.Time, offsetFrom, offsetTo int, name string) {
+	kind := "STANDARD"
+	if daylight {
+		kind = "DAYLIGHT"
+	}
+	b.line("BEGIN:" + kind)
+	b.line("DTSTART:" + start.In(time.FixedZone("", offsetFrom)).Format("20060102T150405"))
+	b.line("TZOFFSETFROM:" + formatICSOffset(offsetFrom))
+	b.line("TZOFFSETTO:" + formatICSOffset(offsetTo))
+	b.line("TZNAME:" + name)
+	b.line("END:" + kind)
+}
+
+// zoneTransitions returns the instants in [from, to) at which loc's UTC offset changes
+func zoneTransitions(loc *time.Location, from, to time.Time) []time.Time {
+	offsetAt := func(t time.Time) int {
+		_, offset := t.In(loc).Zone()
+		return offset
+	}
+
+	var transitions []time.Time
+	current := offsetAt(from)
+	for day := from; day.Before(to); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		if offsetAt(next) == current {
+			continue
+		}
+		// Narrow the change down to the second
+		lo, hi := day, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if offsetAt(mid) == current {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		transitions = append(transitions, hi.Truncate(time.Second))
+		current = offsetAt(hi)
+	}
+	return transitions
+}
+
+// formatICSOffset formats a UTC offset in seconds as "+0100" or "-0430"
+func formatICSOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	if offset%60 != 0 {
+		return fmt.Sprintf("%s%02d%02d%02d", sign, offset/3600, offset%3600/60, offset%60)
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+}
+
+func escapeICSText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
+
+// quoteICSParam quotes a parameter value that contains separators
+func quoteICSParam(value string) string {
+	value = strings.ReplaceAll(value, `"`, "'")
+	if strings.ContainsAny(value, ";:,") {
+		return `"` + value + `"`
+	}
+	return value
+}
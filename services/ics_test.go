@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// icsCalendar wraps VEVENT lines in a calendar with CRLF line endings
+func icsCalendar(lines ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+}
+
+func TestParseICS(t *testing.T) {
+	london, _ := time.LoadLocation("Europe/London")
+	tests := []struct {
+		name  string
+		lines []string
+		want  icsEvent
+	}{
+		{
+			"UTC times",
+			[]string{"BEGIN:VEVENT", "UID:a", "DTSTART:20260302T090000Z", "DTEND:20260302T100000Z", "END:VEVENT"},
+			icsEvent{UID: "a", Start: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			"IANA TZID",
+			[]string{"BEGIN:VEVENT", "DTSTART;TZID=Europe/London:20260702T090000", "DTEND;TZID=Europe/London:20260702T093000", "END:VEVENT"},
+			icsEvent{Start: time.Date(2026, 7, 2, 9, 0, 0, 0, london), End: time.Date(2026, 7, 2, 9, 30, 0, 0, london)},
+		},
+		{
+			"Windows TZID is floating",
+			[]string{
+				"BEGIN:VTIMEZONE", "TZID:W. Europe Standard Time", "BEGIN:STANDARD", "DTSTART:16010101T030000",
+				"TZOFFSETFROM:+0200", "TZOFFSETTO:+0100", "END:STANDARD", "END:VTIMEZONE",
+				"BEGIN:VEVENT", `DTSTART;TZID="W. Europe Standard Time":20260302T090000`,
+				`DTEND;TZID="W. Europe Standard Time":20260302T100000`, "END:VEVENT",
+			},
+			icsEvent{Start: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), Floating: true},
+		},
+		{
+			"floating without TZID",
+			[]string{"BEGIN:VEVENT", "DTSTART:20260302T090000", "DURATION:PT1H30M", "END:VEVENT"},
+			icsEvent{Start: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC), Floating: true},
+		},
+		{
+			"all-day without DTEND",
+			[]string{"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20261225", "SUMMARY:Christmas\\, Day", "END:VEVENT"},
+			icsEvent{Summary: "Christmas, Day", Start: time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 12, 26, 0, 0, 0, 0, time.UTC), AllDay: true},
+		},
+		{
+			"folded summary",
+			[]string{"BEGIN:VEVENT", "DTSTART:20260302T090000Z", "DTEND:20260302T100000Z", "SUMMARY:Team", "  stand-up", "END:VEVENT"},
+			icsEvent{Summary: "Team stand-up", Start: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			"transparent",
+			[]string{"BEGIN:VEVENT", "DTSTART:20260302T090000Z", "DTEND:20260302T100000Z", "TRANSP:TRANSPARENT", "END:VEVENT"},
+			icsEvent{Start: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), Free: true},
+		},
+		{
+			"cancelled",
+			[]string{"BEGIN:VEVENT", "DTSTART:20260302T090000Z", "DTEND:20260302T100000Z", "STATUS:CANCELLED", "END:VEVENT"},
+			icsEvent{Start: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), Free: true},
+		},
+		{
+			"alarm properties are ignored",
+			[]string{
+				"BEGIN:VEVENT", "DTSTART:20260302T090000Z", "DTEND:20260302T100000Z",
+				"BEGIN:VALARM", "SUMMARY:Reminder", "STATUS:CANCELLED", "END:VALARM", "SUMMARY:Meeting", "END:VEVENT",
+			},
+			icsEvent{Summary: "Meeting", Start: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			"recurrence",
+			[]string{
+				"BEGIN:VEVENT", "UID:r", "DTSTART:20260302T090000Z", "DTEND:20260302T100000Z", "RRULE:FREQ=WEEKLY;BYDAY=MO",
+				"EXDATE:20260309T090000Z,20260316T090000Z", "END:VEVENT",
+			},
+			icsEvent{
+				UID: "r", Start: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+				RRule:   "FREQ=WEEKLY;BYDAY=MO",
+				ExDates: []time.Time{time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC), time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := parseICS(strings.NewReader(icsCalendar(tt.lines...)))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("events = %d, want 1", len(events))
+			}
+			got := events[0]
+			if got.UID != tt.want.UID || got.Summary != tt.want.Summary || got.RRule != tt.want.RRule {
+				t.Errorf("event = %+v, want %+v", got, tt.want)
+			}
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) || got.Start.Location().String() != tt.want.Start.Location().String() {
+				t.Errorf("times = %v to %v, want %v to %v", got.Start, got.End, tt.want.Start, tt.want.End)
+			}
+			if got.AllDay != tt.want.AllDay || got.Floating != tt.want.Floating || got.Free != tt.want.Free {
+				t.Errorf("all-day %v, floating %v, free %v; want %v, %v, %v",
+					got.AllDay, got.Floating, got.Free, tt.want.AllDay, tt.want.Floating, tt.want.Free)
+			}
+			if len(got.ExDates) != len(tt.want.ExDates) {
+				t.Fatalf("exdates = %v, want %v", got.ExDates, tt.want.ExDates)
+			}
+			for i := range got.ExDates {
+				if !got.ExDates[i].Equal(tt.want.ExDates[i]) {
+					t.Errorf("exdate %d = %v, want %v", i, got.ExDates[i], tt.want.ExDates[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseICSRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		ics  string
+	}{
+		{"no calendar", "BEGIN:VEVENT\r\nDTSTART:20260302T090000Z\r\nEND:VEVENT\r\n"},
+		{"unterminated event", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20260302T090000Z\r\nEND:VCALENDAR\r\n"},
+		{"no start", icsCalendar("BEGIN:VEVENT", "SUMMARY:Meeting", "END:VEVENT")},
+		{"malformed start", icsCalendar("BEGIN:VEVENT", "DTSTART:2026-03-02 09:00", "END:VEVENT")},
+		{"malformed duration", icsCalendar("BEGIN:VEVENT", "DTSTART:20260302T090000Z", "DURATION:1H", "END:VEVENT")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseICS(strings.NewReader(tt.ics)); !errors.Is(err, ErrInvalidICS) {
+				t.Errorf("got %v, want %v", err, ErrInvalidICS)
+			}
+		})
+	}
+}
+
+// TestBusyIntervalsReadsFloatingTimesOnUserClock checks an Outlook event in a Windows time
+// zone blocks its wall clock time on the user's clock
+func TestBusyIntervalsReadsFloatingTimesOnUserClock(t *testing.T) {
+	warsaw, _ := time.LoadLocation("Europe/Warsaw")
+	events, err := parseICS(strings.NewReader(icsCalendar(
+		"BEGIN:VEVENT", "UID:w", `DTSTART;TZID="W. Europe Standard Time":20260302T090000`,
+		`DTEND;TZID="W. Europe Standard Time":20260302T100000`, "RRULE:FREQ=DAILY;COUNT=3",
+		`EXDATE;TZID="W. Europe Standard Time":20260303T090000`, "END:VEVENT",
+	)))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, warsaw)
+	busy, busyEvents := busyIntervals(events, warsaw, from, from.AddDate(0, 0, 7))
+	want := []time.Time{time.Date(2026, 3, 2, 9, 0, 0, 0, warsaw), time.Date(2026, 3, 4, 9, 0, 0, 0, warsaw)}
+	if busyEvents != 1 || len(busy) != len(want) {
+		t.Fatalf("busy = %v from %d events, want %v from 1", busy, busyEvents, want)
+	}
+	for i, start := range want {
+		if !busy[i].Start.Equal(start) || !busy[i].End.Equal(start.Add(time.Hour)) {
+			t.Errorf("busy[%d] = %v, want an hour from %v", i, busy[i], start)
+		}
+	}
+}
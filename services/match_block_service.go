@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+var (
+	ErrCannotBlockSelf     = errors.New("cannot block yourself")
+	ErrBlockTargetNotFound = errors.New("user not found in your organisation")
+	ErrBlockNotFound       = errors.New("block not found")
+)
+
+// MatchBlockService manages pairs of users who must never be matched. Personal blocks are
+// private: only their creator can list them, and they are never shown to admins.
+type MatchBlockService interface {
+	BlockUser(userID uint, blockedUserID uint) (*models.MatchBlock, error)
+	UnblockUser(userID uint, blockedUserID uint) error
+	GetBlockedUsers(userID uint) ([]*models.MatchBlock, error)
+	AddForbiddenPair(adminID uint, organisationID uint, user1ID uint, user2ID uint) (*models.MatchBlock, error)
+	RemoveForbiddenPair(organisationID uint, blockID uint) error
+	GetForbiddenPairs(organisationID uint) ([]*models.MatchBlock, error)
+}
+
+type matchBlockService struct {
+	blockRepo repositories.MatchBlockRepository
+	userRepo  repositories.UserRepository
+}
+
+func NewMatchBlockService(blockRepo repositories.MatchBlockRepository, userRepo repositories.UserRepository) MatchBlockService {
+	return &matchBlockService{
+		blockRepo: blockRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (s *matchBlockService) BlockUser(userID uint, blockedUserID uint) (*models.MatchBlock, error) {
+	if userID == blockedUserID {
+		return nil, ErrCannotBlockSelf
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.OrganisationID == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.requireMember(*user.OrganisationID, blockedUserID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.blockRepo.FindOne(userID, blockedUserID, models.MatchBlockSourceUser)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	block := &models.MatchBlock{
+		OrganisationID: *user.OrganisationID,
+		UserID:         userID,
+		BlockedUserID:  blockedUserID,
+		Source:         models.MatchBlockSourceUser,
+		CreatedByID:    userID,
+	}
+	if err := s.blockRepo.Create(block); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+func (s *matchBlockService) UnblockUser(userID uint, blockedUserID uint) error {
+	block, err := s.blockRepo.FindOne(userID, blockedUserID, models.MatchBlockSourceUser)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		return ErrBlockNotFound
+	}
+	return s.blockRepo.Delete(block.ID)
+}
+
+func (s *matchBlockService) GetBlockedUsers(userID uint) ([]*models.MatchBlock, error) {
+	return s.blockRepo.FindByUser(userID, models.MatchBlockSourceUser)
+}
+
+func (s *matchBlockService) AddForbiddenPair(adminID uint, organisationID uint, user1ID uint, user2ID uint) (*models.MatchBlock, error) {
+	if user1ID == user2ID {
+		return nil, ErrCannotBlockSelf
+	}
+	if err := s.requireMember(organisationID, user1ID); err != nil {
+		return nil, err
+	}
+	if err := s.requireMember(organisationID, user2ID); err != nil {
+		return nil, err
+	}
+
+	// Store forbidden pairs in a canonical order so each pair exists once
+	if user1ID > user2ID {
+		user1ID, user2ID = user2ID, user1ID
+	}
+
+	existing, err := s.blockRepo.FindOne(user1ID, user2ID, models.MatchBlockSourceAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	block := &models.MatchBlock{
+		OrganisationID: organisationID,
+		UserID:         user1ID,
+		BlockedUserID:  user2ID,
+		Source:         models.MatchBlockSourceAdmin,
+		CreatedByID:    adminID,
+	}
+	if err := s.blockRepo.Create(block); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+func (s *matchBlockService) RemoveForbiddenPair(organisationID uint, blockID uint) error {
+	block, err := s.blockRepo.FindByID(blockID)
+	if err != nil {
+		return err
+	}
+	// Personal blocks are not visible to admins, so treat them as missing
+	if block == nil || block.OrganisationID != organisationID || block.Source != models.MatchBlockSourceAdmin {
+		return ErrBlockNotFound
+	}
+	return s.blockRepo.Delete(block.ID)
+}
+
+func (s *matchBlockService) GetForbiddenPairs(organisationID uint) ([]*models.MatchBlock, error) {
+	return s.blockRepo.FindByOrganisation(organisationID, models.MatchBlockSourceAdmin)
+}
+
+// requireMember checks that the user exists and belongs to the organisation
+func (s *matchBlockService) requireMember(organisationID uint, userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.OrganisationID == nil || *user.OrganisationID != organisationID {
+		return ErrBlockTargetNotFound
+	}
+	return nil
+}
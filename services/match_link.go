@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/utils"
+)
+
+var (
+	ErrInvalidMatchLink = errors.New("invalid or expired match link")
+	ErrMatchClosed      = errors.New("match is no longer open")
+	ErrMatchLinkUsed    = errors.New("match was already answered")
+)
+
+// MatchAction is what a signed match link does
+type MatchAction string
+
+const (
+	MatchActionAccept MatchAction = "accept"
+	MatchActionReject MatchAction = "reject"
+)
+
+// matchLinkTTL is how long the links in new match emails work
+const matchLinkTTL = 14 * 24 * time.Hour
+
+// matchActionPath is where match links are served, relative to API_URL
+const matchActionPath = "/api/match-actions/"
+
+// matchLink returns the signed link that takes action on the match for the user
+func (s *matchService) matchLink(userID uint, matchID uint, action MatchAction) (string, error) {
+	return buildMatchLink(s.apiURL, userID, matchID, action)
+}
+
+// buildMatchLink signs a link served under apiURL. Reminders reuse it so their links behave
+// exactly like those of the new match email.
+func buildMatchLink(apiURL string, userID uint, matchID uint, action MatchAction) (string, error) {
+	token, err := utils.GenerateMatchActionToken(userID, matchID, string(action), matchLinkTTL)
+	if err != nil {
+		return "", err
+	}
+	return apiURL + matchActionPath + token, nil
+}
+
+// CheckMatchLink reports what a link would do, without doing it
+func (s *matchService) CheckMatchLink(token string) (MatchAction, error) {
+	claims, err := s.openMatchLink(token)
+	if err != nil {
+		return "", err
+	}
+	return MatchAction(claims.Action), nil
+}
+
+// ActOnMatchLink accepts or rejects the match of a link exactly as AcceptMatch and
+// RejectMatch do for a logged-in user
+func (s *matchService) ActOnMatchLink(token string) (MatchAction, error) {
+	claims, err := s.openMatchLink(token)
+	if err != nil {
+		return "", err
+	}
+
+	action := MatchAction(claims.Action)
+	if action == MatchActionAccept {
+		err = s.AcceptMatch(claims.UserID, claims.MatchID)
+	} else {
+		err = s.RejectMatch(claims.UserID, claims.MatchID, "")
+	}
+	return action, err
+}
+
+// openMatchLink verifies the link and that its match can still be answered. Links outlive
+// their match, so matches that have expired, been rejected or completed are refused, and
+// each link works only until its user has answered, so a forwarded or replayed link can't
+// change the answer.
+func (s *matchService) openMatchLink(token string) (*utils.MatchActionClaims, error) {
+	claims, err := utils.ValidateMatchActionToken(token)
+	if err != nil {
+		return nil, ErrInvalidMatchLink
+	}
+	if action := MatchAction(claims.Action); action != MatchActionAccept && action != MatchActionReject {
+		return nil, ErrInvalidMatchLink
+	}
+
+	match, err := s.matchRepo.FindByID(claims.MatchID)
+	if err != nil {
+		return nil, ErrMatchNotFound
+	}
+	if !match.IsActiveParticipant(claims.UserID) {
+		return nil, ErrUnauthorizedMatch
+	}
+	switch match.Status {
+	case models.MatchStatusRejected, models.MatchStatusExpired, models.MatchStatusCompleted:
+		return nil, ErrMatchClosed
+	}
+	if match.Participant(claims.UserID).Status != models.ParticipantStatusPending {
+		return nil, ErrMatchLinkUsed
+	}
+	return claims, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+	"virtual-cuppa-be/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// memLinkMatchRepo serves the one match match links are opened against
+type memLinkMatchRepo struct {
+	repositories.MatchRepository
+	match *models.Match
+}
+
+func (r *memLinkMatchRepo) FindByID(id uint) (*models.Match, error) {
+	if r.match == nil || r.match.ID != id {
+		return nil, errors.New("record not found")
+	}
+	return r.match, nil
+}
+
+// newLinkService returns a match service over a pending match between users 1 and 2
+func newLinkService() (*matchService, *models.Match) {
+	match := &models.Match{
+		ID:      7,
+		User1ID: 1,
+		User2ID: 2,
+		Status:  models.MatchStatusPending,
+		Participants: []*models.MatchParticipant{
+			{MatchID: 7, UserID: 1, Status: models.ParticipantStatusPending},
+			{MatchID: 7, UserID: 2, Status: models.ParticipantStatusPending},
+		},
+	}
+	return &matchService{matchRepo: &memLinkMatchRepo{match: match}}, match
+}
+
+func matchLinkToken(t *testing.T, userID uint, action MatchAction, ttl time.Duration) string {
+	t.Helper()
+	token, err := utils.GenerateMatchActionToken(userID, 7, string(action), ttl)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	return token
+}
+
+func TestCheckMatchLink(t *testing.T) {
+	s, _ := newLinkService()
+	for _, action := range []MatchAction{MatchActionAccept, MatchActionReject} {
+		got, err := s.CheckMatchLink(matchLinkToken(t, 1, action, time.Hour))
+		if err != nil || got != action {
+			t.Errorf("%s link = %q, %v; want %q", action, got, err, action)
+		}
+	}
+}
+
+func TestCheckMatchLinkRefusesExpiredToken(t *testing.T) {
+	s, _ := newLinkService()
+	_, err := s.CheckMatchLink(matchLinkToken(t, 1, MatchActionAccept, -time.Minute))
+	if !errors.Is(err, ErrInvalidMatchLink) {
+		t.Errorf("expired link: got %v, want %v", err, ErrInvalidMatchLink)
+	}
+}
+
+func TestCheckMatchLinkRefusesWrongSecret(t *testing.T) {
+	s, _ := newLinkService()
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, utils.MatchActionClaims{
+		UserID:  1,
+		MatchID: 7,
+		Action:  string(MatchActionAccept),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte("not-the-link-secret"))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	if _, err := s.CheckMatchLink(forged); !errors.Is(err, ErrInvalidMatchLink) {
+		t.Errorf("link signed with another secret: got %v, want %v", err, ErrInvalidMatchLink)
+	}
+
+	// Login tokens are signed with a different secret, so they can't be used as links
+	login, err := utils.GenerateToken(1, "ada@example.com", string(models.AccountTypeUser), nil)
+	if err != nil {
+		t.Fatalf("generate login token: %v", err)
+	}
+	if _, err := s.CheckMatchLink(login); !errors.Is(err, ErrInvalidMatchLink) {
+		t.Errorf("login token as a link: got %v, want %v", err, ErrInvalidMatchLink)
+	}
+}
+
+func TestCheckMatchLinkRefusesReplay(t *testing.T) {
+	s, match := newLinkService()
+	accept := matchLinkToken(t, 1, MatchActionAccept, time.Hour)
+	reject := matchLinkToken(t, 1, MatchActionReject, time.Hour)
+
+	// Once the user has accepted, neither of their links works again
+	now := time.Now()
+	match.Participant(1).Status = models.ParticipantStatusAccepted
+	match.Participant(1).AcceptedAt = &now
+	for _, token := range []string{accept, reject} {
+		if _, err := s.CheckMatchLink(token); !errors.Is(err, ErrMatchLinkUsed) {
+			t.Errorf("replayed link: got %v, want %v", err, ErrMatchLinkUsed)
+		}
+	}
+
+	// The partner's links still work until they answer
+	if _, err := s.CheckMatchLink(matchLinkToken(t, 2, MatchActionAccept, time.Hour)); err != nil {
+		t.Errorf("partner's link: %v", err)
+	}
+
+	// A user who declined has left the match
+	match.Participant(1).Status = models.ParticipantStatusRejected
+	if _, err := s.CheckMatchLink(accept); !errors.Is(err, ErrUnauthorizedMatch) {
+		t.Errorf("link after declining: got %v, want %v", err, ErrUnauthorizedMatch)
+	}
+}
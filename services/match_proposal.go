@@ -0,0 +1,166 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/utils"
+)
+
+// matchProposalTTL is how long an admin has to commit a previewed proposal
+const matchProposalTTL = 30 * time.Minute
+
+// proposalCommitMu serialises commits so a proposal committed twice at once is only created
+// once. Proposals live in this process's memory, so previews and commits must reach the same
+// instance, and a restart drops every open proposal.
+var proposalCommitMu sync.Mutex
+
+var (
+	ErrProposalNotFound = errors.New("match proposal not found or expired")
+	ErrProposalOutdated = errors.New("match proposal is outdated, please preview again")
+)
+
+// ProposedMatch is one cuppa in a match proposal
+type ProposedMatch struct {
+	UserIDs        []uint             `json:"userIds"`
+	Score          float64            `json:"score"`
+	ScoreBreakdown map[string]float64 `json:"scoreBreakdown"`
+	Repeat         bool               `json:"repeat"`
+}
+
+// MatchProposal is the result of a dry run of match generation. Nothing is persisted
+// until the proposal is committed.
+type MatchProposal struct {
+	ID             string          `json:"id"`
+	OrganisationID uint            `json:"organisationId"`
+	Matches        []ProposedMatch `json:"matches"`
+	EligibleUsers  int             `json:"eligibleUsers"`
+	// Excluded lists every user who would not get a match, with the reason
+	Excluded  []UnmatchedUser `json:"excluded"`
+	CreatedAt time.Time       `json:"createdAt"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+}
+
+// PreviewMatchesForOrganisation runs the full match generation pipeline without creating
+// matches or history and stores the outcome as a proposal that can be committed later
+func (s *matchService) PreviewMatchesForOrganisation(organisationID uint) (*MatchProposal, error) {
+	pool, err := s.loadCandidatePool(organisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := s.planMatches(pool)
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, err
+	}
+	id := hex.EncodeToString(idBytes)
+
+	now := time.Now()
+	proposal := &MatchProposal{
+		ID:             id,
+		OrganisationID: organisationID,
+		Matches:        []ProposedMatch{},
+		EligibleUsers:  plan.eligibleUsers,
+		Excluded:       append(append([]UnmatchedUser{}, plan.excluded...), plan.unmatched...),
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(matchProposalTTL),
+	}
+	for _, group := range plan.groups {
+		proposal.Matches = append(proposal.Matches, ProposedMatch{
+			UserIDs:        group.userIDs,
+			Score:          group.score,
+			ScoreBreakdown: group.breakdown,
+			Repeat:         group.repeat,
+		})
+	}
+
+	data, err := json.Marshal(proposal)
+	if err != nil {
+		return nil, err
+	}
+	utils.GetMatchProposalCache().Set(proposalCacheKey(organisationID, id), string(data), matchProposalTTL)
+
+	return proposal, nil
+}
+
+// CommitMatchProposal creates exactly the matches of a previewed proposal. The proposal is
+// rejected if any of its users has since become ineligible or a pair is no longer allowed.
+// It is only used up once its matches are created, so a rejected commit keeps the preview.
+func (s *matchService) CommitMatchProposal(organisationID uint, proposalID string) (*MatchRunResult, error) {
+	proposalCommitMu.Lock()
+	defer proposalCommitMu.Unlock()
+
+	cache := utils.GetMatchProposalCache()
+	key := proposalCacheKey(organisationID, proposalID)
+
+	data, ok := cache.Get(key)
+	if !ok {
+		return nil, ErrProposalNotFound
+	}
+
+	var proposal MatchProposal
+	if err := json.Unmarshal([]byte(data), &proposal); err != nil {
+		return nil, err
+	}
+
+	pool, err := s.loadCandidatePool(organisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	eligible := make(map[uint]bool)
+	for _, user := range pool.users {
+		if pool.isEligible(user) {
+			eligible[user.ID] = true
+		}
+	}
+
+	plan := &matchPlan{eligibleUsers: proposal.EligibleUsers}
+	for _, proposed := range proposal.Matches {
+		if !pool.canMeet(proposed.UserIDs...) {
+			return nil, ErrProposalOutdated
+		}
+		for i, id := range proposed.UserIDs {
+			if !eligible[id] {
+				return nil, ErrProposalOutdated
+			}
+			for _, other := range proposed.UserIDs[i+1:] {
+				if allowed, _ := pool.checkPair(id, other); !allowed {
+					return nil, ErrProposalOutdated
+				}
+			}
+		}
+		plan.groups = append(plan.groups, matchGroup{
+			userIDs:   proposed.UserIDs,
+			score:     proposed.Score,
+			breakdown: proposed.ScoreBreakdown,
+			repeat:    proposed.Repeat,
+		})
+	}
+	for _, excluded := range proposal.Excluded {
+		if excluded.Reason == UnmatchedNoCompatiblePartner || excluded.Reason == UnmatchedPartnersTaken {
+			plan.unmatched = append(plan.unmatched, excluded)
+		}
+	}
+
+	run, err := s.startRun(organisationID, models.MatchRunTriggerProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	// A proposal can only be committed once
+	result := s.createPlannedMatches(pool, plan, run)
+	cache.Delete(key)
+	return result, nil
+}
+
+func proposalCacheKey(organisationID uint, proposalID string) string {
+	return fmt.Sprintf("%d:%s", organisationID, proposalID)
+}
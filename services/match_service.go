@@ -3,8 +3,9 @@ package services
 import (
 	"errors"
 	"fmt"
-	"math/rand"
-	"sort"
+	"math"
+	"os"
+	"strings"
 	"time"
 	"virtual-cuppa-be/models"
 	"virtual-cuppa-be/repositories"
@@ -17,6 +18,7 @@ var (
 	ErrFeedbackAlreadyExists = errors.New("feedback already submitted for this match")
 	ErrMatchNotAccepted      = errors.New("can only provide feedback for accepted matches")
 	ErrInvalidRating         = errors.New("rating must be between 1 and 5")
+	ErrMatchNotScheduled     = errors.New("match has no scheduled time")
 )
 
 // AvailabilitySlot represents a single availability time slot for email templates
@@ -26,18 +28,26 @@ type AvailabilitySlot struct {
 }
 
 type MatchService interface {
-	GenerateMatchesForOrganisation(organisationID uint) (int, error)
+	GenerateMatchesForOrganisation(organisationID uint) (*MatchRunResult, error)
+	PreviewMatchesForOrganisation(organisationID uint) (*MatchProposal, error)
+	CommitMatchProposal(organisationID uint, proposalID string) (*MatchRunResult, error)
 	TryGenerateMatchForUser(userID uint) error
 	GetCurrentMatch(userID uint) (*models.Match, error)
 	GetMatchHistory(userID uint) ([]*models.Match, error)
+	GetMatchCalendar(userID uint, matchID uint) ([]byte, error)
+	GetMatchingStatus(userID uint) (*models.MatchingStatus, error)
+	UpdateMatchPreferences(userID uint, input *models.UpdateMatchPreferencesInput) (*models.MatchingStatus, error)
 	AcceptMatch(userID uint, matchID uint) error
 	AcceptMatchWithAvailability(userID uint, matchID uint, availability models.Availability) (*models.Match, error)
-	RejectMatch(userID uint, matchID uint) error
+	RejectMatch(userID uint, matchID uint, reason models.RejectionReason) error
 	GetOrganisationMatches(organisationID uint) ([]*models.Match, error)
 	GetMatchAvailabilities(userID uint, matchID uint) ([]*models.MatchAvailability, error)
 	SubmitFeedback(userID uint, matchID uint, rating int, comment string) error
 	GetMatchFeedbacks(userID uint, matchID uint) ([]*models.MatchFeedback, error)
 	GetMatchesPendingFeedback(userID uint) ([]*models.Match, error)
+	ExpireStaleMatches(acceptanceWindow time.Duration) (int, error)
+	CheckMatchLink(token string) (MatchAction, error)
+	ActOnMatchLink(token string) (MatchAction, error)
 }
 
 type matchService struct {
@@ -46,7 +56,13 @@ type matchService struct {
 	matchFeedbackRepo repositories.MatchFeedbackRepository
 	userRepo          repositories.UserRepository
 	availConfigRepo   repositories.UserAvailabilityConfigRepository
-	emailSvc          EmailService
+	orgRepo           repositories.OrganisationRepository
+	blockRepo         repositories.MatchBlockRepository
+	calendarRepo      repositories.HolidayCalendarRepository
+	overrideRepo      repositories.AvailabilityOverrideRepository
+	uow               repositories.UnitOfWork
+	notifier          Notifier
+	apiURL            string
 }
 
 func NewMatchService(
@@ -55,7 +71,12 @@ func NewMatchService(
 	matchFeedbackRepo repositories.MatchFeedbackRepository,
 	userRepo repositories.UserRepository,
 	availConfigRepo repositories.UserAvailabilityConfigRepository,
-	emailSvc EmailService,
+	orgRepo repositories.OrganisationRepository,
+	blockRepo repositories.MatchBlockRepository,
+	calendarRepo repositories.HolidayCalendarRepository,
+	overrideRepo repositories.AvailabilityOverrideRepository,
+	uow repositories.UnitOfWork,
+	notifier Notifier,
 ) MatchService {
 	return &matchService{
 		matchRepo:         matchRepo,
@@ -63,94 +84,170 @@ func NewMatchService(
 		matchFeedbackRepo: matchFeedbackRepo,
 		userRepo:          userRepo,
 		availConfigRepo:   availConfigRepo,
-		emailSvc:          emailSvc,
+		orgRepo:           orgRepo,
+		blockRepo:         blockRepo,
+		calendarRepo:      calendarRepo,
+		overrideRepo:      overrideRepo,
+		uow:               uow,
+		notifier:          notifier,
+		apiURL:            strings.TrimRight(os.Getenv("API_URL"), "/"),
 	}
 }
 
-// calculateMatchScore calculates compatibility score based on common tags
-func (s *matchService) calculateMatchScore(user1Tags, user2Tags []models.Tag) float64 {
-	if len(user1Tags) == 0 && len(user2Tags) == 0 {
-		return 0
-	}
+type userPair struct {
+	user1ID uint
+	user2ID uint
+	score   float64
+	// breakdown is the score of each scoring strategy before the rematch factor
+	breakdown map[string]float64
+	// repeat is set when the two users have been matched before
+	repeat bool
+}
+
+// UnmatchedReason explains why a user did not get a match in a run
+type UnmatchedReason string
+
+const (
+	// UnmatchedAdmin means the user is an admin; admins are never matched
+	UnmatchedAdmin UnmatchedReason = "admin"
+	// UnmatchedUnconfirmed means the user has not been confirmed yet
+	UnmatchedUnconfirmed UnmatchedReason = "unconfirmed"
+	// UnmatchedPaused means the user has paused matching
+	UnmatchedPaused UnmatchedReason = "paused"
+	// UnmatchedPendingMatch means the user is already in an open match
+	UnmatchedPendingMatch UnmatchedReason = "pending_match"
+	// UnmatchedCadence means the user's last cuppa is too recent for their match cadence
+	UnmatchedCadence UnmatchedReason = "cadence"
+	// UnmatchedNoAvailabilityConfig means the user has not set up availability
+	UnmatchedNoAvailabilityConfig UnmatchedReason = "no_availability_config"
+	// UnmatchedNoFreeTime means the user has no free time left in the scheduling range,
+	// e.g. because they are on leave all week
+	UnmatchedNoFreeTime UnmatchedReason = "no_free_time"
+
+	// UnmatchedNoCompatiblePartner means no other eligible user could be paired with them
+	UnmatchedNoCompatiblePartner UnmatchedReason = "no_compatible_partner"
+	// UnmatchedPartnersTaken means every compatible partner was needed for another pair
+	UnmatchedPartnersTaken UnmatchedReason = "partners_taken"
+	// UnmatchedCreateFailed means a pair was selected but the match could not be saved
+	UnmatchedCreateFailed UnmatchedReason = "create_failed"
+)
+
+// UnmatchedUser is a user left without a match after a run
+type UnmatchedUser struct {
+	UserID uint            `json:"userId"`
+	Reason UnmatchedReason `json:"reason"`
+}
+
+// MatchRunResult summarises a single match generation run for an organisation
+type MatchRunResult struct {
+	MatchesCreated int             `json:"matchesCreated"`
+	EligibleUsers  int             `json:"eligibleUsers"`
+	Unmatched      []UnmatchedUser `json:"unmatched"`
+}
 
-	tagMap := make(map[string]bool)
-	for _, tag := range user1Tags {
-		tagMap[tag.Name] = true
+// feedbackWindowDays is how long an accepted match stays open for feedback
+const feedbackWindowDays = 5
+
+// rejectionExclusionDays is how long a pair stays apart after one of them rejected the
+// match because they don't want to meet the other
+const rejectionExclusionDays = 180
+
+// rematchScoreFactor is applied to the score of pairs who have met before
+const rematchScoreFactor = 0.5
+
+// matchScoreScale converts percentage scores to integer weights for the blossom algorithm
+const matchScoreScale = 100
+
+// selectOptimalPairs picks a maximum-cardinality set of disjoint pairs and, among
+// those, the one with the highest total score
+func selectOptimalPairs(userIDs []uint, pairs []userPair) []userPair {
+	index := make(map[uint]int, len(userIDs))
+	for i, id := range userIDs {
+		index[id] = i
 	}
 
-	commonTags := 0
-	for _, tag := range user2Tags {
-		if tagMap[tag.Name] {
-			commonTags++
+	edges := make([]weightedEdge, len(pairs))
+	for k, pair := range pairs {
+		edges[k] = weightedEdge{
+			u:      index[pair.user1ID],
+			v:      index[pair.user2ID],
+			weight: int64(math.Round(pair.score * matchScoreScale)),
 		}
 	}
 
-	totalUniqueTags := len(tagMap)
-	for _, tag := range user2Tags {
-		if !tagMap[tag.Name] {
-			totalUniqueTags++
+	mate := maxWeightMatching(edges, true)
+
+	var selected []userPair
+	for k, pair := range pairs {
+		u, v := edges[k].u, edges[k].v
+		if u < len(mate) && mate[u] == v {
+			selected = append(selected, pair)
 		}
 	}
+	return selected
+}
 
-	if totalUniqueTags == 0 {
-		return 0
+func (s *matchService) GenerateMatchesForOrganisation(organisationID uint) (*MatchRunResult, error) {
+	// Load users, open matches, availability and history in bulk; the rest runs in memory
+	pool, err := s.loadCandidatePool(organisationID)
+	if err != nil {
+		return nil, err
 	}
 
-	return float64(commonTags) / float64(totalUniqueTags) * 100
+	plan := s.planMatches(pool)
+	if plan.eligibleUsers < 2 {
+		return nil, ErrNoUsersToMatch
+	}
+
+	run, err := s.startRun(organisationID, models.MatchRunTriggerBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.createPlannedMatches(pool, plan, run)
+	if len(plan.groups) == 0 {
+		return result, ErrNoUsersToMatch
+	}
+
+	return result, nil
 }
 
-type userPair struct {
-	user1ID uint
-	user2ID uint
-	score   float64
+// matchPlan is the outcome of match selection before anything is persisted
+type matchPlan struct {
+	groups        []matchGroup
+	eligibleUsers int
+	// unmatched lists eligible users left without a group
+	unmatched []UnmatchedUser
+	// excluded lists users who were not eligible for this run
+	excluded []UnmatchedUser
 }
 
-func (s *matchService) GenerateMatchesForOrganisation(organisationID uint) (int, error) {
-	// Get all confirmed users in organisation
-	users, err := s.userRepo.FindByOrganisation(organisationID)
-	if err != nil {
-		return 0, err
-	}
+// planMatches selects the cuppas for a run entirely in memory
+func (s *matchService) planMatches(pool *candidatePool) *matchPlan {
+	org := pool.org
+	plan := &matchPlan{}
 
 	// Filter only confirmed users without pending matches (exclude Admins)
 	// Also check if users have availability configuration
 	var availableUsers []*models.User
-	var userConfigs = make(map[uint]*models.UserAvailabilityConfig)
-	
-	for _, user := range users {
-		// Skip admins - they should not be matched
-		if user.AccountType == models.AccountTypeAdmin {
-			continue
-		}
-		if !user.IsConfirmed {
+	for _, user := range pool.users {
+		if reason := pool.exclusionReason(user); reason != "" {
+			plan.excluded = append(plan.excluded, UnmatchedUser{UserID: user.ID, Reason: reason})
 			continue
 		}
-		hasPending, err := s.matchRepo.HasPendingMatch(user.ID)
-		if err != nil {
-			return 0, err
-		}
-		if hasPending {
-			continue
-		}
-		
-		// Check if user has availability configuration
-		config, err := s.availConfigRepo.FindByUserID(user.ID)
-		if err != nil {
-			return 0, err
-		}
-		if config == nil {
-			// User doesn't have availability config, skip
-			continue
-		}
-		
-		userConfigs[user.ID] = config
 		availableUsers = append(availableUsers, user)
 	}
+	plan.eligibleUsers = len(availableUsers)
 
 	if len(availableUsers) < 2 {
-		return 0, ErrNoUsersToMatch
+		for _, user := range availableUsers {
+			plan.unmatched = append(plan.unmatched, UnmatchedUser{UserID: user.ID, Reason: UnmatchedNoCompatiblePartner})
+		}
+		return plan
 	}
 
+	scorer := scorerFor(org)
+
 	// Calculate scores for all possible pairs
 	var pairs []userPair
 	for i := 0; i < len(availableUsers); i++ {
@@ -158,88 +255,221 @@ func (s *matchService) GenerateMatchesForOrganisation(organisationID uint) (int,
 			user1 := availableUsers[i]
 			user2 := availableUsers[j]
 
-			// Check if they were ever matched before
-			wasMatched, err := s.matchHistoryRepo.WasEverMatched(user1.ID, user2.ID)
-			if err != nil {
-				return 0, err
-			}
-			if wasMatched {
+			// Skip blocked pairs and apply the re-match policy to pairs who met before
+			allowed, repeat := pool.checkPair(user1.ID, user2.ID)
+			if !allowed {
 				continue
 			}
 			
-			// Check if users have common availability
-			config1 := userConfigs[user1.ID]
-			config2 := userConfigs[user2.ID]
-			if !models.HasCommonAvailability(config1, config2) {
+			// Check if users have a common slot long enough for a cuppa within working hours
+			if !pool.canMeet(user1.ID, user2.ID) {
 				// No common time slots, skip this pair
 				continue
 			}
 
-			score := s.calculateMatchScore(user1.Tags, user2.Tags)
+			score, breakdown := scorer.Breakdown(user1, user2)
 			pairs = append(pairs, userPair{
-				user1ID: user1.ID,
-				user2ID: user2.ID,
-				score:   score,
+				user1ID:   user1.ID,
+				user2ID:   user2.ID,
+				score:     score,
+				breakdown: breakdown,
+				repeat:    repeat,
 			})
 		}
 	}
+	pairs = applyRematchPolicy(org, pairs)
 
-	if len(pairs) == 0 {
-		return 0, ErrNoUsersToMatch
+	hasCandidate := make(map[uint]bool)
+	for _, pair := range pairs {
+		hasCandidate[pair.user1ID] = true
+		hasCandidate[pair.user2ID] = true
 	}
 
-	// Sort pairs by score (highest first)
-	sort.Slice(pairs, func(i, j int) bool {
-		return pairs[i].score > pairs[j].score
-	})
+	userIDs := make([]uint, len(availableUsers))
+	for i, user := range availableUsers {
+		userIDs[i] = user.ID
+	}
 
-	// Greedy algorithm: select best matches ensuring each user appears once
-	matchedUsers := make(map[uint]bool)
-	var matchesToCreate []userPair
+	groupSize := org.GroupSize()
 
-	for _, pair := range pairs {
-		if !matchedUsers[pair.user1ID] && !matchedUsers[pair.user2ID] {
-			matchesToCreate = append(matchesToCreate, pair)
-			matchedUsers[pair.user1ID] = true
-			matchedUsers[pair.user2ID] = true
+	if groupSize > 2 {
+		// Group cuppas: every member of a group must be compatible with every other member
+		plan.groups = formGroups(userIDs, pairs, groupSize, pool.groupFits())
+	} else {
+		// Maximum-cardinality, maximum-weight matching so that as many users as
+		// possible get a match, with total match score as the tie-breaker
+		for _, pair := range selectOptimalPairs(userIDs, pairs) {
+			plan.groups = append(plan.groups, matchGroup{
+				userIDs: []uint{pair.user1ID, pair.user2ID},
+				score:   pair.score,
+			})
 		}
 	}
 
-	if len(matchesToCreate) == 0 {
-		return 0, ErrNoUsersToMatch
+	index := pairIndex(pairs)
+	for i := range plan.groups {
+		plan.groups[i].describe(index)
 	}
 
-	// Create matches with random dates in next week
-	createdCount := 0
-	for _, pair := range matchesToCreate {
-		scheduledDate, scheduledTime := s.generateRandomDateTime()
+	grouped := make(map[uint]bool)
+	for _, group := range plan.groups {
+		for _, id := range group.userIDs {
+			grouped[id] = true
+		}
+	}
 
-		match := &models.Match{
-			OrganisationID: organisationID,
-			User1ID:        pair.user1ID,
-			User2ID:        pair.user2ID,
-			MatchScore:     pair.score,
-			Status:         models.MatchStatusPending,
-			ScheduledDate:  scheduledDate,
-			ScheduledTime:  scheduledTime,
+	for _, user := range availableUsers {
+		if grouped[user.ID] {
+			continue
+		}
+		reason := UnmatchedPartnersTaken
+		if !hasCandidate[user.ID] {
+			reason = UnmatchedNoCompatiblePartner
 		}
+		plan.unmatched = append(plan.unmatched, UnmatchedUser{UserID: user.ID, Reason: reason})
+	}
+
+	return plan
+}
+
+// startRun records the start of a match generation run
+func (s *matchService) startRun(organisationID uint, trigger string) (*models.MatchRun, error) {
+	run := &models.MatchRun{
+		OrganisationID: organisationID,
+		Trigger:        trigger,
+		StartedAt:      time.Now(),
+	}
+	if err := s.matchRepo.CreateRun(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// finishRun stores the outcome of a match generation run
+func (s *matchService) finishRun(run *models.MatchRun, result *MatchRunResult) {
+	finishedAt := time.Now()
+	run.EligibleUsers = result.EligibleUsers
+	run.MatchesCreated = result.MatchesCreated
+	run.UnmatchedCount = len(result.Unmatched)
+	run.FinishedAt = &finishedAt
+	if err := s.matchRepo.UpdateRun(run); err != nil {
+		fmt.Printf("ERROR: Failed to update match run %d: %v\n", run.ID, err)
+	}
+}
 
-		if err := s.matchRepo.Create(match); err != nil {
+// createPlannedMatches persists the groups of a plan as part of run and reports the outcome
+func (s *matchService) createPlannedMatches(pool *candidatePool, plan *matchPlan, run *models.MatchRun) *MatchRunResult {
+	result := &MatchRunResult{EligibleUsers: plan.eligibleUsers}
+
+	// Create matches, each scheduled in a slot all members are available for
+	for _, group := range plan.groups {
+		if _, err := s.createMatch(pool, group, &run.ID); err != nil {
+			for _, id := range group.userIDs {
+				result.Unmatched = append(result.Unmatched, UnmatchedUser{UserID: id, Reason: UnmatchedCreateFailed})
+			}
 			continue
 		}
 
-		// Add to history
-		history := &models.MatchHistory{
-			User1ID:   pair.user1ID,
-			User2ID:   pair.user2ID,
-			MatchedAt: time.Now(),
+		result.MatchesCreated++
+	}
+
+	result.Unmatched = append(result.Unmatched, plan.unmatched...)
+	s.finishRun(run, result)
+	return result
+}
+
+// applyRematchPolicy drops repeat pairs the policy does not allow yet and halves the score
+// of the remaining repeats. This only biases the selection towards first-time pairings: the
+// matching still maximises the number of users matched first, so a repeat is chosen whenever
+// it lets more users get a cuppa, and a high-scoring repeat can still beat a poor new pairing.
+func applyRematchPolicy(org *models.Organisation, pairs []userPair) []userPair {
+	// Count how many new colleagues each user could still meet in this candidate set
+	unmet := make(map[uint]int)
+	for _, pair := range pairs {
+		if !pair.repeat {
+			unmet[pair.user1ID]++
+			unmet[pair.user2ID]++
+		}
+	}
+
+	var result []userPair
+	for _, pair := range pairs {
+		if pair.repeat {
+			if org.RematchPolicy == models.RematchPolicyAfterEveryone && (unmet[pair.user1ID] > 0 || unmet[pair.user2ID] > 0) {
+				continue
+			}
+			pair.score *= rematchScoreFactor
+		}
+		result = append(result, pair)
+	}
+	return result
+}
+
+// createMatch persists a match for the group together with its participants, its
+// explanation and one history row per pair of members. The cuppa is scheduled in a
+// slot that every member is available for.
+func (s *matchService) createMatch(pool *candidatePool, group matchGroup, runID *uint) (*models.Match, error) {
+	org := pool.org
+	userIDs := group.userIDs
+	slot, err := s.pickMeetingSlot(org, pool.commonIntervals(userIDs), pool.now)
+	if err != nil {
+		return nil, err
+	}
+	scheduledDate := time.Date(slot.start.Year(), slot.start.Month(), slot.start.Day(), 0, 0, 0, 0, slot.start.Location())
+
+	participants := make([]*models.MatchParticipant, len(userIDs))
+	for i, id := range userIDs {
+		participants[i] = &models.MatchParticipant{
+			UserID: id,
+			Status: models.ParticipantStatusPending,
+		}
+	}
+
+	match := &models.Match{
+		OrganisationID: org.ID,
+		User1ID:        userIDs[0],
+		User2ID:        userIDs[1],
+		MatchScore:     group.score,
+		Status:         models.MatchStatusPending,
+		Participants:   participants,
+		ScheduledDate:  scheduledDate,
+		ScheduledTime:  slot.start.Format("15:04"),
+		ScheduledStart: &slot.start,
+		ScheduledEnd:   &slot.end,
+		RunID:          runID,
+		Explanation:    pool.explain(group, runID),
+	}
+
+	// Create the match and queue the new cuppa emails together
+	err = s.uow.Do(func(repos *repositories.TxRepositories) error {
+		if err := repos.Matches.Create(match); err != nil {
+			return err
+		}
+		notifications, err := s.matchCreatedNotifications(match, org, pool.usersByID)
+		if err != nil {
+			return err
 		}
-		s.matchHistoryRepo.Create(history)
+		return s.notifier.Enqueue(repos.Outbox, notifications...)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		createdCount++
+	// Add to history
+	now := time.Now()
+	for i := 0; i < len(userIDs); i++ {
+		for j := i + 1; j < len(userIDs); j++ {
+			history := &models.MatchHistory{
+				MatchID:   &match.ID,
+				User1ID:   userIDs[i],
+				User2ID:   userIDs[j],
+				MatchedAt: now,
+			}
+			s.matchHistoryRepo.Create(history)
+		}
 	}
 
-	return createdCount, nil
+	return match, nil
 }
 
 // TryGenerateMatchForUser tries to find and create a match for a specific user
@@ -247,141 +477,144 @@ func (s *matchService) GenerateMatchesForOrganisation(organisationID uint) (int,
 func (s *matchService) TryGenerateMatchForUser(userID uint) error {
 	// Get user and check if confirmed and not admin
 	user, err := s.userRepo.FindByID(userID)
-	if err != nil {
+	if err != nil || user == nil {
 		return nil // User not found, no error
 	}
 
-	if user.AccountType == models.AccountTypeAdmin || !user.IsConfirmed {
-		return nil // Admins and unconfirmed users don't get matched
-	}
-
 	// Check if user has organisation
 	if user.OrganisationID == nil {
 		return nil // User not assigned to organisation
 	}
 
-	// Check if user already has a pending match
-	hasPending, err := s.matchRepo.HasPendingMatch(userID)
+	// Load the organisation's candidates in bulk
+	pool, err := s.loadCandidatePool(*user.OrganisationID)
 	if err != nil {
 		return nil
 	}
-	if hasPending {
-		return nil // User already has a pending match
-	}
-	
-	// Check if user has availability configuration
-	userConfig, err := s.availConfigRepo.FindByUserID(userID)
-	if err != nil {
+	org := pool.org
+
+	// No new matches during a blackout, as with the scheduled runs
+	if pool.blackedOut() {
 		return nil
 	}
-	if userConfig == nil {
-		return nil // User doesn't have availability config
+
+	// Use the organisation listing for the user too, it comes with tags for scoring
+	for _, u := range pool.users {
+		if u.ID == userID {
+			user = u
+		}
 	}
 
-	// Get all confirmed users in the same organisation
-	users, err := s.userRepo.FindByOrganisation(*user.OrganisationID)
-	if err != nil {
+	// Admins, unconfirmed or paused users, users with a pending match, users not yet due
+	// under their cadence and users without availability configuration don't get matched
+	if !pool.isEligible(user) {
 		return nil
 	}
+	scorer := scorerFor(org)
 
 	// Find available users (excluding the current user, admins, unconfirmed, and those with pending matches)
 	var candidates []*models.User
-	var candidateConfigs = make(map[uint]*models.UserAvailabilityConfig)
+	var pairs []userPair
 	
-	for _, u := range users {
-		if u.ID == userID || u.AccountType == models.AccountTypeAdmin || !u.IsConfirmed {
-			continue
-		}
-		hasPending, err := s.matchRepo.HasPendingMatch(u.ID)
-		if err != nil || hasPending {
+	for _, u := range pool.users {
+		if u.ID == userID || !pool.isEligible(u) {
 			continue
 		}
 
-		// Check if they were ever matched before
-		wasMatched, err := s.matchHistoryRepo.WasEverMatched(userID, u.ID)
-		if err != nil || wasMatched {
-			continue
-		}
-		
-		// Check if candidate has availability configuration
-		candidateConfig, err := s.availConfigRepo.FindByUserID(u.ID)
-		if err != nil || candidateConfig == nil {
+		// Skip blocked colleagues and apply the re-match policy to colleagues the user met before
+		allowed, repeat := pool.checkPair(userID, u.ID)
+		if !allowed {
 			continue
 		}
 		
 		// Check if they have common availability
-		if !models.HasCommonAvailability(userConfig, candidateConfig) {
+		if !pool.canMeet(userID, u.ID) {
 			continue
 		}
 		
-		candidateConfigs[u.ID] = candidateConfig
 		candidates = append(candidates, u)
+		score, breakdown := scorer.Breakdown(user, u)
+		pairs = append(pairs, userPair{
+			user1ID:   userID,
+			user2ID:   u.ID,
+			score:     score,
+			breakdown: breakdown,
+			repeat:    repeat,
+		})
 	}
 
 	if len(candidates) == 0 {
 		return nil // No available candidates, no error
 	}
 
-	// Find the best match based on score
-	var bestCandidate *models.User
-	var bestScore float64 = -1
+	groupSize := org.GroupSize()
 
-	for _, candidate := range candidates {
-		score := s.calculateMatchScore(user.Tags, candidate.Tags)
-		if score > bestScore {
-			bestScore = score
-			bestCandidate = candidate
-		}
-	}
+	var memberIDs []uint
+	var matchScore float64
 
-	if bestCandidate == nil {
-		return nil // No suitable candidate found
-	}
+	if groupSize > 2 {
+		// Build a group around the user from candidates who are also compatible with each other
+		candidateIDs := make([]uint, 0, len(candidates))
+		for _, candidate := range candidates {
+			candidateIDs = append(candidateIDs, candidate.ID)
+		}
+		for i := 0; i < len(candidates); i++ {
+			for j := i + 1; j < len(candidates); j++ {
+				a, b := candidates[i], candidates[j]
+				allowed, repeat := pool.checkPair(a.ID, b.ID)
+				if !allowed || !pool.canMeet(a.ID, b.ID) {
+					continue
+				}
+				score, breakdown := scorer.Breakdown(a, b)
+				pairs = append(pairs, userPair{
+					user1ID:   a.ID,
+					user2ID:   b.ID,
+					score:     score,
+					breakdown: breakdown,
+					repeat:    repeat,
+				})
+			}
+		}
 
-	// Create the match
-	scheduledDate, scheduledTime := s.generateRandomDateTime()
+		pairs = applyRematchPolicy(org, pairs)
+		scores := pairScores(pairs)
+		memberIDs = growGroup(userID, candidateIDs, scores, groupSize, pool.groupFits())
+		matchScore = groupScore(memberIDs, scores)
+	} else {
+		// Find the best match based on score
+		var bestPair *userPair
+
+		pairs = applyRematchPolicy(org, pairs)
+		for _, pair := range pairs {
+			if bestPair == nil || pair.score > bestPair.score {
+				p := pair
+				bestPair = &p
+			}
+		}
 
-	match := &models.Match{
-		OrganisationID: *user.OrganisationID,
-		User1ID:        userID,
-		User2ID:        bestCandidate.ID,
-		MatchScore:     bestScore,
-		Status:         models.MatchStatusPending,
-		ScheduledDate:  scheduledDate,
-		ScheduledTime:  scheduledTime,
-	}
+		if bestPair == nil {
+			return nil // No suitable candidate found
+		}
 
-	if err := s.matchRepo.Create(match); err != nil {
-		return nil // Failed to create match, but no error to caller
+		memberIDs = []uint{userID, bestPair.user2ID}
+		matchScore = bestPair.score
 	}
 
-	// Add to history
-	history := &models.MatchHistory{
-		User1ID:   userID,
-		User2ID:   bestCandidate.ID,
-		MatchedAt: time.Now(),
+	if len(memberIDs) < 2 {
+		return nil // No suitable candidate found
 	}
-	s.matchHistoryRepo.Create(history)
-
-	return nil
-}
 
-func (s *matchService) generateRandomDateTime() (time.Time, string) {
-	// Random day in next 7 days (excluding weekends)
-	daysAhead := rand.Intn(5) + 1 // 1-5 days ahead
-	date := time.Now().AddDate(0, 0, daysAhead)
+	group := matchGroup{userIDs: memberIDs, score: matchScore}
+	group.describe(pairIndex(pairs))
 
-	// Ensure it's a weekday
-	for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
-		daysAhead++
-		date = time.Now().AddDate(0, 0, daysAhead)
+	// Create the match as a run of its own
+	run, err := s.startRun(org.ID, models.MatchRunTriggerRematch)
+	if err != nil {
+		return nil // Failed to create match, but no error to caller
 	}
+	s.createPlannedMatches(pool, &matchPlan{groups: []matchGroup{group}, eligibleUsers: len(candidates) + 1}, run)
 
-	// Random time slot between 9 AM and 5 PM
-	hours := []string{"9 AM", "10 AM", "11 AM", "2 PM", "3 PM", "4 PM", "5 PM"}
-	timeSlot := hours[rand.Intn(len(hours))]
-
-	return date, timeSlot
+	return nil
 }
 
 func (s *matchService) GetCurrentMatch(userID uint) (*models.Match, error) {
@@ -390,10 +623,10 @@ func (s *matchService) GetCurrentMatch(userID uint) (*models.Match, error) {
 		return nil, ErrMatchNotFound
 	}
 
-	// Check if both users have already submitted feedback
+	// Check if all participants have already submitted feedback
 	// If so, this match should not be considered "current"
 	feedbackCount, err := s.matchFeedbackRepo.CountFeedbacksByMatch(match.ID)
-	if err == nil && feedbackCount >= 2 {
+	if err == nil && feedbackCount >= int64(len(match.ActiveParticipants())) {
 		return nil, ErrMatchNotFound
 	}
 
@@ -404,180 +637,409 @@ func (s *matchService) GetMatchHistory(userID uint) ([]*models.Match, error) {
 	return s.matchRepo.FindByUserID(userID)
 }
 
+// GetMatchingStatus returns the user's cadence and pause settings and when they will next
+// be considered for a match
+func (s *matchService) GetMatchingStatus(userID uint) (*models.MatchingStatus, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	lastMatches, err := s.matchRepo.FindLastMatchedAt([]uint{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	status := &models.MatchingStatus{
+		MatchCadence: user.MatchCadence,
+		Paused:       user.IsPaused(now),
+	}
+	if status.Paused {
+		status.PausedUntil = user.PausedUntil
+	}
+
+	var nextEligibleAt time.Time
+	if len(lastMatches) > 0 {
+		lastMatchedAt := lastMatches[0].MatchedAt
+		status.LastMatchedAt = &lastMatchedAt
+		nextEligibleAt = user.NextMatchAfter(lastMatchedAt)
+	}
+	if status.Paused && user.PausedUntil.After(nextEligibleAt) {
+		nextEligibleAt = *user.PausedUntil
+	}
+	if nextEligibleAt.After(now) {
+		status.NextEligibleAt = &nextEligibleAt
+	}
+
+	return status, nil
+}
+
+// UpdateMatchPreferences sets the user's match cadence and pause. A user who resumes or
+// becomes due straight away is considered for a match immediately.
+func (s *matchService) UpdateMatchPreferences(userID uint, input *models.UpdateMatchPreferencesInput) (*models.MatchingStatus, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.MatchCadence = input.MatchCadence
+	user.PausedUntil = input.PausedUntil
+	// A pause that has already ended is the same as no pause
+	if user.PausedUntil != nil && !user.IsPaused(time.Now()) {
+		user.PausedUntil = nil
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if user.PausedUntil == nil {
+		go s.TryGenerateMatchForUser(userID)
+	}
+
+	return s.GetMatchingStatus(userID)
+}
+
 func (s *matchService) AcceptMatch(userID uint, matchID uint) error {
 	match, err := s.matchRepo.FindByID(matchID)
 	if err != nil {
 		return ErrMatchNotFound
 	}
 
-	if match.User1ID != userID && match.User2ID != userID {
+	if !match.IsActiveParticipant(userID) {
 		return ErrUnauthorizedMatch
 	}
 
-	now := time.Now()
-
-	// Mark the current user as accepted with timestamp
-	if match.User1ID == userID {
-		match.User1Accepted = true
-		match.User1AcceptedAt = &now
-	} else {
-		match.User2Accepted = true
-		match.User2AcceptedAt = &now
+	// The other participants are sent the current user's availability config; without
+	// one, or with no slots enabled, they are not notified
+	var availabilitySlots []AvailabilitySlot
+	availConfig, err := s.availConfigRepo.FindByUserID(userID)
+	if err == nil && availConfig != nil {
+		availabilitySlots = windowSlots(availConfig.Windows())
+		if len(availabilitySlots) == 0 {
+			fmt.Printf("WARNING: User %d has availability config but no slots enabled\n", userID)
+		}
 	}
 
-	// If both users accepted, change status to waiting_for_feedback and set expiration (5 days from now)
-	if match.User1Accepted && match.User2Accepted {
-		match.Status = models.MatchStatusWaitingForFeedback
-		expiresAt := now.AddDate(0, 0, 5) // 5 days from now
-		match.ExpiresAt = &expiresAt
+	return s.uow.Do(func(repos *repositories.TxRepositories) error {
+		return s.acceptAndNotify(repos, match, userID, availabilitySlots, len(availabilitySlots) > 0)
+	})
+}
+
+// acceptAndNotify marks the user as accepted and queues, in the same transaction, the
+// confirmations when this completes the match and, with notifyPartners, the user's
+// availability for the other participants
+func (s *matchService) acceptAndNotify(repos *repositories.TxRepositories, match *models.Match, userID uint, availabilitySlots []AvailabilitySlot, notifyPartners bool) error {
+	org, err := s.orgRepo.FindByID(match.OrganisationID)
+	if err != nil {
+		return err
 	}
 
-	if err := s.matchRepo.Update(match); err != nil {
+	wasPending := match.Status == models.MatchStatusPending
+	if err := s.markAccepted(repos.Matches, match, userID, time.Now()); err != nil {
 		return err
 	}
 
-	// Send email notification to the OTHER user when current user accepts
-	// Reload match with user details for email
-	updatedMatch, err := s.matchRepo.FindByID(matchID)
-	if err == nil && updatedMatch != nil {
-		var otherUser *models.User
-		var currentUser *models.User
-		
-		if updatedMatch.User1ID == userID {
-			otherUser = updatedMatch.User2
-			currentUser = updatedMatch.User1
-		} else {
-			otherUser = updatedMatch.User1
-			currentUser = updatedMatch.User2
-		}
-
-		if otherUser != nil && currentUser != nil {
-			// Get current user's availability config
-			availConfig, err := s.availConfigRepo.FindByUserID(currentUser.ID)
-			if err == nil && availConfig != nil {
-				// Convert availability config to Availability map format
-				availability := availConfig.ToAvailability()
-				availabilitySlots := s.formatAvailabilitySlots(availability)
-				
-				// If no slots, don't send email (config exists but all slots are false)
-				if len(availabilitySlots) == 0 {
-					fmt.Printf("WARNING: User %d has availability config but no slots enabled\n", currentUser.ID)
-					return nil
-				}
-				
-				currentUserName := fmt.Sprintf("%s %s", currentUser.FirstName, currentUser.LastName)
-				otherUserName := fmt.Sprintf("%s %s", otherUser.FirstName, otherUser.LastName)
-				
-				err = s.emailSvc.SendMatchAccepted(
-					otherUser.Email,
-					otherUserName,
-					currentUserName,
-					currentUser.Email,
-					availabilitySlots,
-				)
-				if err != nil {
-					fmt.Printf("ERROR: Failed to send match accepted email: %v\n", err)
-				}
+	var notifications []*Notification
+	if wasPending && match.Status == models.MatchStatusWaitingForFeedback {
+		notifications = confirmationNotifications(match, org)
+	}
+	accepter := match.Participant(userID)
+	if notifyPartners && accepter != nil && accepter.User != nil {
+		for _, other := range match.ActiveParticipants() {
+			if other.UserID == userID || other.User == nil {
+				continue
 			}
+			notifications = append(notifications, partnerAcceptedNotification(match, org, accepter.User, other.User, availabilitySlots))
 		}
 	}
 
-	return nil
+	return s.notifier.Enqueue(repos.Outbox, notifications...)
 }
 
-func (s *matchService) AcceptMatchWithAvailability(userID uint, matchID uint, availability models.Availability) (*models.Match, error) {
+// matchCreatedNotifications tells every participant of a new match who they are meeting, what
+// they have in common and when, with signed links to accept or reject it
+func (s *matchService) matchCreatedNotifications(match *models.Match, org *models.Organisation, users map[uint]*models.User) ([]*Notification, error) {
+	var notifications []*Notification
+	for _, p := range match.Participants {
+		user := users[p.UserID]
+		if user == nil {
+			continue
+		}
+		var others []string
+		for _, other := range match.Participants {
+			if other.UserID != p.UserID && users[other.UserID] != nil {
+				others = append(others, fullName(users[other.UserID]))
+			}
+		}
+		acceptURL, err := s.matchLink(user.ID, match.ID, MatchActionAccept)
+		if err != nil {
+			return nil, err
+		}
+		rejectURL, err := s.matchLink(user.ID, match.ID, MatchActionReject)
+		if err != nil {
+			return nil, err
+		}
+
+		var sharedTags []string
+		if match.Explanation != nil {
+			sharedTags = match.Explanation.SharedTags
+		}
+		notifications = append(notifications, &Notification{
+			Event:          models.NotificationMatchCreated,
+			OrganisationID: match.OrganisationID,
+			RecipientName:  fullName(user),
+			RecipientEmail: user.Email,
+			Text:           fmt.Sprintf("%s has a new cuppa with %s", fullName(user), strings.Join(others, ", ")),
+			Data: NotificationData{
+				MatchID:          match.ID,
+				ParticipantNames: others,
+				SharedTags:       sharedTags,
+				MeetingTime:      meetingTimeFor(match, org, user),
+				AcceptURL:        acceptURL,
+				RejectURL:        rejectURL,
+			},
+		})
+	}
+	return notifications, nil
+}
+
+// confirmationNotifications tells every participant of a fully accepted match that it is
+// confirmed, with the calendar invite attached
+func confirmationNotifications(match *models.Match, org *models.Organisation) []*Notification {
+	if match.ScheduledStart == nil || match.ScheduledEnd == nil {
+		return nil
+	}
+
+	invite := buildMatchInvite(match, icsMethodRequest, time.Now())
+	var notifications []*Notification
+	for _, p := range match.ActiveParticipants() {
+		if p.User == nil {
+			continue
+		}
+		var others []string
+		for _, other := range match.ActiveParticipants() {
+			if other.UserID != p.UserID && other.User != nil {
+				others = append(others, fullName(other.User))
+			}
+		}
+		notifications = append(notifications, &Notification{
+			Event:          models.NotificationMatchConfirmed,
+			OrganisationID: match.OrganisationID,
+			RecipientName:  fullName(p.User),
+			RecipientEmail: p.User.Email,
+			Text:           fmt.Sprintf("%s's cuppa with %s is confirmed for %s", fullName(p.User), strings.Join(others, ", "), meetingTimeFor(match, org, p.User)),
+			Data: NotificationData{
+				MatchID:          match.ID,
+				ParticipantNames: others,
+				MeetingTime:      meetingTimeFor(match, org, p.User),
+				Invite:           invite,
+			},
+		})
+	}
+	return notifications
+}
+
+// cancellationNotifications withdraws a confirmed match from the calendars of the given
+// participants
+func cancellationNotifications(match *models.Match, org *models.Organisation, participants []*models.MatchParticipant) []*Notification {
+	if match.ScheduledStart == nil || match.ScheduledEnd == nil {
+		return nil
+	}
+
+	cancellation := buildMatchInvite(match, icsMethodCancel, time.Now())
+	var notifications []*Notification
+	for _, p := range participants {
+		if p.User == nil {
+			continue
+		}
+		notifications = append(notifications, &Notification{
+			Event:          models.NotificationMatchCancelled,
+			OrganisationID: match.OrganisationID,
+			RecipientName:  fullName(p.User),
+			RecipientEmail: p.User.Email,
+			Text:           fmt.Sprintf("%s's cuppa on %s is cancelled", fullName(p.User), meetingTimeFor(match, org, p.User)),
+			Data: NotificationData{
+				MatchID:     match.ID,
+				MeetingTime: meetingTimeFor(match, org, p.User),
+				Invite:      cancellation,
+			},
+		})
+	}
+	return notifications
+}
+
+// GetMatchCalendar returns the match as an .ics file for one of its participants. Matches
+// that will not take place, or that the user left, are returned as a cancellation.
+func (s *matchService) GetMatchCalendar(userID uint, matchID uint) ([]byte, error) {
 	match, err := s.matchRepo.FindByID(matchID)
 	if err != nil {
 		return nil, ErrMatchNotFound
 	}
-
-	if match.User1ID != userID && match.User2ID != userID {
+	if match.Participant(userID) == nil {
 		return nil, ErrUnauthorizedMatch
 	}
+	if match.ScheduledStart == nil || match.ScheduledEnd == nil {
+		return nil, ErrMatchNotScheduled
+	}
 
-	now := time.Now()
+	method := icsMethodRequest
+	if isCancelledFor(match, userID) {
+		method = icsMethodCancel
+	}
+	return buildMatchInvite(match, method, time.Now()), nil
+}
 
-	// Mark the current user as accepted with timestamp
-	if match.User1ID == userID {
-		match.User1Accepted = true
-		match.User1AcceptedAt = &now
-	} else {
-		match.User2Accepted = true
-		match.User2AcceptedAt = &now
+// isCancelledFor reports whether the cuppa is off for the user: they left it, it was
+// rejected, or it expired before everyone accepted
+func isCancelledFor(match *models.Match, userID uint) bool {
+	if !match.IsActiveParticipant(userID) {
+		return true
+	}
+	switch match.Status {
+	case models.MatchStatusRejected:
+		return true
+	case models.MatchStatusExpired:
+		return match.ExpiryReason == models.ExpiryReasonAcceptanceWindowElapsed
+	default:
+		return false
+	}
+}
+
+// markAccepted records the user's acceptance and moves the match to waiting_for_feedback
+// once every active participant has accepted
+func (s *matchService) markAccepted(matchRepo repositories.MatchRepository, match *models.Match, userID uint, now time.Time) error {
+	participant := match.Participant(userID)
+	participant.Status = models.ParticipantStatusAccepted
+	participant.AcceptedAt = &now
+	if err := matchRepo.UpdateParticipant(participant); err != nil {
+		return err
+	}
+
+	s.refreshMatchStatus(match, now)
+	return matchRepo.Update(match)
+}
+
+// refreshMatchStatus derives the match status from its participants and keeps the
+// legacy user1/user2 acceptance fields in sync
+func (s *matchService) refreshMatchStatus(match *models.Match, now time.Time) {
+	if p := match.Participant(match.User1ID); p != nil {
+		match.User1Accepted = p.Status == models.ParticipantStatusAccepted
+		match.User1AcceptedAt = p.AcceptedAt
+	}
+	if p := match.Participant(match.User2ID); p != nil {
+		match.User2Accepted = p.Status == models.ParticipantStatusAccepted
+		match.User2AcceptedAt = p.AcceptedAt
+	}
+
+	if match.Status != models.MatchStatusPending {
+		return
 	}
 
-	// If both users accepted, change status to waiting_for_feedback and set expiration (5 days from now)
-	if match.User1Accepted && match.User2Accepted {
+	// If fewer than two participants remain, the whole match is rejected
+	if len(match.ActiveParticipants()) < 2 {
+		match.Status = models.MatchStatusRejected
+		return
+	}
+
+	// If all participants accepted, change status to waiting_for_feedback and keep it open for
+	// feedback until 5 days after the cuppa, or after now for matches without a slot
+	if match.AllAccepted() {
 		match.Status = models.MatchStatusWaitingForFeedback
-		expiresAt := now.AddDate(0, 0, 5) // 5 days from now
+		expiresAt := feedbackDeadline(match, now)
 		match.ExpiresAt = &expiresAt
 	}
+}
 
-	// Save or update availability
-	existingAvailability, err := s.matchRepo.FindAvailabilityByMatchAndUser(matchID, userID)
-	if err != nil || existingAvailability == nil {
-		// Create new availability
-		newAvailability := &models.MatchAvailability{
-			MatchID:      matchID,
-			UserID:       userID,
-			Availability: availability,
-		}
-		if err := s.matchRepo.CreateAvailability(newAvailability); err != nil {
-			return nil, err
-		}
-	} else {
-		// Update existing availability
-		existingAvailability.Availability = availability
-		if err := s.matchRepo.UpdateAvailability(existingAvailability); err != nil {
-			return nil, err
-		}
+// feedbackDeadline is when an accepted match closes for feedback: the feedback window after
+// its slot ends, so it can't expire before the cuppa takes place, or after now once it has
+func feedbackDeadline(match *models.Match, now time.Time) time.Time {
+	from := now
+	if match.ScheduledEnd != nil && match.ScheduledEnd.After(now) {
+		from = *match.ScheduledEnd
 	}
+	return from.AddDate(0, 0, feedbackWindowDays)
+}
 
-	// Update match
-	if err := s.matchRepo.Update(match); err != nil {
-		return nil, err
+func (s *matchService) AcceptMatchWithAvailability(userID uint, matchID uint, availability models.Availability) (*models.Match, error) {
+	match, err := s.matchRepo.FindByID(matchID)
+	if err != nil {
+		return nil, ErrMatchNotFound
 	}
 
-	// Reload match with availabilities
-	updatedMatch, err := s.matchRepo.FindByID(matchID)
+	if !match.IsActiveParticipant(userID) {
+		return nil, ErrUnauthorizedMatch
+	}
+
+	availabilitySlots := s.formatAvailabilitySlots(availability)
+	err = s.uow.Do(func(repos *repositories.TxRepositories) error {
+		// Save or update availability
+		existingAvailability, err := repos.Matches.FindAvailabilityByMatchAndUser(matchID, userID)
+		if err != nil || existingAvailability == nil {
+			// Create new availability
+			newAvailability := &models.MatchAvailability{
+				MatchID:      matchID,
+				UserID:       userID,
+				Availability: availability,
+			}
+			if err := repos.Matches.CreateAvailability(newAvailability); err != nil {
+				return err
+			}
+		} else {
+			// Update existing availability
+			existingAvailability.Availability = availability
+			if err := repos.Matches.UpdateAvailability(existingAvailability); err != nil {
+				return err
+			}
+		}
+
+		// Mark the current user as accepted and tell the other participants their availability
+		return s.acceptAndNotify(repos, match, userID, availabilitySlots, true)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Send email notification to the OTHER user when current user accepts
-	// The other user gets notified about the current user's availability
-	var otherUser *models.User
-	var currentUserName string
-	
-	if updatedMatch.User1ID == userID {
-		otherUser = updatedMatch.User2
-		currentUserName = fmt.Sprintf("%s %s", updatedMatch.User1.FirstName, updatedMatch.User1.LastName)
-	} else {
-		otherUser = updatedMatch.User1
-		currentUserName = fmt.Sprintf("%s %s", updatedMatch.User2.FirstName, updatedMatch.User2.LastName)
+	// Reload match with availabilities
+	return s.matchRepo.FindByID(matchID)
+}
+
+// partnerAcceptedNotification tells recipient that accepter accepted their match
+func partnerAcceptedNotification(match *models.Match, org *models.Organisation, accepter, recipient *models.User, availabilitySlots []AvailabilitySlot) *Notification {
+	return &Notification{
+		Event:          models.NotificationPartnerAccepted,
+		OrganisationID: match.OrganisationID,
+		RecipientName:  fullName(recipient),
+		RecipientEmail: recipient.Email,
+		Text:           fmt.Sprintf("%s accepted the cuppa with %s", fullName(accepter), strings.Join(otherParticipantNames(match, accepter.ID), ", ")),
+		Data: NotificationData{
+			MatchID:           match.ID,
+			PartnerName:       fullName(accepter),
+			PartnerEmail:      accepter.Email,
+			MeetingTime:       meetingTimeFor(match, org, recipient),
+			AvailabilitySlots: availabilitySlots,
+		},
 	}
+}
 
-	// Format the current user's availability as structured data for SendGrid
-	if otherUser != nil {
-		availabilitySlots := s.formatAvailabilitySlots(availability)
-		
-		var currentUserEmail string
-		if updatedMatch.User1ID == userID {
-			currentUserEmail = updatedMatch.User1.Email
-		} else {
-			currentUserEmail = updatedMatch.User2.Email
-		}
-		
-		s.emailSvc.SendMatchAccepted(
-			otherUser.Email,
-			fmt.Sprintf("%s %s", otherUser.FirstName, otherUser.LastName),
-			currentUserName,
-			currentUserEmail,
-			availabilitySlots,
-		)
+// meetingTimeFor formats the match's scheduled slot in the recipient's time zone, or returns
+// "" when the match has no slot
+func meetingTimeFor(match *models.Match, org *models.Organisation, recipient *models.User) string {
+	if match.ScheduledStart == nil || match.ScheduledEnd == nil || org == nil {
+		return ""
 	}
+	return formatMeetingTime(*match.ScheduledStart, *match.ScheduledEnd, recipient.Location(org))
+}
 
-	return updatedMatch, nil
+// windowSlots lists weekly availability windows for email templates, one slot per window
+// with its times as the period, e.g. Tuesday "12:00-13:30"
+func windowSlots(windows []models.WeeklyWindow) []AvailabilitySlot {
+	slots := []AvailabilitySlot{}
+	for _, w := range windows {
+		slots = append(slots, AvailabilitySlot{Day: w.Day.String(), Period: w.Hours()})
+	}
+	return slots
 }
 
 // formatAvailabilitySlots converts availability map to structured array for SendGrid template
@@ -626,26 +1088,112 @@ func (s *matchService) GetMatchAvailabilities(userID uint, matchID uint) ([]*mod
 	}
 
 	// Check if user is part of this match
-	if match.User1ID != userID && match.User2ID != userID {
+	if match.Participant(userID) == nil {
 		return nil, ErrUnauthorizedMatch
 	}
 
 	return s.matchRepo.FindAvailabilitiesByMatch(matchID)
 }
 
-func (s *matchService) RejectMatch(userID uint, matchID uint) error {
+// RejectMatch removes the user from the match, recording their reason if they gave one.
+// Partners left without a cuppa are re-matched straight away.
+func (s *matchService) RejectMatch(userID uint, matchID uint, reason models.RejectionReason) error {
 	match, err := s.matchRepo.FindByID(matchID)
 	if err != nil {
 		return ErrMatchNotFound
 	}
 
-	if match.User1ID != userID && match.User2ID != userID {
+	if !match.IsActiveParticipant(userID) {
 		return ErrUnauthorizedMatch
 	}
 
-	// If any user rejects, the whole match is rejected
-	match.Status = models.MatchStatusRejected
-	return s.matchRepo.Update(match)
+	// The rejecting user leaves the match. For pairs (or groups that drop below
+	// two members) the whole match is rejected; larger groups carry on without them.
+	// Everyone already has the invite of a confirmed match, so it needs withdrawing
+	confirmed := match.Status == models.MatchStatusAccepted || match.Status == models.MatchStatusWaitingForFeedback
+
+	participant := match.Participant(userID)
+	participant.Status = models.ParticipantStatusRejected
+	participant.RejectionReason = reason
+
+	if match.Status == models.MatchStatusWaitingForFeedback && len(match.ActiveParticipants()) < 2 {
+		match.Status = models.MatchStatusRejected
+	}
+	s.refreshMatchStatus(match, time.Now())
+
+	var notifications []*Notification
+	if confirmed {
+		match.CalendarSequence++
+		org, err := s.orgRepo.FindByID(match.OrganisationID)
+		if err != nil {
+			return err
+		}
+		if match.Status == models.MatchStatusRejected {
+			notifications = cancellationNotifications(match, org, match.Participants)
+		} else {
+			notifications = cancellationNotifications(match, org, []*models.MatchParticipant{participant})
+		}
+	}
+
+	// The rejection, the new calendar sequence and the cancellations are saved together
+	err = s.uow.Do(func(repos *repositories.TxRepositories) error {
+		if err := repos.Matches.UpdateParticipant(participant); err != nil {
+			return err
+		}
+		if err := repos.Matches.Update(match); err != nil {
+			return err
+		}
+		return s.notifier.Enqueue(repos.Outbox, notifications...)
+	})
+	if err != nil {
+		return err
+	}
+
+	// In a pair it is clear who the user doesn't want to meet; in a group it is not,
+	// so the reason is only recorded
+	if reason == models.RejectionReasonDontWantToMeet && !match.IsGroup() {
+		for _, p := range match.Participants {
+			if p.UserID != userID {
+				s.excludePair(match.OrganisationID, userID, p.UserID)
+			}
+		}
+	}
+
+	if match.Status == models.MatchStatusRejected {
+		for _, p := range match.ActiveParticipants() {
+			go s.TryGenerateMatchForUser(p.UserID)
+		}
+	}
+
+	return nil
+}
+
+// excludePair keeps two users apart for rejectionExclusionDays. Unlike personal blocks the
+// exclusion is not listed anywhere and lapses on its own.
+func (s *matchService) excludePair(organisationID uint, userID uint, otherUserID uint) {
+	expiresAt := time.Now().AddDate(0, 0, rejectionExclusionDays)
+
+	existing, err := s.blockRepo.FindOne(userID, otherUserID, models.MatchBlockSourceRejection)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to look up exclusion of users %d and %d: %v\n", userID, otherUserID, err)
+		return
+	}
+	if existing != nil {
+		existing.ExpiresAt = &expiresAt
+		err = s.blockRepo.Update(existing)
+	} else {
+		err = s.blockRepo.Create(&models.MatchBlock{
+			OrganisationID: organisationID,
+			UserID:         userID,
+			BlockedUserID:  otherUserID,
+			Source:         models.MatchBlockSourceRejection,
+			CreatedByID:    userID,
+			ExpiresAt:      &expiresAt,
+		})
+	}
+	if err != nil {
+		fmt.Printf("ERROR: Failed to exclude users %d and %d from matching: %v\n", userID, otherUserID, err)
+	}
 }
 
 func (s *matchService) GetOrganisationMatches(organisationID uint) ([]*models.Match, error) {
@@ -665,11 +1213,11 @@ func (s *matchService) SubmitFeedback(userID uint, matchID uint, rating int, com
 	}
 
 	// Check if user is part of this match
-	if match.User1ID != userID && match.User2ID != userID {
+	if !match.IsActiveParticipant(userID) {
 		return ErrUnauthorizedMatch
 	}
 
-	// Check if match is waiting for feedback (both users accepted)
+	// Check if match is waiting for feedback (all participants accepted)
 	if match.Status != models.MatchStatusWaitingForFeedback {
 		return ErrMatchNotAccepted
 	}
@@ -695,33 +1243,33 @@ func (s *matchService) SubmitFeedback(userID uint, matchID uint, rating int, com
 		return err
 	}
 
-	// Determine who is being rated (the other user in the match)
-	var ratedUserID uint
-	if match.User1ID == userID {
-		ratedUserID = match.User2ID
-	} else {
-		ratedUserID = match.User1ID
-	}
-
-	// Update average rating for the rated user
-	if err := s.updateUserAverageRating(ratedUserID); err != nil {
-		return err
+	// The feedback rates every other participant of the match
+	activeParticipants := match.ActiveParticipants()
+	for _, participant := range activeParticipants {
+		if participant.UserID == userID {
+			continue
+		}
+		// Update average rating for the rated user
+		if err := s.updateUserAverageRating(participant.UserID); err != nil {
+			return err
+		}
 	}
 
-	// Check if both users have now submitted feedback
+	// Check if all participants have now submitted feedback
 	feedbackCount, err := s.matchFeedbackRepo.CountFeedbacksByMatch(matchID)
-	if err == nil && feedbackCount >= 2 {
-		// Both users gave feedback, mark match as completed
+	if err == nil && feedbackCount >= int64(len(activeParticipants)) {
+		// Everyone gave feedback, mark match as completed
 		match.Status = models.MatchStatusCompleted
 		if updateErr := s.matchRepo.Update(match); updateErr != nil {
 			// Log error but don't fail the feedback submission
 			fmt.Printf("Warning: Failed to update match status to completed: %v\n", updateErr)
 		}
 		
-		// Try to generate new matches for both users
+		// Try to generate new matches for all participants
 		// These operations are fire-and-forget - errors are ignored
-		go s.TryGenerateMatchForUser(match.User1ID)
-		go s.TryGenerateMatchForUser(match.User2ID)
+		for _, participant := range activeParticipants {
+			go s.TryGenerateMatchForUser(participant.UserID)
+		}
 	}
 
 	return nil
@@ -746,10 +1294,10 @@ func (s *matchService) updateUserAverageRating(userID uint) error {
 
 		// Find feedback where the OTHER user rated this user
 		for _, feedback := range feedbacks {
-			// If this is a feedback from the other user (not from userID themselves)
+			// If this is a feedback from another participant (not from userID themselves)
 			if feedback.UserID != userID {
 				// Check if userID is part of this match
-				if match.User1ID == userID || match.User2ID == userID {
+				if match.IsActiveParticipant(userID) {
 					totalRating += float64(feedback.Rating)
 					feedbackCount++
 				}
@@ -780,7 +1328,7 @@ func (s *matchService) GetMatchFeedbacks(userID uint, matchID uint) ([]*models.M
 	}
 
 	// Check if user is part of this match
-	if match.User1ID != userID && match.User2ID != userID {
+	if match.Participant(userID) == nil {
 		return nil, ErrUnauthorizedMatch
 	}
 
@@ -797,8 +1345,8 @@ func (s *matchService) GetMatchesPendingFeedback(userID uint) ([]*models.Match,
 	var pendingFeedback []*models.Match
 
 	for _, match := range matches {
-		// Only waiting_for_feedback matches (both users accepted, waiting for feedback)
-		if match.Status != models.MatchStatusWaitingForFeedback {
+		// Only waiting_for_feedback matches (all participants accepted, waiting for feedback)
+		if match.Status != models.MatchStatusWaitingForFeedback || !match.IsActiveParticipant(userID) {
 			continue
 		}
 
@@ -816,3 +1364,52 @@ func (s *matchService) GetMatchesPendingFeedback(userID uint) ([]*models.Match,
 	return pendingFeedback, nil
 }
 
+
+// ExpireStaleMatches moves pending matches older than the acceptance window and
+// waiting_for_feedback matches past their ExpiresAt to expired, recording why.
+// Affected participants are immediately considered for a new match.
+func (s *matchService) ExpireStaleMatches(acceptanceWindow time.Duration) (int, error) {
+	now := time.Now()
+
+	pending, err := s.matchRepo.FindPendingCreatedBefore(now.Add(-acceptanceWindow))
+	if err != nil {
+		return 0, err
+	}
+	waiting, err := s.matchRepo.FindWaitingForFeedbackExpiredBefore(now)
+	if err != nil {
+		return 0, err
+	}
+
+	var affectedUsers []uint
+	expiredCount := 0
+
+	expire := func(match *models.Match, reason string) {
+		expired, err := s.matchRepo.Expire(match.ID, match.Status, now, reason)
+		if err != nil {
+			fmt.Printf("ERROR: Failed to expire match %d: %v\n", match.ID, err)
+			return
+		}
+		if !expired {
+			// The match moved on since it was read, e.g. its last participant accepted
+			return
+		}
+		expiredCount++
+		for _, participant := range match.ActiveParticipants() {
+			affectedUsers = append(affectedUsers, participant.UserID)
+		}
+	}
+
+	for _, match := range pending {
+		expire(match, models.ExpiryReasonAcceptanceWindowElapsed)
+	}
+	for _, match := range waiting {
+		expire(match, models.ExpiryReasonFeedbackWindowElapsed)
+	}
+
+	// Re-match only after every stale match is expired so freed users can pair up with each other
+	for _, userID := range affectedUsers {
+		s.TryGenerateMatchForUser(userID)
+	}
+
+	return expiredCount, nil
+}
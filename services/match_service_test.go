@@ -0,0 +1,149 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+// TestRefreshMatchStatusKeepsFeedbackWindowAfterSlot checks a match confirmed for a slot at
+// the far end of the scheduling range stays open for feedback after the cuppa
+func TestRefreshMatchStatusKeepsFeedbackWindowAfterSlot(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		end  *time.Time
+		want time.Time
+	}{
+		{"slot 7 days out", timePtr(now.AddDate(0, 0, 7).Add(30 * time.Minute)), now.AddDate(0, 0, 7+feedbackWindowDays).Add(30 * time.Minute)},
+		{"slot already over", timePtr(now.Add(-time.Hour)), now.AddDate(0, 0, feedbackWindowDays)},
+		{"no slot", nil, now.AddDate(0, 0, feedbackWindowDays)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acceptedAt := now.Add(-time.Hour)
+			match := &models.Match{
+				User1ID:      1,
+				User2ID:      2,
+				Status:       models.MatchStatusPending,
+				ScheduledEnd: tt.end,
+				Participants: []*models.MatchParticipant{
+					{UserID: 1, Status: models.ParticipantStatusAccepted, AcceptedAt: &acceptedAt},
+					{UserID: 2, Status: models.ParticipantStatusAccepted, AcceptedAt: &acceptedAt},
+				},
+			}
+			(&matchService{}).refreshMatchStatus(match, now)
+
+			if match.Status != models.MatchStatusWaitingForFeedback {
+				t.Fatalf("status = %s, want %s", match.Status, models.MatchStatusWaitingForFeedback)
+			}
+			if match.ExpiresAt == nil || !match.ExpiresAt.Equal(tt.want) {
+				t.Errorf("expires at %v, want %v", match.ExpiresAt, tt.want)
+			}
+			if tt.end != nil && !match.ExpiresAt.After(*tt.end) {
+				t.Errorf("expires at %v, before the cuppa ends at %v", match.ExpiresAt, *tt.end)
+			}
+		})
+	}
+}
+
+// TestWindowSlotsUseTimeRanges checks the availability sent to partners comes from the
+// weekly time ranges, not the legacy half-day flags
+func TestWindowSlotsUseTimeRanges(t *testing.T) {
+	config := &models.UserAvailabilityConfig{
+		MondayMorning: true,
+		TimeRanges: models.TimeRanges{
+			{Day: "Tuesday", Start: "12:00", End: "13:30"},
+			{Day: "Thursday", Start: "09:00", End: "10:00"},
+		},
+	}
+	got := windowSlots(config.Windows())
+	want := []AvailabilitySlot{{Day: "Tuesday", Period: "12:00-13:30"}, {Day: "Thursday", Period: "09:00-10:00"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("slots = %v, want %v", got, want)
+	}
+
+	if got := windowSlots((&models.UserAvailabilityConfig{}).Windows()); len(got) != 0 {
+		t.Errorf("slots without availability = %v, want none", got)
+	}
+}
+
+// newRejectService returns a match service over a confirmed group match of users 1 to 3,
+// whose writes go to the returned transaction
+func newRejectService(outbox repositories.OutboxRepository) (*matchService, *repositories.TxRepositories, *models.Match) {
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	end := start.Add(30 * time.Minute)
+	match := &models.Match{
+		ID:             7,
+		OrganisationID: 1,
+		User1ID:        1,
+		User2ID:        2,
+		Status:         models.MatchStatusWaitingForFeedback,
+		ScheduledStart: &start,
+		ScheduledEnd:   &end,
+	}
+	for id, name := range []string{"Ada", "Grace", "Hedy"} {
+		userID := uint(id + 1)
+		match.Participants = append(match.Participants, &models.MatchParticipant{
+			MatchID: 7,
+			UserID:  userID,
+			Status:  models.ParticipantStatusAccepted,
+			User:    &models.User{ID: userID, FirstName: name, Email: strings.ToLower(name) + "@example.com"},
+		})
+	}
+	tx := &repositories.TxRepositories{Matches: &memTxMatchRepo{}, Outbox: outbox}
+	s := &matchService{
+		matchRepo: &memLinkMatchRepo{match: match},
+		orgRepo:   &memOrgRepo{queryCounter: &queryCounter{}, org: &models.Organisation{ID: 1, TimeZone: "UTC"}},
+		uow:       &memUnitOfWork{repos: tx},
+		notifier:  &notifier{channelRepo: &memChannelRepo{}},
+	}
+	return s, tx, match
+}
+
+// TestRejectMatchWritesInOneUnitOfWork checks leaving a confirmed group saves the rejection,
+// the new calendar sequence and the leaver's cancellation through the transaction
+func TestRejectMatchWritesInOneUnitOfWork(t *testing.T) {
+	outbox := &memOutboxRepo{}
+	s, tx, match := newRejectService(outbox)
+
+	if err := s.RejectMatch(3, 7, models.RejectionReasonBadTiming); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+
+	matches := tx.Matches.(*memTxMatchRepo)
+	if len(matches.updatedParticipants) != 1 || matches.updatedParticipants[0].Status != models.ParticipantStatusRejected {
+		t.Errorf("participants updated in the transaction = %v, want the leaver", matches.updatedParticipants)
+	}
+	if len(matches.updated) != 1 || match.CalendarSequence != 1 {
+		t.Errorf("matches updated = %d with sequence %d, want 1 with sequence 1", len(matches.updated), match.CalendarSequence)
+	}
+	if len(outbox.messages) != 1 || outbox.messages[0].Event != models.NotificationMatchCancelled || outbox.messages[0].Recipient != "hedy@example.com" {
+		t.Fatalf("outbox = %v, want one cancellation for the leaver", outbox.messages)
+	}
+	var queued Notification
+	if err := json.Unmarshal(outbox.messages[0].Payload, &queued); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if !strings.Contains(string(queued.Data.Invite), "METHOD:CANCEL") {
+		t.Errorf("invite = %q, want a cancellation", queued.Data.Invite)
+	}
+}
+
+// TestRejectMatchFailsWhenCancellationsCannotBeQueued checks a failure to queue the
+// cancellations fails the rejection rather than leaving the invite in calendars
+func TestRejectMatchFailsWhenCancellationsCannotBeQueued(t *testing.T) {
+	s, _, _ := newRejectService(&failingOutboxRepo{})
+
+	if err := s.RejectMatch(3, 7, models.RejectionReasonBadTiming); err == nil {
+		t.Error("reject with a failing outbox: got no error")
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+var (
+	ErrChannelNotFound = errors.New("notification channel not found")
+	ErrInvalidChannel  = errors.New("invalid notification channel")
+)
+
+type NotificationChannelService interface {
+	GetChannels(organisationID uint) ([]*models.NotificationChannel, error)
+	CreateChannel(organisationID uint, input *models.CreateNotificationChannelInput) (*models.NotificationChannel, error)
+	DeleteChannel(organisationID uint, channelID uint) error
+}
+
+type notificationChannelService struct {
+	channelRepo repositories.NotificationChannelRepository
+}
+
+func NewNotificationChannelService(channelRepo repositories.NotificationChannelRepository) NotificationChannelService {
+	return &notificationChannelService{
+		channelRepo: channelRepo,
+	}
+}
+
+func (s *notificationChannelService) GetChannels(organisationID uint) ([]*models.NotificationChannel, error) {
+	return s.channelRepo.FindByOrganisation(organisationID)
+}
+
+// CreateChannel adds a channel. Webhook channels need an http(s) URL; email channels take none.
+func (s *notificationChannelService) CreateChannel(organisationID uint, input *models.CreateNotificationChannelInput) (*models.NotificationChannel, error) {
+	if input.Kind == models.NotificationChannelEmail {
+		if input.URL != "" || input.Secret != "" {
+			return nil, fmt.Errorf("%w: email channels take no URL or secret", ErrInvalidChannel)
+		}
+	} else {
+		parsed, err := url.Parse(input.URL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return nil, fmt.Errorf("%w: %s channels need an http(s) webhook URL", ErrInvalidChannel, input.Kind)
+		}
+		if input.Secret != "" && input.Kind != models.NotificationChannelWebhook {
+			return nil, fmt.Errorf("%w: only webhook channels are signed with a secret", ErrInvalidChannel)
+		}
+	}
+	for _, event := range input.Events {
+		if !event.IsValid() {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidChannel, event)
+		}
+	}
+
+	channel := &models.NotificationChannel{
+		OrganisationID: organisationID,
+		Kind:           input.Kind,
+		Name:           input.Name,
+		URL:            input.URL,
+		Secret:         input.Secret,
+		Events:         input.Events,
+	}
+	if err := s.channelRepo.Create(channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (s *notificationChannelService) DeleteChannel(organisationID uint, channelID uint) error {
+	channel, err := s.channelRepo.FindByID(channelID)
+	if err != nil {
+		return err
+	}
+	if channel == nil || channel.OrganisationID != organisationID {
+		return ErrChannelNotFound
+	}
+	return s.channelRepo.Delete(channelID)
+}
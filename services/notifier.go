@@ -0,0 +1,314 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+// webhookTimeout bounds each webhook request so a slow endpoint can't hold up the outbox worker
+const webhookTimeout = 10 * time.Second
+
+// Notification is one event addressed to one user. Email channels deliver it to the
+// recipient; chat and webhook channels post it where the whole organisation can see it,
+// once per event of a match.
+type Notification struct {
+	Event          models.NotificationEvent `json:"event"`
+	OrganisationID uint                     `json:"organisationId"`
+	RecipientName  string                   `json:"recipientName"`
+	RecipientEmail string                   `json:"recipientEmail"`
+	// Text is a plain-text summary for chat channels, written for a shared channel: it names
+	// everyone involved, as only one participant's copy is posted
+	Text string           `json:"text"`
+	Data NotificationData `json:"data"`
+}
+
+// NotificationData holds the details of the event; each event fills the fields its
+// email needs
+type NotificationData struct {
+	MatchID           uint               `json:"matchId,omitempty"`
+	OrganisationName  string             `json:"organisationName,omitempty"`
+	PartnerName       string             `json:"partnerName,omitempty"`
+	PartnerEmail      string             `json:"partnerEmail,omitempty"`
+	ParticipantNames  []string           `json:"participantNames,omitempty"`
+	SharedTags        []string           `json:"sharedTags,omitempty"`
+	MeetingTime       string             `json:"meetingTime,omitempty"`
+	Slots             []string           `json:"slots,omitempty"`
+	Counter           bool               `json:"counter,omitempty"`
+	AvailabilitySlots []AvailabilitySlot `json:"availabilitySlots,omitempty"`
+	// Invite is the calendar invite, or cancellation, attached to emails
+	Invite []byte `json:"invite,omitempty"`
+	// FeedbackDeadline is when the feedback window closes, in the recipient's time zone
+	FeedbackDeadline string `json:"feedbackDeadline,omitempty"`
+	// AcceptURL and RejectURL act on the match for the recipient without logging in. They
+	// are only emailed, never posted to webhooks.
+	AcceptURL string `json:"acceptUrl,omitempty"`
+	RejectURL string `json:"rejectUrl,omitempty"`
+}
+
+// withoutActionLinks returns a copy of the notification without its signed action links,
+// which only belong in the recipient's own email
+func (n Notification) withoutActionLinks() Notification {
+	n.Data.AcceptURL, n.Data.RejectURL = "", ""
+	return n
+}
+
+// ErrChannelGone is returned when a queued message's channel was deleted; such messages are
+// dead-lettered straight away
+var ErrChannelGone = errors.New("notification channel no longer exists")
+
+// Notifier fans notifications out to the channels their organisation routes them to. It
+// queues one outbox message per channel; the outbox worker delivers them later.
+type Notifier interface {
+	// Notify queues the notifications on their own
+	Notify(notifications ...*Notification) error
+	// Enqueue queues the notifications through the outbox of a unit of work, so they are only
+	// sent if the change they describe is committed. The notifications of one event, one per
+	// participant, are queued together so that shared channels post the event once.
+	Enqueue(outbox repositories.OutboxRepository, notifications ...*Notification) error
+	// Deliver sends a queued message through its channel
+	Deliver(message *models.OutboxMessage) error
+}
+
+type notifier struct {
+	channelRepo repositories.NotificationChannelRepository
+	outboxRepo  repositories.OutboxRepository
+	emailSvc    EmailService
+	client      *http.Client
+}
+
+func NewNotifier(channelRepo repositories.NotificationChannelRepository, outboxRepo repositories.OutboxRepository, emailSvc EmailService) Notifier {
+	return &notifier{
+		channelRepo: channelRepo,
+		outboxRepo:  outboxRepo,
+		emailSvc:    emailSvc,
+		client:      &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// defaultChannels is the routing of organisations that have not configured any channels
+var defaultChannels = []*models.NotificationChannel{{Kind: models.NotificationChannelEmail, Name: "Email"}}
+
+func (n *notifier) Notify(notifications ...*Notification) error {
+	return n.Enqueue(n.outboxRepo, notifications...)
+}
+
+// sharedPost identifies an event of one match posted to a chat or webhook channel
+type sharedPost struct {
+	channel *models.NotificationChannel
+	event   models.NotificationEvent
+	matchID uint
+}
+
+// Enqueue writes a message for every channel that routes each notification's event. Email
+// channels get one message per recipient; chat and webhook channels are shared, so they
+// get the first notification of each match and event in the batch and skip the copies
+// addressed to the other participants.
+func (n *notifier) Enqueue(outbox repositories.OutboxRepository, notifications ...*Notification) error {
+	channelsByOrg := make(map[uint][]*models.NotificationChannel)
+	posted := make(map[sharedPost]bool)
+	now := time.Now()
+	for _, notification := range notifications {
+		channels, ok := channelsByOrg[notification.OrganisationID]
+		if !ok {
+			channels = defaultChannels
+			if notification.OrganisationID != 0 {
+				configured, err := n.channelRepo.FindByOrganisation(notification.OrganisationID)
+				if err != nil {
+					return err
+				}
+				if len(configured) > 0 {
+					channels = configured
+				}
+			}
+			channelsByOrg[notification.OrganisationID] = channels
+		}
+
+		payload, err := json.Marshal(notification)
+		if err != nil {
+			return err
+		}
+
+		for _, channel := range channels {
+			if !channel.Routes(notification.Event) {
+				continue
+			}
+			if channel.Kind != models.NotificationChannelEmail && notification.Data.MatchID != 0 {
+				post := sharedPost{channel: channel, event: notification.Event, matchID: notification.Data.MatchID}
+				if posted[post] {
+					continue
+				}
+				posted[post] = true
+			}
+
+			message := &models.OutboxMessage{
+				OrganisationID: notification.OrganisationID,
+				Event:          notification.Event,
+				ChannelKind:    channel.Kind,
+				Payload:        payload,
+				Status:         models.OutboxStatusPending,
+				NextAttemptAt:  now,
+			}
+			if channel.ID != 0 {
+				channelID := channel.ID
+				message.ChannelID = &channelID
+			}
+			if channel.Kind == models.NotificationChannelEmail {
+				message.Recipient = notification.RecipientEmail
+			}
+			if err := outbox.Create(message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Deliver sends the message through the channel it was queued for
+func (n *notifier) Deliver(message *models.OutboxMessage) error {
+	var notification Notification
+	if err := json.Unmarshal(message.Payload, &notification); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	channel := defaultChannels[0]
+	if message.ChannelID != nil {
+		configured, err := n.channelRepo.FindByID(*message.ChannelID)
+		if err != nil {
+			return err
+		}
+		if configured == nil {
+			return ErrChannelGone
+		}
+		channel = configured
+	}
+	return n.deliver(channel, &notification)
+}
+
+func (n *notifier) deliver(channel *models.NotificationChannel, notification *Notification) error {
+	switch channel.Kind {
+	case models.NotificationChannelEmail:
+		return n.sendEmail(notification)
+	case models.NotificationChannelSlack:
+		return n.post(channel.URL, map[string]string{
+			"text": fmt.Sprintf("*%s*\n%s", notificationTitle(notification.Event), notification.Text),
+		}, nil)
+	case models.NotificationChannelTeams:
+		return n.post(channel.URL, map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  notificationTitle(notification.Event),
+			"title":    notificationTitle(notification.Event),
+			"text":     notification.Text,
+		}, nil)
+	case models.NotificationChannelWebhook:
+		return n.postWebhook(channel, notification)
+	}
+	return fmt.Errorf("unknown channel kind %q", channel.Kind)
+}
+
+// sendEmail emails the recipient with the template of the event. Events without an email
+// template are only delivered to chat and webhook channels.
+func (n *notifier) sendEmail(notification *Notification) error {
+	to, name, data := notification.RecipientEmail, notification.RecipientName, notification.Data
+	switch notification.Event {
+	case models.NotificationUserInvited:
+		return n.emailSvc.SendInvitation(to, name, data.OrganisationName)
+	case models.NotificationMatchCreated:
+		return n.emailSvc.SendMatchCreated(to, name, data.ParticipantNames, data.SharedTags, data.MeetingTime, data.AcceptURL, data.RejectURL)
+	case models.NotificationAcceptanceReminder:
+		return n.emailSvc.SendAcceptanceReminder(to, name, data.ParticipantNames, data.MeetingTime, data.AcceptURL, data.RejectURL)
+	case models.NotificationPartnerAccepted:
+		return n.emailSvc.SendMatchAccepted(to, name, data.PartnerName, data.PartnerEmail, data.MeetingTime, data.AvailabilitySlots)
+	case models.NotificationMatchConfirmed:
+		return n.emailSvc.SendMatchConfirmed(to, name, data.ParticipantNames, data.MeetingTime, data.Invite)
+	case models.NotificationMatchCancelled:
+		return n.emailSvc.SendMatchCancelled(to, name, data.MeetingTime, data.Invite)
+	case models.NotificationRescheduleProposed:
+		return n.emailSvc.SendRescheduleProposed(to, name, data.PartnerName, data.Slots, data.Counter)
+	case models.NotificationRescheduleAccepted:
+		return n.emailSvc.SendRescheduleAccepted(to, name, data.PartnerName, data.MeetingTime, data.Invite)
+	case models.NotificationFeedbackReminder:
+		return n.emailSvc.SendFeedbackReminder(to, name, data.ParticipantNames, data.MeetingTime, data.FeedbackDeadline)
+	}
+	return nil
+}
+
+// postWebhook sends the whole notification as JSON. With a secret, the body is signed in
+// the X-Cuppa-Signature header as "sha256=" and the hex HMAC-SHA256 of the body.
+func (n *notifier) postWebhook(channel *models.NotificationChannel, notification *Notification) error {
+	body, err := json.Marshal(notification.withoutActionLinks())
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{"X-Cuppa-Event": string(notification.Event)}
+	if channel.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(channel.Secret))
+		mac.Write(body)
+		headers["X-Cuppa-Signature"] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return n.postBody(channel.URL, body, headers)
+}
+
+func (n *notifier) post(url string, payload interface{}, headers map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return n.postBody(url, body, headers)
+}
+
+func (n *notifier) postBody(url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// notificationTitle is the heading of an event in chat channels
+func notificationTitle(event models.NotificationEvent) string {
+	switch event {
+	case models.NotificationUserInvited:
+		return "New colleague invited"
+	case models.NotificationMatchCreated:
+		return "New cuppa"
+	case models.NotificationAcceptanceReminder:
+		return "Cuppa waiting for an answer"
+	case models.NotificationPartnerAccepted:
+		return "Cuppa accepted"
+	case models.NotificationMatchConfirmed:
+		return "Cuppa confirmed"
+	case models.NotificationMatchCancelled:
+		return "Cuppa cancelled"
+	case models.NotificationRescheduleProposed:
+		return "New times proposed"
+	case models.NotificationRescheduleAccepted:
+		return "Cuppa rescheduled"
+	case models.NotificationFeedbackReminder:
+		return "Feedback reminder"
+	}
+	return "Virtual Cuppa"
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+// capturedRequest is a request received by a test webhook endpoint
+type capturedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookServer starts an endpoint that records each request and answers with the status
+func newWebhookServer(t *testing.T, status int) (*httptest.Server, *[]capturedRequest) {
+	t.Helper()
+	var requests []capturedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		requests = append(requests, capturedRequest{header: r.Header.Clone(), body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func testNotification() *Notification {
+	return &Notification{
+		Event:          models.NotificationMatchCreated,
+		OrganisationID: 1,
+		RecipientName:  "Ada",
+		RecipientEmail: "ada@example.com",
+		Text:           "Ada and Grace have a new cuppa",
+		Data: NotificationData{
+			MatchID:          7,
+			ParticipantNames: []string{"Grace"},
+			MeetingTime:      "Monday 9 March at 10:00",
+			AcceptURL:        "https://api.example.com/matches/7/accept?token=secret-accept",
+			RejectURL:        "https://api.example.com/matches/7/reject?token=secret-reject",
+		},
+	}
+}
+
+// deliverTo delivers the test notification through a channel of the kind posting to a
+// server answering 200, and returns the one request it received
+func deliverTo(t *testing.T, channel *models.NotificationChannel) capturedRequest {
+	t.Helper()
+	server, requests := newWebhookServer(t, http.StatusOK)
+	channel.URL = server.URL
+	n := &notifier{client: server.Client()}
+	if err := n.deliver(channel, testNotification()); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(*requests))
+	}
+	request := (*requests)[0]
+	if got := request.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	return request
+}
+
+func TestDeliverSlack(t *testing.T) {
+	request := deliverTo(t, &models.NotificationChannel{Kind: models.NotificationChannelSlack})
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(request.body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	want := map[string]interface{}{"text": "*New cuppa*\nAda and Grace have a new cuppa"}
+	if len(payload) != len(want) || payload["text"] != want["text"] {
+		t.Errorf("payload = %v, want %v", payload, want)
+	}
+}
+
+func TestDeliverTeams(t *testing.T) {
+	request := deliverTo(t, &models.NotificationChannel{Kind: models.NotificationChannelTeams})
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(request.body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	want := map[string]interface{}{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  "New cuppa",
+		"title":    "New cuppa",
+		"text":     "Ada and Grace have a new cuppa",
+	}
+	if len(payload) != len(want) {
+		t.Errorf("payload = %v, want %v", payload, want)
+	}
+	for key, value := range want {
+		if payload[key] != value {
+			t.Errorf("payload[%q] = %v, want %v", key, payload[key], value)
+		}
+	}
+}
+
+func TestDeliverWebhook(t *testing.T) {
+	request := deliverTo(t, &models.NotificationChannel{Kind: models.NotificationChannelWebhook})
+
+	if got := request.header.Get("X-Cuppa-Event"); got != string(models.NotificationMatchCreated) {
+		t.Errorf("X-Cuppa-Event = %q, want %q", got, models.NotificationMatchCreated)
+	}
+	if got := request.header.Get("X-Cuppa-Signature"); got != "" {
+		t.Errorf("X-Cuppa-Signature = %q without a secret, want none", got)
+	}
+
+	var received Notification
+	if err := json.Unmarshal(request.body, &received); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	sent := testNotification()
+	if received.Event != sent.Event || received.RecipientEmail != sent.RecipientEmail || received.Text != sent.Text {
+		t.Errorf("body = %+v, want the notification %+v", received, sent)
+	}
+	if received.Data.MatchID != sent.Data.MatchID || received.Data.MeetingTime != sent.Data.MeetingTime {
+		t.Errorf("data = %+v, want %+v", received.Data, sent.Data)
+	}
+}
+
+func TestDeliverWebhookRedactsActionLinks(t *testing.T) {
+	request := deliverTo(t, &models.NotificationChannel{Kind: models.NotificationChannelWebhook})
+
+	body := string(request.body)
+	for _, leaked := range []string{"acceptUrl", "rejectUrl", "secret-accept", "secret-reject"} {
+		if strings.Contains(body, leaked) {
+			t.Errorf("body contains %q: %s", leaked, body)
+		}
+	}
+}
+
+func TestDeliverWebhookSignsBody(t *testing.T) {
+	secret := "shh"
+	request := deliverTo(t, &models.NotificationChannel{Kind: models.NotificationChannelWebhook, Secret: secret})
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(request.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := request.header.Get("X-Cuppa-Signature"); got != want {
+		t.Errorf("X-Cuppa-Signature = %q, want %q", got, want)
+	}
+}
+
+func TestDeliverFailsOnErrorStatus(t *testing.T) {
+	kinds := []models.NotificationChannelKind{models.NotificationChannelSlack, models.NotificationChannelTeams, models.NotificationChannelWebhook}
+	statuses := []int{http.StatusMultipleChoices, http.StatusBadRequest, http.StatusInternalServerError}
+	for _, kind := range kinds {
+		for _, status := range statuses {
+			server, _ := newWebhookServer(t, status)
+			n := &notifier{client: server.Client()}
+			channel := &models.NotificationChannel{Kind: kind, URL: server.URL}
+			if err := n.deliver(channel, testNotification()); err == nil {
+				t.Errorf("%s answered %d: got no error", kind, status)
+			}
+		}
+	}
+
+	// Anything below 300 is delivered
+	server, _ := newWebhookServer(t, http.StatusNoContent)
+	n := &notifier{client: server.Client()}
+	channel := &models.NotificationChannel{Kind: models.NotificationChannelWebhook, URL: server.URL}
+	if err := n.deliver(channel, testNotification()); err != nil {
+		t.Errorf("answered 204: %v", err)
+	}
+}
+
+type memChannelRepo struct {
+	repositories.NotificationChannelRepository
+	channels []*models.NotificationChannel
+}
+
+func (r *memChannelRepo) FindByOrganisation(organisationID uint) ([]*models.NotificationChannel, error) {
+	return r.channels, nil
+}
+
+type memOutboxRepo struct {
+	repositories.OutboxRepository
+	messages []*models.OutboxMessage
+}
+
+func (r *memOutboxRepo) Create(message *models.OutboxMessage) error {
+	message.ID = uint(len(r.messages) + 1)
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *memOutboxRepo) FindByID(id uint) (*models.OutboxMessage, error) {
+	for _, message := range r.messages {
+		if message.ID == id {
+			return message, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memOutboxRepo) FindByOrganisation(organisationID uint, status models.OutboxStatus, limit int) ([]*models.OutboxMessage, error) {
+	return r.messages, nil
+}
+
+func (r *memOutboxRepo) Requeue(id uint, now time.Time) (bool, error) {
+	return true, nil
+}
+
+// TestEnqueueKeepsRemindersOffSharedChannels checks reminders are only posted to chat and
+// webhook channels that list them, while email channels route every event
+func TestEnqueueKeepsRemindersOffSharedChannels(t *testing.T) {
+	channels := &memChannelRepo{channels: []*models.NotificationChannel{
+		{ID: 1, Kind: models.NotificationChannelEmail},
+		{ID: 2, Kind: models.NotificationChannelSlack},
+		{ID: 3, Kind: models.NotificationChannelTeams},
+		{ID: 4, Kind: models.NotificationChannelWebhook},
+		{ID: 5, Kind: models.NotificationChannelSlack, Events: models.NotificationEventList{models.NotificationAcceptanceReminder}},
+	}}
+	n := &notifier{channelRepo: channels}
+
+	tests := []struct {
+		event models.NotificationEvent
+		want  []uint
+	}{
+		{models.NotificationMatchCreated, []uint{1, 2, 3, 4}},
+		{models.NotificationAcceptanceReminder, []uint{1, 5}},
+		{models.NotificationFeedbackReminder, []uint{1}},
+	}
+	for _, tt := range tests {
+		outbox := &memOutboxRepo{}
+		notification := testNotification()
+		notification.Event = tt.event
+		if err := n.Enqueue(outbox, notification); err != nil {
+			t.Fatalf("%s: %v", tt.event, err)
+		}
+
+		var got []uint
+		for _, message := range outbox.messages {
+			got = append(got, *message.ChannelID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s routed to channels %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
+
+// TestEnqueuePostsGroupEventsOnceToSharedChannels checks a three-person match is emailed to
+// each participant but posted once to each chat and webhook channel
+func TestEnqueuePostsGroupEventsOnceToSharedChannels(t *testing.T) {
+	channels := &memChannelRepo{channels: []*models.NotificationChannel{
+		{ID: 1, Kind: models.NotificationChannelEmail},
+		{ID: 2, Kind: models.NotificationChannelSlack},
+		{ID: 3, Kind: models.NotificationChannelTeams},
+		{ID: 4, Kind: models.NotificationChannelWebhook},
+	}}
+	n := &notifier{channelRepo: channels}
+
+	var notifications []*Notification
+	for _, recipient := range []string{"ada", "grace", "hedy"} {
+		notification := testNotification()
+		notification.RecipientEmail = recipient + "@example.com"
+		notifications = append(notifications, notification)
+	}
+	// Another match's event is posted on its own
+	other := testNotification()
+	other.Data.MatchID = 8
+	notifications = append(notifications, other)
+
+	outbox := &memOutboxRepo{}
+	if err := n.Enqueue(outbox, notifications...); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	var emails []string
+	posts := make(map[uint][]uint)
+	for _, message := range outbox.messages {
+		if message.ChannelKind == models.NotificationChannelEmail {
+			emails = append(emails, message.Recipient)
+			continue
+		}
+		var posted Notification
+		if err := json.Unmarshal(message.Payload, &posted); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		posts[*message.ChannelID] = append(posts[*message.ChannelID], posted.Data.MatchID)
+	}
+	if want := "[ada@example.com grace@example.com hedy@example.com ada@example.com]"; fmt.Sprint(emails) != want {
+		t.Errorf("emails to %v, want %s", emails, want)
+	}
+	for _, channelID := range []uint{2, 3, 4} {
+		if fmt.Sprint(posts[channelID]) != "[7 8]" {
+			t.Errorf("channel %d posted matches %v, want each match once", channelID, posts[channelID])
+		}
+	}
+}
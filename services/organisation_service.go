@@ -1,9 +1,17 @@
 package services
 
 import (
+	"errors"
 	"log"
 	"virtual-cuppa-be/models"
 	"virtual-cuppa-be/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidWorkingHours = errors.New("working hours must end after they start")
+	ErrInvalidTimeZone     = errors.New("invalid time zone")
 )
 
 type OrganisationService interface {
@@ -22,10 +30,24 @@ func NewOrganisationService(orgRepo repositories.OrganisationRepository) Organis
 }
 
 func (s *organisationService) UpsertOrganisation(input *models.UpsertOrganisationInput) (*models.Organisation, error) {
-	organisation := &models.Organisation{
-		ID:         input.ID,
-		Name:       input.Name,
-		CompanyUrl: input.CompanyUrl,
+	if input.TimeZone != "" && !models.IsValidTimeZone(input.TimeZone) {
+		return nil, ErrInvalidTimeZone
+	}
+
+	// Apply the input over the stored organisation, or the defaults of a new one, so omitted
+	// fields keep their value and the working hours are checked as they will be saved
+	organisation := &models.Organisation{ID: input.ID, WorkingHoursStart: 9, WorkingHoursEnd: 17}
+	if input.ID != 0 {
+		existing, err := s.orgRepo.FindByID(input.ID)
+		if err == nil {
+			organisation = existing
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	applyOrganisationInput(organisation, input)
+	if organisation.WorkingHoursEnd <= organisation.WorkingHoursStart {
+		return nil, ErrInvalidWorkingHours
 	}
 
 	if err := s.orgRepo.Upsert(organisation); err != nil {
@@ -37,6 +59,42 @@ func (s *organisationService) UpsertOrganisation(input *models.UpsertOrganisatio
 	return organisation, nil
 }
 
+// applyOrganisationInput copies the fields the input sets onto the organisation
+func applyOrganisationInput(organisation *models.Organisation, input *models.UpsertOrganisationInput) {
+	organisation.Name = input.Name
+	if input.CompanyUrl != "" {
+		organisation.CompanyUrl = input.CompanyUrl
+	}
+	if input.MatchGroupSize != 0 {
+		organisation.MatchGroupSize = input.MatchGroupSize
+	}
+	if input.RematchPolicy != "" {
+		organisation.RematchPolicy = input.RematchPolicy
+	}
+	if input.RematchCooldownDays != 0 {
+		organisation.RematchCooldownDays = input.RematchCooldownDays
+	}
+	if input.WorkingHoursStart != nil {
+		organisation.WorkingHoursStart = *input.WorkingHoursStart
+	}
+	if input.WorkingHoursEnd != nil {
+		organisation.WorkingHoursEnd = *input.WorkingHoursEnd
+	}
+	if input.CuppaDurationMinutes != 0 {
+		organisation.CuppaDurationMinutes = input.CuppaDurationMinutes
+	}
+	// Empty weights ({}) clear them, going back to scoring by tag overlap only
+	if input.ScoringWeights != nil {
+		organisation.ScoringWeights = input.ScoringWeights
+		if len(input.ScoringWeights) == 0 {
+			organisation.ScoringWeights = nil
+		}
+	}
+	if input.TimeZone != "" {
+		organisation.TimeZone = input.TimeZone
+	}
+}
+
 func (s *organisationService) GetOrganisationByID(id uint) (*models.Organisation, error) {
 	return s.orgRepo.FindByID(id)
 }
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+
+	"gorm.io/gorm"
+)
+
+// memUpsertOrgRepo stores one organisation the way the database repository does: Upsert
+// overwrites every field
+type memUpsertOrgRepo struct {
+	repositories.OrganisationRepository
+	org *models.Organisation
+}
+
+func (r *memUpsertOrgRepo) FindByID(id uint) (*models.Organisation, error) {
+	if r.org == nil || r.org.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	stored := *r.org
+	return &stored, nil
+}
+
+func (r *memUpsertOrgRepo) Upsert(organisation *models.Organisation) error {
+	stored := *organisation
+	r.org = &stored
+	return nil
+}
+
+func TestUpsertOrganisationScoringWeights(t *testing.T) {
+	stored := models.ScoringWeights{"tag_overlap": 2, "random": 1}
+	tests := []struct {
+		name    string
+		weights models.ScoringWeights
+		want    models.ScoringWeights
+	}{
+		{"omitted keeps them", nil, stored},
+		{"empty clears them", models.ScoringWeights{}, nil},
+		{"new weights replace them", models.ScoringWeights{"tag_diversity": 1}, models.ScoringWeights{"tag_diversity": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memUpsertOrgRepo{org: &models.Organisation{ID: 1, Name: "Acme", WorkingHoursStart: 9, WorkingHoursEnd: 17, ScoringWeights: stored}}
+			org, err := NewOrganisationService(repo).UpsertOrganisation(&models.UpsertOrganisationInput{ID: 1, Name: "Acme", ScoringWeights: tt.weights})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(org.ScoringWeights) != fmt.Sprint(tt.want) || (tt.want == nil) != (org.ScoringWeights == nil) {
+				t.Errorf("weights = %v, want %v", org.ScoringWeights, tt.want)
+			}
+		})
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestUpsertOrganisationWorkingHours(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     *int
+		end       *int
+		wantErr   error
+		wantStart int
+		wantEnd   int
+	}{
+		{"start only, after the stored end", intPtr(18), nil, ErrInvalidWorkingHours, 0, 0},
+		{"start only, before the stored end", intPtr(8), nil, nil, 8, 17},
+		{"start at midnight", intPtr(0), nil, nil, 0, 17},
+		{"end only, before the stored start", nil, intPtr(9), ErrInvalidWorkingHours, 0, 0},
+		{"both", intPtr(20), intPtr(24), nil, 20, 24},
+		{"neither", nil, nil, nil, 9, 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memUpsertOrgRepo{org: &models.Organisation{ID: 1, Name: "Acme", WorkingHoursStart: 9, WorkingHoursEnd: 17, CuppaDurationMinutes: 45}}
+			s := NewOrganisationService(repo)
+
+			org, err := s.UpsertOrganisation(&models.UpsertOrganisationInput{ID: 1, Name: "Acme", WorkingHoursStart: tt.start, WorkingHoursEnd: tt.end})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if repo.org.WorkingHoursStart != 9 || repo.org.WorkingHoursEnd != 17 {
+					t.Errorf("stored hours changed to %d-%d", repo.org.WorkingHoursStart, repo.org.WorkingHoursEnd)
+				}
+				return
+			}
+			if org.WorkingHoursStart != tt.wantStart || org.WorkingHoursEnd != tt.wantEnd {
+				t.Errorf("hours = %d-%d, want %d-%d", org.WorkingHoursStart, org.WorkingHoursEnd, tt.wantStart, tt.wantEnd)
+			}
+			if org.CuppaDurationMinutes != 45 {
+				t.Errorf("omitted cuppa duration = %d, want the stored 45", org.CuppaDurationMinutes)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+var (
+	ErrOutboxMessageNotFound = errors.New("outbox message not found")
+	ErrOutboxMessageSent     = errors.New("outbox message was already sent")
+	ErrOutboxMessageLeased   = errors.New("outbox message is being delivered")
+	ErrInvalidOutboxStatus   = errors.New("invalid outbox status")
+)
+
+const (
+	// outboxBatchSize is the most messages one delivery run sends
+	outboxBatchSize = 100
+	// outboxMaxAttempts is how many times a message is tried before it is dead-lettered
+	outboxMaxAttempts = 10
+	// outboxBaseBackoff is the wait after the first failure; it doubles with every attempt
+	outboxBaseBackoff = time.Minute
+	// outboxMaxBackoff caps the wait between attempts
+	outboxMaxBackoff = 6 * time.Hour
+	// outboxLease keeps a claimed message from other workers while it is being delivered
+	outboxLease = 5 * time.Minute
+	// outboxRetention is how long sent messages are kept for inspection
+	outboxRetention = 30 * 24 * time.Hour
+	// outboxListLimit is the most messages returned to admins
+	outboxListLimit = 200
+)
+
+type OutboxService interface {
+	// DeliverDue sends every message whose next attempt is due
+	DeliverDue() (sent int, failed int, err error)
+	GetMessages(organisationID uint, status models.OutboxStatus) ([]*models.OutboxMessage, error)
+	GetMessage(organisationID uint, messageID uint) (*models.OutboxMessage, error)
+	// RetryMessage queues a failed or dead message for immediate delivery with fresh attempts.
+	// Messages a worker is delivering can't be retried until its lease runs out.
+	RetryMessage(organisationID uint, messageID uint) (*models.OutboxMessage, error)
+}
+
+type outboxService struct {
+	outboxRepo repositories.OutboxRepository
+	notifier   Notifier
+}
+
+func NewOutboxService(outboxRepo repositories.OutboxRepository, notifier Notifier) OutboxService {
+	return &outboxService{
+		outboxRepo: outboxRepo,
+		notifier:   notifier,
+	}
+}
+
+func (s *outboxService) DeliverDue() (int, int, error) {
+	now := time.Now()
+	if _, err := s.outboxRepo.DeleteSentBefore(now.Add(-outboxRetention)); err != nil {
+		fmt.Printf("ERROR: Failed to purge sent outbox messages: %v\n", err)
+	}
+
+	messages, err := s.outboxRepo.FindDue(now, outboxBatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sent, failed := 0, 0
+	for _, message := range messages {
+		// Each lease starts when its message is claimed; deliveries before it may have been slow
+		claimedAt := time.Now()
+		claimed, err := s.outboxRepo.Claim(message.ID, claimedAt, claimedAt.Add(outboxLease))
+		if err != nil {
+			return sent, failed, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if s.deliver(message) {
+			sent++
+		} else {
+			failed++
+		}
+	}
+	return sent, failed, nil
+}
+
+// deliver makes one attempt at the message and records the outcome: sent, rescheduled with
+// exponential backoff, or dead once it runs out of attempts or its channel is gone
+func (s *outboxService) deliver(message *models.OutboxMessage) bool {
+	err := s.notifier.Deliver(message)
+	now := time.Now()
+	message.Attempts++
+	message.LeasedUntil = nil
+
+	if err == nil {
+		message.Status = models.OutboxStatusSent
+		message.SentAt = &now
+		message.LastError = ""
+	} else {
+		message.LastError = err.Error()
+		if errors.Is(err, ErrChannelGone) || message.Attempts >= outboxMaxAttempts {
+			message.Status = models.OutboxStatusDead
+			fmt.Printf("ERROR: Outbox message %d dead-lettered after %d attempts: %v\n", message.ID, message.Attempts, err)
+		} else {
+			message.NextAttemptAt = now.Add(outboxBackoff(message.Attempts))
+		}
+	}
+
+	if updateErr := s.outboxRepo.Update(message); updateErr != nil {
+		fmt.Printf("ERROR: Failed to record delivery of outbox message %d: %v\n", message.ID, updateErr)
+	}
+	return err == nil
+}
+
+// outboxBackoff is the wait after the given number of failed attempts
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return backoff
+}
+
+func (s *outboxService) GetMessages(organisationID uint, status models.OutboxStatus) ([]*models.OutboxMessage, error) {
+	if status != "" && !status.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOutboxStatus, status)
+	}
+	messages, err := s.outboxRepo.FindByOrganisation(organisationID, status, outboxListLimit)
+	if err != nil {
+		return nil, err
+	}
+	redacted := make([]*models.OutboxMessage, len(messages))
+	for i, message := range messages {
+		redacted[i] = redactActionLinks(message)
+	}
+	return redacted, nil
+}
+
+func (s *outboxService) GetMessage(organisationID uint, messageID uint) (*models.OutboxMessage, error) {
+	message, err := s.outboxRepo.FindByID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil || message.OrganisationID != organisationID {
+		return nil, ErrOutboxMessageNotFound
+	}
+	return redactActionLinks(message), nil
+}
+
+func (s *outboxService) RetryMessage(organisationID uint, messageID uint) (*models.OutboxMessage, error) {
+	message, err := s.GetMessage(organisationID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.Status == models.OutboxStatusSent {
+		return nil, ErrOutboxMessageSent
+	}
+
+	// Requeue in one conditional update, so a worker claiming the message meanwhile wins
+	requeued, err := s.outboxRepo.Requeue(message.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !requeued {
+		return nil, ErrOutboxMessageLeased
+	}
+	requeuedMessage, err := s.outboxRepo.FindByID(message.ID)
+	if err != nil {
+		return nil, err
+	}
+	return redactActionLinks(requeuedMessage), nil
+}
+
+// redactActionLinks returns a copy of the message for admins, without the signed accept and
+// reject links in its payload: anyone holding them could act on the match as the recipient
+func redactActionLinks(message *models.OutboxMessage) *models.OutboxMessage {
+	if message == nil {
+		return nil
+	}
+	var notification Notification
+	if err := json.Unmarshal(message.Payload, &notification); err != nil {
+		return message
+	}
+	payload, err := json.Marshal(notification.withoutActionLinks())
+	if err != nil {
+		return message
+	}
+	redacted := *message
+	redacted.Payload = payload
+	return &redacted
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"virtual-cuppa-be/models"
+)
+
+// TestOutboxAdminViewsRedactActionLinks checks admins can inspect queued match emails
+// without seeing the signed links that act on the match as the recipient, while the queued
+// message keeps them for delivery
+func TestOutboxAdminViewsRedactActionLinks(t *testing.T) {
+	outbox := &memOutboxRepo{}
+	n := &notifier{channelRepo: &memChannelRepo{}}
+	if err := n.Enqueue(outbox, testNotification()); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	s := &outboxService{outboxRepo: outbox, notifier: n}
+
+	messages, err := s.GetMessages(1, "")
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("messages = %v, %v; want one", messages, err)
+	}
+	message, err := s.GetMessage(1, messages[0].ID)
+	if err != nil {
+		t.Fatalf("get message: %v", err)
+	}
+	outbox.messages[0].Status = models.OutboxStatusDead
+	retried, err := s.RetryMessage(1, messages[0].ID)
+	if err != nil {
+		t.Fatalf("retry message: %v", err)
+	}
+
+	for name, shown := range map[string]*models.OutboxMessage{"list": messages[0], "message": message, "retry": retried} {
+		payload := string(shown.Payload)
+		for _, leaked := range []string{"acceptUrl", "rejectUrl", "secret-accept", "secret-reject"} {
+			if strings.Contains(payload, leaked) {
+				t.Errorf("%s payload contains %q: %s", name, leaked, payload)
+			}
+		}
+		if !strings.Contains(payload, "Ada and Grace have a new cuppa") {
+			t.Errorf("%s payload lost the notification: %s", name, payload)
+		}
+	}
+	if !strings.Contains(string(outbox.messages[0].Payload), "secret-accept") {
+		t.Error("queued message lost its accept link")
+	}
+}
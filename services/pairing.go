@@ -0,0 +1,596 @@
+package services
+
+// weightedEdge is an undirected edge between two vertices of the pairing graph
+type weightedEdge struct {
+	u      int
+	v      int
+	weight int64
+}
+
+// maxWeightMatching computes a maximum-weight matching of a general graph using
+// Edmonds' blossom algorithm with primal-dual weight adjustments (O(n^3)).
+// When maxCardinality is true the result is a maximum-cardinality matching and,
+// among those, the one with the largest total weight.
+//
+// Vertices are numbered 0..n-1. The returned slice maps every vertex to its mate,
+// or -1 when the vertex is left unmatched.
+//
+// This is a port of Joris van Rantwijk's reference implementation. Weights must be
+// integers so that all dual variables stay exact.
+func maxWeightMatching(edges []weightedEdge, maxCardinality bool) []int {
+	if len(edges) == 0 {
+		return nil
+	}
+
+	nedge := len(edges)
+	nvertex := 0
+	var maxWeight int64
+	for _, e := range edges {
+		if e.u >= nvertex {
+			nvertex = e.u + 1
+		}
+		if e.v >= nvertex {
+			nvertex = e.v + 1
+		}
+		if e.weight > maxWeight {
+			maxWeight = e.weight
+		}
+	}
+
+	// endpoint[p] is the vertex at endpoint p; edge k has endpoints 2k and 2k+1
+	endpoint := make([]int, 2*nedge)
+	for k, e := range edges {
+		endpoint[2*k] = e.u
+		endpoint[2*k+1] = e.v
+	}
+
+	// neighbend[v] lists the remote endpoints of edges incident to v
+	neighbend := make([][]int, nvertex)
+	for k, e := range edges {
+		neighbend[e.u] = append(neighbend[e.u], 2*k+1)
+		neighbend[e.v] = append(neighbend[e.v], 2*k)
+	}
+
+	mate := make([]int, nvertex)
+	for i := range mate {
+		mate[i] = -1
+	}
+
+	label := make([]int, 2*nvertex)
+	labelEnd := make([]int, 2*nvertex)
+	inBlossom := make([]int, nvertex)
+	blossomParent := make([]int, 2*nvertex)
+	blossomChilds := make([][]int, 2*nvertex)
+	blossomBase := make([]int, 2*nvertex)
+	blossomEndps := make([][]int, 2*nvertex)
+	bestEdge := make([]int, 2*nvertex)
+	blossomBestEdges := make([][]int, 2*nvertex)
+	dualVar := make([]int64, 2*nvertex)
+	allowEdge := make([]bool, nedge)
+	var unusedBlossoms []int
+	var queue []int
+
+	for i := 0; i < 2*nvertex; i++ {
+		labelEnd[i] = -1
+		blossomParent[i] = -1
+		bestEdge[i] = -1
+		if i < nvertex {
+			inBlossom[i] = i
+			blossomBase[i] = i
+			dualVar[i] = maxWeight
+		} else {
+			blossomBase[i] = -1
+			unusedBlossoms = append(unusedBlossoms, i)
+		}
+	}
+
+	slack := func(k int) int64 {
+		e := edges[k]
+		return dualVar[e.u] + dualVar[e.v] - 2*e.weight
+	}
+
+	var blossomLeaves func(b int) []int
+	blossomLeaves = func(b int) []int {
+		if b < nvertex {
+			return []int{b}
+		}
+		var leaves []int
+		for _, t := range blossomChilds[b] {
+			if t < nvertex {
+				leaves = append(leaves, t)
+			} else {
+				leaves = append(leaves, blossomLeaves(t)...)
+			}
+		}
+		return leaves
+	}
+
+	indexOf := func(list []int, value int) int {
+		for i, x := range list {
+			if x == value {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// at resolves negative indices the same way Python slicing does
+	at := func(list []int, i int) int {
+		if i < 0 {
+			i += len(list)
+		}
+		return list[i]
+	}
+
+	var assignLabel func(w, t, p int)
+	assignLabel = func(w, t, p int) {
+		b := inBlossom[w]
+		label[w], label[b] = t, t
+		labelEnd[w], labelEnd[b] = p, p
+		bestEdge[w], bestEdge[b] = -1, -1
+		if t == 1 {
+			// b became an S-blossom; scan all of its vertices
+			queue = append(queue, blossomLeaves(b)...)
+		} else if t == 2 {
+			// b became a T-blossom; its mate becomes an S-vertex
+			base := blossomBase[b]
+			assignLabel(endpoint[mate[base]], 1, mate[base]^1)
+		}
+	}
+
+	// scanBlossom traces back from v and w to find a new blossom or an augmenting path
+	scanBlossom := func(v, w int) int {
+		var path []int
+		base := -1
+		for v != -1 || w != -1 {
+			b := inBlossom[v]
+			if label[b]&4 != 0 {
+				base = blossomBase[b]
+				break
+			}
+			path = append(path, b)
+			label[b] = 5
+			if labelEnd[b] == -1 {
+				v = -1
+			} else {
+				v = endpoint[labelEnd[b]]
+				b = inBlossom[v]
+				v = endpoint[labelEnd[b]]
+			}
+			if w != -1 {
+				v, w = w, v
+			}
+		}
+		for _, b := range path {
+			label[b] = 1
+		}
+		return base
+	}
+
+	addBlossom := func(base, k int) {
+		v, w := edges[k].u, edges[k].v
+		bb := inBlossom[base]
+		bv := inBlossom[v]
+		bw := inBlossom[w]
+
+		b := unusedBlossoms[len(unusedBlossoms)-1]
+		unusedBlossoms = unusedBlossoms[:len(unusedBlossoms)-1]
+
+		blossomBase[b] = base
+		blossomParent[b] = -1
+		blossomParent[bb] = b
+
+		var path, endps []int
+		for bv != bb {
+			blossomParent[bv] = b
+			path = append(path, bv)
+			endps = append(endps, labelEnd[bv])
+			v = endpoint[labelEnd[bv]]
+			bv = inBlossom[v]
+		}
+		path = append(path, bb)
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		for i, j := 0, len(endps)-1; i < j; i, j = i+1, j-1 {
+			endps[i], endps[j] = endps[j], endps[i]
+		}
+		endps = append(endps, 2*k)
+		for bw != bb {
+			blossomParent[bw] = b
+			path = append(path, bw)
+			endps = append(endps, labelEnd[bw]^1)
+			w = endpoint[labelEnd[bw]]
+			bw = inBlossom[w]
+		}
+		blossomChilds[b] = path
+		blossomEndps[b] = endps
+
+		label[b] = 1
+		labelEnd[b] = labelEnd[bb]
+		dualVar[b] = 0
+
+		for _, leaf := range blossomLeaves(b) {
+			if label[inBlossom[leaf]] == 2 {
+				// former T-vertices inside the new blossom become S-vertices
+				queue = append(queue, leaf)
+			}
+			inBlossom[leaf] = b
+		}
+
+		// compute the least-slack edges from the new blossom to each neighbouring S-blossom
+		bestEdgeTo := make([]int, 2*nvertex)
+		for i := range bestEdgeTo {
+			bestEdgeTo[i] = -1
+		}
+		for _, child := range path {
+			var nbLists [][]int
+			if blossomBestEdges[child] == nil {
+				for _, leaf := range blossomLeaves(child) {
+					list := make([]int, 0, len(neighbend[leaf]))
+					for _, p := range neighbend[leaf] {
+						list = append(list, p/2)
+					}
+					nbLists = append(nbLists, list)
+				}
+			} else {
+				nbLists = [][]int{blossomBestEdges[child]}
+			}
+			for _, nbList := range nbLists {
+				for _, ek := range nbList {
+					j := edges[ek].v
+					if inBlossom[j] == b {
+						j = edges[ek].u
+					}
+					bj := inBlossom[j]
+					if bj != b && label[bj] == 1 && (bestEdgeTo[bj] == -1 || slack(ek) < slack(bestEdgeTo[bj])) {
+						bestEdgeTo[bj] = ek
+					}
+				}
+			}
+			blossomBestEdges[child] = nil
+			bestEdge[child] = -1
+		}
+
+		var best []int
+		for _, ek := range bestEdgeTo {
+			if ek != -1 {
+				best = append(best, ek)
+			}
+		}
+		blossomBestEdges[b] = best
+		bestEdge[b] = -1
+		for _, ek := range best {
+			if bestEdge[b] == -1 || slack(ek) < slack(bestEdge[b]) {
+				bestEdge[b] = ek
+			}
+		}
+	}
+
+	var expandBlossom func(b int, endStage bool)
+	expandBlossom = func(b int, endStage bool) {
+		for _, s := range blossomChilds[b] {
+			blossomParent[s] = -1
+			if s < nvertex {
+				inBlossom[s] = s
+			} else if endStage && dualVar[s] == 0 {
+				expandBlossom(s, endStage)
+			} else {
+				for _, leaf := range blossomLeaves(s) {
+					inBlossom[leaf] = s
+				}
+			}
+		}
+
+		if !endStage && label[b] == 2 {
+			// relabel the sub-blossoms on the path from the entry child to the base
+			childs := blossomChilds[b]
+			endps := blossomEndps[b]
+			entryChild := inBlossom[endpoint[labelEnd[b]^1]]
+			j := indexOf(childs, entryChild)
+			var jstep, endptrick int
+			if j&1 != 0 {
+				j -= len(childs)
+				jstep = 1
+				endptrick = 0
+			} else {
+				jstep = -1
+				endptrick = 1
+			}
+			p := labelEnd[b]
+			for j != 0 {
+				label[endpoint[p^1]] = 0
+				label[endpoint[at(endps, j-endptrick)^endptrick^1]] = 0
+				assignLabel(endpoint[p^1], 2, p)
+				allowEdge[at(endps, j-endptrick)/2] = true
+				j += jstep
+				p = at(endps, j-endptrick) ^ endptrick
+				allowEdge[p/2] = true
+				j += jstep
+			}
+			bv := at(childs, j)
+			label[endpoint[p^1]], label[bv] = 2, 2
+			labelEnd[endpoint[p^1]], labelEnd[bv] = p, p
+			bestEdge[bv] = -1
+			j += jstep
+			for at(childs, j) != entryChild {
+				bv = at(childs, j)
+				if label[bv] == 1 {
+					j += jstep
+					continue
+				}
+				labelled := -1
+				for _, leaf := range blossomLeaves(bv) {
+					if label[leaf] != 0 {
+						labelled = leaf
+						break
+					}
+				}
+				if labelled != -1 {
+					label[labelled] = 0
+					label[endpoint[mate[blossomBase[bv]]]] = 0
+					assignLabel(labelled, 2, labelEnd[labelled])
+				}
+				j += jstep
+			}
+		}
+
+		label[b], labelEnd[b] = -1, -1
+		blossomChilds[b], blossomEndps[b] = nil, nil
+		blossomBase[b] = -1
+		blossomBestEdges[b] = nil
+		bestEdge[b] = -1
+		unusedBlossoms = append(unusedBlossoms, b)
+	}
+
+	var augmentBlossom func(b, v int)
+	augmentBlossom = func(b, v int) {
+		t := v
+		for blossomParent[t] != b {
+			t = blossomParent[t]
+		}
+		if t >= nvertex {
+			augmentBlossom(t, v)
+		}
+		childs := blossomChilds[b]
+		endps := blossomEndps[b]
+		i := indexOf(childs, t)
+		j := i
+		var jstep, endptrick int
+		if i&1 != 0 {
+			j -= len(childs)
+			jstep = 1
+			endptrick = 0
+		} else {
+			jstep = -1
+			endptrick = 1
+		}
+		for j != 0 {
+			j += jstep
+			t = at(childs, j)
+			p := at(endps, j-endptrick) ^ endptrick
+			if t >= nvertex {
+				augmentBlossom(t, endpoint[p])
+			}
+			j += jstep
+			t = at(childs, j)
+			if t >= nvertex {
+				augmentBlossom(t, endpoint[p^1])
+			}
+			mate[endpoint[p]] = p ^ 1
+			mate[endpoint[p^1]] = p
+		}
+		// rotate so that the new base is the first child
+		blossomChilds[b] = append(append([]int{}, childs[i:]...), childs[:i]...)
+		blossomEndps[b] = append(append([]int{}, endps[i:]...), endps[:i]...)
+		blossomBase[b] = blossomBase[blossomChilds[b][0]]
+	}
+
+	augmentMatching := func(k int) {
+		v, w := edges[k].u, edges[k].v
+		for _, start := range [2][2]int{{v, 2*k + 1}, {w, 2 * k}} {
+			s, p := start[0], start[1]
+			for {
+				bs := inBlossom[s]
+				if bs >= nvertex {
+					augmentBlossom(bs, s)
+				}
+				mate[s] = p
+				if labelEnd[bs] == -1 {
+					break
+				}
+				t := endpoint[labelEnd[bs]]
+				bt := inBlossom[t]
+				s = endpoint[labelEnd[bt]]
+				j := endpoint[labelEnd[bt]^1]
+				if bt >= nvertex {
+					augmentBlossom(bt, j)
+				}
+				mate[j] = labelEnd[bt]
+				p = labelEnd[bt] ^ 1
+			}
+		}
+	}
+
+	for stage := 0; stage < nvertex; stage++ {
+		for i := range label {
+			label[i] = 0
+			bestEdge[i] = -1
+		}
+		for i := nvertex; i < 2*nvertex; i++ {
+			blossomBestEdges[i] = nil
+		}
+		for i := range allowEdge {
+			allowEdge[i] = false
+		}
+		queue = queue[:0]
+
+		for v := 0; v < nvertex; v++ {
+			if mate[v] == -1 && label[inBlossom[v]] == 0 {
+				assignLabel(v, 1, -1)
+			}
+		}
+
+		augmented := false
+		for {
+			for len(queue) > 0 && !augmented {
+				v := queue[len(queue)-1]
+				queue = queue[:len(queue)-1]
+
+				for _, p := range neighbend[v] {
+					k := p / 2
+					w := endpoint[p]
+					if inBlossom[v] == inBlossom[w] {
+						continue
+					}
+					var kslack int64
+					if !allowEdge[k] {
+						kslack = slack(k)
+						if kslack <= 0 {
+							allowEdge[k] = true
+						}
+					}
+					if allowEdge[k] {
+						if label[inBlossom[w]] == 0 {
+							assignLabel(w, 2, p^1)
+						} else if label[inBlossom[w]] == 1 {
+							base := scanBlossom(v, w)
+							if base >= 0 {
+								addBlossom(base, k)
+							} else {
+								augmentMatching(k)
+								augmented = true
+								break
+							}
+						} else if label[w] == 0 {
+							label[w] = 2
+							labelEnd[w] = p ^ 1
+						}
+					} else if label[inBlossom[w]] == 1 {
+						b := inBlossom[v]
+						if bestEdge[b] == -1 || kslack < slack(bestEdge[b]) {
+							bestEdge[b] = k
+						}
+					} else if label[w] == 0 {
+						if bestEdge[w] == -1 || kslack < slack(bestEdge[w]) {
+							bestEdge[w] = k
+						}
+					}
+				}
+			}
+
+			if augmented {
+				break
+			}
+
+			// no augmenting path yet; compute the dual adjustment
+			deltaType := -1
+			var delta int64
+			deltaEdge, deltaBlossom := -1, -1
+
+			if !maxCardinality {
+				deltaType = 1
+				delta = minDual(dualVar[:nvertex])
+			}
+			for v := 0; v < nvertex; v++ {
+				if label[inBlossom[v]] == 0 && bestEdge[v] != -1 {
+					d := slack(bestEdge[v])
+					if deltaType == -1 || d < delta {
+						delta = d
+						deltaType = 2
+						deltaEdge = bestEdge[v]
+					}
+				}
+			}
+			for b := 0; b < 2*nvertex; b++ {
+				if blossomParent[b] == -1 && label[b] == 1 && bestEdge[b] != -1 {
+					d := slack(bestEdge[b]) / 2
+					if deltaType == -1 || d < delta {
+						delta = d
+						deltaType = 3
+						deltaEdge = bestEdge[b]
+					}
+				}
+			}
+			for b := nvertex; b < 2*nvertex; b++ {
+				if blossomBase[b] >= 0 && blossomParent[b] == -1 && label[b] == 2 &&
+					(deltaType == -1 || dualVar[b] < delta) {
+					delta = dualVar[b]
+					deltaType = 4
+					deltaBlossom = b
+				}
+			}
+			if deltaType == -1 {
+				// only reachable in max-cardinality mode: no further improvement is possible
+				deltaType = 1
+				delta = minDual(dualVar[:nvertex])
+				if delta < 0 {
+					delta = 0
+				}
+			}
+
+			for v := 0; v < nvertex; v++ {
+				switch label[inBlossom[v]] {
+				case 1:
+					dualVar[v] -= delta
+				case 2:
+					dualVar[v] += delta
+				}
+			}
+			for b := nvertex; b < 2*nvertex; b++ {
+				if blossomBase[b] >= 0 && blossomParent[b] == -1 {
+					switch label[b] {
+					case 1:
+						dualVar[b] += delta
+					case 2:
+						dualVar[b] -= delta
+					}
+				}
+			}
+
+			if deltaType == 1 {
+				break
+			} else if deltaType == 2 {
+				allowEdge[deltaEdge] = true
+				i := edges[deltaEdge].u
+				if label[inBlossom[i]] == 0 {
+					i = edges[deltaEdge].v
+				}
+				queue = append(queue, i)
+			} else if deltaType == 3 {
+				allowEdge[deltaEdge] = true
+				queue = append(queue, edges[deltaEdge].u)
+			} else if deltaType == 4 {
+				expandBlossom(deltaBlossom, false)
+			}
+		}
+
+		if !augmented {
+			break
+		}
+
+		// expand S-blossoms whose dual dropped to zero at the end of the stage
+		for b := nvertex; b < 2*nvertex; b++ {
+			if blossomParent[b] == -1 && blossomBase[b] >= 0 && label[b] == 1 && dualVar[b] == 0 {
+				expandBlossom(b, true)
+			}
+		}
+	}
+
+	for v := 0; v < nvertex; v++ {
+		if mate[v] >= 0 {
+			mate[v] = endpoint[mate[v]]
+		}
+	}
+	return mate
+}
+
+func minDual(values []int64) int64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// matchingTotals checks mates is a matching over the edges and returns its size and weight
+func matchingTotals(t *testing.T, edges []weightedEdge, mates []int) (int, int64) {
+	t.Helper()
+	weights := make(map[[2]int]int64, len(edges))
+	for _, e := range edges {
+		u, v := e.u, e.v
+		if u > v {
+			u, v = v, u
+		}
+		if w, ok := weights[[2]int{u, v}]; !ok || e.weight > w {
+			weights[[2]int{u, v}] = e.weight
+		}
+	}
+
+	size, total := 0, int64(0)
+	for u, v := range mates {
+		if v == -1 {
+			continue
+		}
+		if v < 0 || v >= len(mates) || mates[v] != u || u == v {
+			t.Fatalf("mates %v: %d and %d are not mated to each other", mates, u, v)
+		}
+		if u > v {
+			continue
+		}
+		w, ok := weights[[2]int{u, v}]
+		if !ok {
+			t.Fatalf("mates %v: %d and %d are not joined by an edge", mates, u, v)
+		}
+		size++
+		total += w
+	}
+	return size, total
+}
+
+// bruteForceMatching returns the size and weight of the best matching by trying every one:
+// the heaviest, or with maxCardinality the heaviest of the largest
+func bruteForceMatching(n int, edges []weightedEdge, maxCardinality bool) (int, int64) {
+	matched := make([]bool, n)
+	bestSize, bestWeight := 0, int64(0)
+	var search func(k int, size int, weight int64)
+	search = func(k int, size int, weight int64) {
+		if k == len(edges) {
+			better := weight > bestWeight
+			if maxCardinality {
+				better = size > bestSize || (size == bestSize && weight > bestWeight)
+			}
+			if better {
+				bestSize, bestWeight = size, weight
+			}
+			return
+		}
+		search(k+1, size, weight)
+		if e := edges[k]; !matched[e.u] && !matched[e.v] {
+			matched[e.u], matched[e.v] = true, true
+			search(k+1, size+1, weight+e.weight)
+			matched[e.u], matched[e.v] = false, false
+		}
+	}
+	search(0, 0, 0)
+	return bestSize, bestWeight
+}
+
+// TestMaxWeightMatchingKnownGraphs runs the cases of the reference implementation's test
+// suite, which cover S- and T-blossoms, nested blossoms and blossom augmentation
+func TestMaxWeightMatchingKnownGraphs(t *testing.T) {
+	tests := []struct {
+		name           string
+		edges          [][3]int64
+		maxCardinality bool
+		want           []int
+	}{
+		{"single edge", [][3]int64{{0, 1, 1}}, false, []int{1, 0}},
+		{"path prefers heavy middle", [][3]int64{{1, 2, 10}, {2, 3, 11}}, false, []int{-1, -1, 3, 2}},
+		{"path", [][3]int64{{1, 2, 5}, {2, 3, 11}, {3, 4, 5}}, false, []int{-1, -1, 3, 2, -1}},
+		{"path with max cardinality", [][3]int64{{1, 2, 5}, {2, 3, 11}, {3, 4, 5}}, true, []int{-1, 2, 1, 4, 3}},
+		{"S-blossom", [][3]int64{{1, 2, 8}, {1, 3, 9}, {2, 3, 10}, {3, 4, 7}}, false, []int{-1, 2, 1, 4, 3}},
+		{"S-blossom augmented", [][3]int64{{1, 2, 8}, {1, 3, 9}, {2, 3, 10}, {3, 4, 7}, {1, 6, 5}, {4, 5, 6}}, false, []int{-1, 6, 3, 2, 5, 4, 1}},
+		{"T-blossom", [][3]int64{{1, 2, 9}, {1, 3, 8}, {2, 3, 10}, {1, 4, 5}, {4, 5, 4}, {1, 6, 3}}, false, []int{-1, 6, 3, 2, 5, 4, 1}},
+		{"nested S-blossom", [][3]int64{{1, 2, 9}, {1, 3, 9}, {2, 3, 10}, {2, 4, 8}, {3, 5, 8}, {4, 5, 10}, {5, 6, 6}}, false, []int{-1, 3, 4, 1, 2, 6, 5}},
+		{"relabelled nested S-blossom", [][3]int64{{1, 2, 10}, {1, 7, 10}, {2, 3, 12}, {3, 4, 20}, {3, 5, 20}, {4, 5, 25}, {5, 6, 10}, {6, 7, 10}, {7, 8, 8}}, false, []int{-1, 2, 1, 4, 3, 6, 5, 8, 7}},
+		{"augment blossom", [][3]int64{{1, 2, 45}, {1, 5, 45}, {2, 3, 50}, {3, 4, 45}, {4, 5, 50}, {1, 6, 30}, {3, 9, 35}, {4, 8, 35}, {5, 7, 26}, {9, 10, 5}}, false, []int{-1, 6, 3, 2, 8, 7, 1, 5, 4, 10, 9}},
+		{"triangle", [][3]int64{{0, 1, 1}, {1, 2, 1}, {0, 2, 1}}, true, nil},
+		{"five-cycle with a tail", [][3]int64{{0, 1, 1}, {1, 2, 1}, {2, 3, 1}, {3, 4, 1}, {4, 0, 1}, {4, 5, 1}}, true, []int{1, 0, 3, 2, 5, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edges := make([]weightedEdge, len(tt.edges))
+			for i, e := range tt.edges {
+				edges[i] = weightedEdge{u: int(e[0]), v: int(e[1]), weight: e[2]}
+			}
+			mates := maxWeightMatching(edges, tt.maxCardinality)
+			size, _ := matchingTotals(t, edges, mates)
+			if tt.want == nil {
+				// Any one edge of a triangle is optimal
+				if size != 1 {
+					t.Errorf("mates = %v, want one pair", mates)
+				}
+				return
+			}
+			if fmt.Sprint(mates) != fmt.Sprint(tt.want) {
+				t.Errorf("mates = %v, want %v", mates, tt.want)
+			}
+		})
+	}
+}
+
+// TestMaxWeightMatchingAgainstBruteForce compares random small graphs, dense enough to be
+// full of odd cycles, with an exhaustive search
+func TestMaxWeightMatchingAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		n := 2 + rng.Intn(9)
+		density := 0.2 + 0.7*rng.Float64()
+		maxWeight := int64(1)
+		if rng.Intn(4) > 0 {
+			maxWeight = 1 + rng.Int63n(30)
+		}
+
+		var edges []weightedEdge
+		for u := 0; u < n; u++ {
+			for v := u + 1; v < n; v++ {
+				if rng.Float64() < density {
+					edges = append(edges, weightedEdge{u: u, v: v, weight: rng.Int63n(maxWeight + 1)})
+				}
+			}
+		}
+		if len(edges) == 0 || len(edges) > 22 {
+			continue
+		}
+
+		for _, maxCardinality := range []bool{false, true} {
+			mates := maxWeightMatching(edges, maxCardinality)
+			size, weight := matchingTotals(t, edges, mates)
+			wantSize, wantWeight := bruteForceMatching(n, edges, maxCardinality)
+			if weight != wantWeight || (maxCardinality && size != wantSize) {
+				t.Fatalf("graph %d %v (maxCardinality %v): got %d pairs weighing %d, want %d weighing %d",
+					i, edges, maxCardinality, size, weight, wantSize, wantWeight)
+			}
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+// ErrReminderAfterExpiry is returned for an acceptance reminder timed at or before the
+// moment pending matches are created, which would never reach a participant in time
+var ErrReminderAfterExpiry = errors.New("acceptance reminders must be sent within the acceptance window")
+
+type ReminderService interface {
+	// SendDueReminders queues the acceptance and feedback reminders that are due under each
+	// organisation's policy and returns how many were queued
+	SendDueReminders() (int, error)
+	// GetPolicy returns the organisation's reminder policy, or the default one if it has not
+	// set one
+	GetPolicy(organisationID uint) (*models.ReminderPolicy, error)
+	UpdatePolicy(organisationID uint, input *models.UpdateReminderPolicyInput) (*models.ReminderPolicy, error)
+}
+
+type reminderService struct {
+	policyRepo   repositories.ReminderPolicyRepository
+	reminderRepo repositories.MatchReminderRepository
+	matchRepo    repositories.MatchRepository
+	orgRepo      repositories.OrganisationRepository
+	uow          repositories.UnitOfWork
+	notifier     Notifier
+	apiURL       string
+	// acceptanceWindow is how long a pending match waits for answers before it expires
+	acceptanceWindow time.Duration
+}
+
+func NewReminderService(
+	policyRepo repositories.ReminderPolicyRepository,
+	reminderRepo repositories.MatchReminderRepository,
+	matchRepo repositories.MatchRepository,
+	orgRepo repositories.OrganisationRepository,
+	uow repositories.UnitOfWork,
+	notifier Notifier,
+	acceptanceWindow time.Duration,
+) ReminderService {
+	return &reminderService{
+		policyRepo:       policyRepo,
+		reminderRepo:     reminderRepo,
+		matchRepo:        matchRepo,
+		orgRepo:          orgRepo,
+		uow:              uow,
+		notifier:         notifier,
+		apiURL:           strings.TrimRight(os.Getenv("API_URL"), "/"),
+		acceptanceWindow: acceptanceWindow,
+	}
+}
+
+func (s *reminderService) GetPolicy(organisationID uint) (*models.ReminderPolicy, error) {
+	policy, err := s.policyRepo.FindByOrganisation(organisationID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return models.DefaultReminderPolicy(organisationID), nil
+	}
+	return policy, nil
+}
+
+func (s *reminderService) UpdatePolicy(organisationID uint, input *models.UpdateReminderPolicyInput) (*models.ReminderPolicy, error) {
+	if !s.remindsBeforeExpiry(input.AcceptanceReminderHoursBefore) {
+		return nil, fmt.Errorf("%w: pending matches expire after %s", ErrReminderAfterExpiry, s.acceptanceWindow)
+	}
+
+	policy, err := s.GetPolicy(organisationID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.AcceptanceReminderEnabled = input.AcceptanceReminderEnabled
+	policy.AcceptanceReminderHoursBefore = input.AcceptanceReminderHoursBefore
+	policy.FeedbackReminderEnabled = input.FeedbackReminderEnabled
+	policy.FeedbackReminderHoursBefore = input.FeedbackReminderHoursBefore
+
+	if err := s.policyRepo.Save(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// SendDueReminders runs both campaigns for every organisation. A failing organisation is
+// logged and skipped so it can't hold up the others.
+func (s *reminderService) SendDueReminders() (int, error) {
+	orgs, err := s.orgRepo.FindAll()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	sent := 0
+	for _, org := range orgs {
+		policy, err := s.GetPolicy(org.ID)
+		if err != nil {
+			fmt.Printf("ERROR: Failed to load reminder policy of organisation %d: %v\n", org.ID, err)
+			continue
+		}
+
+		if policy.AcceptanceReminderEnabled {
+			count, err := s.sendAcceptanceReminders(org, policy, now)
+			if err != nil {
+				fmt.Printf("ERROR: Failed to send acceptance reminders for organisation %d: %v\n", org.ID, err)
+			}
+			sent += count
+		}
+		if policy.FeedbackReminderEnabled {
+			count, err := s.sendFeedbackReminders(org, policy, now)
+			if err != nil {
+				fmt.Printf("ERROR: Failed to send feedback reminders for organisation %d: %v\n", org.ID, err)
+			}
+			sent += count
+		}
+	}
+	return sent, nil
+}
+
+// remindsBeforeExpiry reports whether a reminder hoursBefore a pending match expires falls
+// after the match was created
+func (s *reminderService) remindsBeforeExpiry(hoursBefore int) bool {
+	return time.Duration(hoursBefore)*time.Hour < s.acceptanceWindow
+}
+
+// sendAcceptanceReminders nudges participants who still haven't answered a pending match
+// AcceptanceReminderHoursBefore it expires. A policy saved under a longer acceptance window
+// is skipped rather than reminding participants as soon as they are matched.
+func (s *reminderService) sendAcceptanceReminders(org *models.Organisation, policy *models.ReminderPolicy, now time.Time) (int, error) {
+	if !s.remindsBeforeExpiry(policy.AcceptanceReminderHoursBefore) {
+		return 0, ErrReminderAfterExpiry
+	}
+	remindAfter := s.acceptanceWindow - time.Duration(policy.AcceptanceReminderHoursBefore)*time.Hour
+	cutoff := now.Add(-remindAfter)
+	matches, err := s.matchRepo.FindPendingByOrganisationCreatedBefore(org.ID, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	reminded, err := s.remindedParticipants(matches, models.ReminderKindAcceptance)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, match := range matches {
+		for _, p := range match.Participants {
+			if p.Status != models.ParticipantStatusPending || p.User == nil || reminded[reminderKey{match.ID, p.UserID}] {
+				continue
+			}
+
+			acceptURL, err := buildMatchLink(s.apiURL, p.UserID, match.ID, MatchActionAccept)
+			if err != nil {
+				return sent, err
+			}
+			rejectURL, err := buildMatchLink(s.apiURL, p.UserID, match.ID, MatchActionReject)
+			if err != nil {
+				return sent, err
+			}
+
+			others := otherParticipantNames(match, p.UserID)
+			notification := &Notification{
+				Event:          models.NotificationAcceptanceReminder,
+				OrganisationID: match.OrganisationID,
+				RecipientName:  fullName(p.User),
+				RecipientEmail: p.User.Email,
+				Text:           fmt.Sprintf("%s hasn't answered their cuppa with %s yet", fullName(p.User), strings.Join(others, ", ")),
+				Data: NotificationData{
+					MatchID:          match.ID,
+					ParticipantNames: others,
+					MeetingTime:      meetingTimeFor(match, org, p.User),
+					AcceptURL:        acceptURL,
+					RejectURL:        rejectURL,
+				},
+			}
+			if s.remind(match.ID, p.UserID, models.ReminderKindAcceptance, notification, now) {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// sendFeedbackReminders asks participants who haven't given feedback to do so once the
+// match's feedback window closes within FeedbackReminderHoursBefore
+func (s *reminderService) sendFeedbackReminders(org *models.Organisation, policy *models.ReminderPolicy, now time.Time) (int, error) {
+	until := now.Add(time.Duration(policy.FeedbackReminderHoursBefore) * time.Hour)
+	matches, err := s.matchRepo.FindWaitingForFeedbackByOrganisationExpiringBetween(org.ID, now, until)
+	if err != nil {
+		return 0, err
+	}
+	reminded, err := s.remindedParticipants(matches, models.ReminderKindFeedback)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, match := range matches {
+		for _, p := range match.ActiveParticipants() {
+			if p.User == nil || hasGivenFeedback(match, p.UserID) || reminded[reminderKey{match.ID, p.UserID}] {
+				continue
+			}
+
+			others := otherParticipantNames(match, p.UserID)
+			notification := &Notification{
+				Event:          models.NotificationFeedbackReminder,
+				OrganisationID: match.OrganisationID,
+				RecipientName:  fullName(p.User),
+				RecipientEmail: p.User.Email,
+				Text:           fmt.Sprintf("%s hasn't given feedback on their cuppa with %s yet", fullName(p.User), strings.Join(others, ", ")),
+				Data: NotificationData{
+					MatchID:          match.ID,
+					ParticipantNames: others,
+					MeetingTime:      meetingTimeFor(match, org, p.User),
+					FeedbackDeadline: match.ExpiresAt.In(p.User.Location(org)).Format("Monday 2 January, 15:04 MST"),
+				},
+			}
+			if s.remind(match.ID, p.UserID, models.ReminderKindFeedback, notification, now) {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// remind records the reminder and queues its notification in one transaction, so a
+// participant is reminded exactly when the reminder is recorded. The unique index on
+// match_reminders stops a second instance of the scheduler from reminding anyone twice.
+func (s *reminderService) remind(matchID uint, userID uint, kind models.ReminderKind, notification *Notification, now time.Time) bool {
+	err := s.uow.Do(func(repos *repositories.TxRepositories) error {
+		if err := repos.Reminders.Create(&models.MatchReminder{
+			MatchID: matchID,
+			UserID:  userID,
+			Kind:    kind,
+			SentAt:  now,
+		}); err != nil {
+			return err
+		}
+		return s.notifier.Enqueue(repos.Outbox, notification)
+	})
+	if err != nil {
+		fmt.Printf("ERROR: Failed to send %s reminder for match %d to user %d: %v\n", kind, matchID, userID, err)
+		return false
+	}
+	return true
+}
+
+type reminderKey struct {
+	matchID uint
+	userID  uint
+}
+
+// remindedParticipants returns the participants of the matches already sent the reminder
+func (s *reminderService) remindedParticipants(matches []*models.Match, kind models.ReminderKind) (map[reminderKey]bool, error) {
+	matchIDs := make([]uint, len(matches))
+	for i, match := range matches {
+		matchIDs[i] = match.ID
+	}
+	reminders, err := s.reminderRepo.FindByMatchIDs(matchIDs, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	reminded := make(map[reminderKey]bool, len(reminders))
+	for _, reminder := range reminders {
+		reminded[reminderKey{reminder.MatchID, reminder.UserID}] = true
+	}
+	return reminded, nil
+}
+
+// otherParticipantNames lists the active participants of the match other than the user
+func otherParticipantNames(match *models.Match, userID uint) []string {
+	var names []string
+	for _, p := range match.ActiveParticipants() {
+		if p.UserID != userID && p.User != nil {
+			names = append(names, fullName(p.User))
+		}
+	}
+	return names
+}
+
+func hasGivenFeedback(match *models.Match, userID uint) bool {
+	for _, feedback := range match.Feedbacks {
+		if feedback.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
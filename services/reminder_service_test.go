@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+func (r *memOrgRepo) FindAll() ([]*models.Organisation, error) {
+	r.query()
+	return []*models.Organisation{r.org}, nil
+}
+
+type memPolicyRepo struct {
+	repositories.ReminderPolicyRepository
+	policy *models.ReminderPolicy
+}
+
+func (r *memPolicyRepo) FindByOrganisation(organisationID uint) (*models.ReminderPolicy, error) {
+	return r.policy, nil
+}
+
+func (r *memPolicyRepo) Save(policy *models.ReminderPolicy) error {
+	r.policy = policy
+	return nil
+}
+
+// memReminderRepo keeps reminders unique per match, user and kind like idx_match_reminder
+type memReminderRepo struct {
+	repositories.MatchReminderRepository
+	reminders []*models.MatchReminder
+}
+
+func (r *memReminderRepo) Create(reminder *models.MatchReminder) error {
+	for _, sent := range r.reminders {
+		if sent.MatchID == reminder.MatchID && sent.UserID == reminder.UserID && sent.Kind == reminder.Kind {
+			return errors.New("duplicate key value violates unique constraint \"idx_match_reminder\"")
+		}
+	}
+	r.reminders = append(r.reminders, reminder)
+	return nil
+}
+
+func (r *memReminderRepo) FindByMatchIDs(matchIDs []uint, kind models.ReminderKind) ([]*models.MatchReminder, error) {
+	var reminders []*models.MatchReminder
+	for _, reminder := range r.reminders {
+		for _, id := range matchIDs {
+			if reminder.MatchID == id && reminder.Kind == kind {
+				reminders = append(reminders, reminder)
+			}
+		}
+	}
+	return reminders, nil
+}
+
+// memCampaignMatchRepo serves the matches of both campaigns and records the bounds each
+// campaign asked for
+type memCampaignMatchRepo struct {
+	repositories.MatchRepository
+	pending         []*models.Match
+	waiting         []*models.Match
+	pendingCutoff   time.Time
+	feedbackFrom    time.Time
+	feedbackTo      time.Time
+	pendingQueries  int
+	feedbackQueries int
+}
+
+func (r *memCampaignMatchRepo) FindPendingByOrganisationCreatedBefore(organisationID uint, cutoff time.Time) ([]*models.Match, error) {
+	r.pendingQueries++
+	r.pendingCutoff = cutoff
+	return r.pending, nil
+}
+
+func (r *memCampaignMatchRepo) FindWaitingForFeedbackByOrganisationExpiringBetween(organisationID uint, from, to time.Time) ([]*models.Match, error) {
+	r.feedbackQueries++
+	r.feedbackFrom, r.feedbackTo = from, to
+	return r.waiting, nil
+}
+
+func testParticipant(matchID, userID uint, name string, status models.ParticipantStatus) *models.MatchParticipant {
+	return &models.MatchParticipant{
+		MatchID: matchID,
+		UserID:  userID,
+		Status:  status,
+		User:    &models.User{ID: userID, FirstName: name, Email: name + "@example.com"},
+	}
+}
+
+// newReminderService returns a reminder service for organisation 1 under the policy, with a
+// 72 hour acceptance window, whose reminders and notifications go to the returned repos
+func newReminderService(policy *models.ReminderPolicy, matches *memCampaignMatchRepo) (*reminderService, *memReminderRepo, *memOutboxRepo) {
+	reminders := &memReminderRepo{}
+	outbox := &memOutboxRepo{}
+	s := &reminderService{
+		policyRepo:       &memPolicyRepo{policy: policy},
+		reminderRepo:     reminders,
+		matchRepo:        matches,
+		orgRepo:          &memOrgRepo{queryCounter: &queryCounter{}, org: &models.Organisation{ID: 1, TimeZone: "UTC"}},
+		uow:              &memUnitOfWork{repos: &repositories.TxRepositories{Reminders: reminders, Outbox: outbox}},
+		notifier:         &notifier{channelRepo: &memChannelRepo{}},
+		acceptanceWindow: 72 * time.Hour,
+	}
+	return s, reminders, outbox
+}
+
+// TestSendDueRemindersCampaignQueries checks each campaign looks for matches at the time
+// set by the policy, and that a policy falling outside the acceptance window sends nothing
+func TestSendDueRemindersCampaignQueries(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        *models.ReminderPolicy
+		wantAfter     time.Duration
+		wantFeedback  time.Duration
+		wantPending   int
+		wantFeedbacks int
+	}{
+		{"default policy", nil, 48 * time.Hour, 24 * time.Hour, 1, 1},
+		{
+			"custom policy",
+			&models.ReminderPolicy{OrganisationID: 1, AcceptanceReminderEnabled: true, AcceptanceReminderHoursBefore: 6, FeedbackReminderEnabled: true, FeedbackReminderHoursBefore: 48},
+			66 * time.Hour, 48 * time.Hour, 1, 1,
+		},
+		{
+			"saved under a longer acceptance window",
+			&models.ReminderPolicy{OrganisationID: 1, AcceptanceReminderEnabled: true, AcceptanceReminderHoursBefore: 96, FeedbackReminderEnabled: true, FeedbackReminderHoursBefore: 24},
+			0, 24 * time.Hour, 0, 1,
+		},
+		{
+			"campaigns turned off",
+			&models.ReminderPolicy{OrganisationID: 1, AcceptanceReminderHoursBefore: 24, FeedbackReminderHoursBefore: 24},
+			0, 0, 0, 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := &memCampaignMatchRepo{}
+			s, _, _ := newReminderService(tt.policy, matches)
+
+			before := time.Now()
+			if _, err := s.SendDueReminders(); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+			after := time.Now()
+
+			if matches.pendingQueries != tt.wantPending || matches.feedbackQueries != tt.wantFeedbacks {
+				t.Fatalf("queries = %d pending, %d feedback; want %d, %d",
+					matches.pendingQueries, matches.feedbackQueries, tt.wantPending, tt.wantFeedbacks)
+			}
+			if tt.wantPending > 0 {
+				if cutoff := matches.pendingCutoff; cutoff.Before(before.Add(-tt.wantAfter)) || cutoff.After(after.Add(-tt.wantAfter)) {
+					t.Errorf("pending matches created before %v, want %s ago", cutoff, tt.wantAfter)
+				}
+			}
+			if tt.wantFeedbacks > 0 {
+				if from := matches.feedbackFrom; from.Before(before) || from.After(after) {
+					t.Errorf("feedback windows closing from %v, want now", from)
+				}
+				if window := matches.feedbackTo.Sub(matches.feedbackFrom); window != tt.wantFeedback {
+					t.Errorf("feedback windows closing within %s, want %s", window, tt.wantFeedback)
+				}
+			}
+		})
+	}
+}
+
+// TestSendDueRemindersRemindsEachParticipantOnce checks only participants still owing an
+// answer or feedback are reminded, and that later runs don't remind them again
+func TestSendDueRemindersRemindsEachParticipantOnce(t *testing.T) {
+	expiresAt := time.Now().Add(12 * time.Hour)
+	matches := &memCampaignMatchRepo{
+		pending: []*models.Match{{
+			ID:             1,
+			OrganisationID: 1,
+			Status:         models.MatchStatusPending,
+			Participants: []*models.MatchParticipant{
+				testParticipant(1, 1, "ada", models.ParticipantStatusAccepted),
+				testParticipant(1, 2, "grace", models.ParticipantStatusPending),
+				testParticipant(1, 3, "linus", models.ParticipantStatusPending),
+			},
+		}},
+		waiting: []*models.Match{{
+			ID:             2,
+			OrganisationID: 1,
+			Status:         models.MatchStatusWaitingForFeedback,
+			ExpiresAt:      &expiresAt,
+			Participants: []*models.MatchParticipant{
+				testParticipant(2, 1, "ada", models.ParticipantStatusAccepted),
+				testParticipant(2, 4, "barbara", models.ParticipantStatusAccepted),
+				testParticipant(2, 5, "ken", models.ParticipantStatusRejected),
+			},
+			Feedbacks: []*models.MatchFeedback{{MatchID: 2, UserID: 1, Rating: 5}},
+		}},
+	}
+	s, reminders, outbox := newReminderService(nil, matches)
+	// Linus was reminded by an earlier run
+	reminders.reminders = []*models.MatchReminder{{MatchID: 1, UserID: 3, Kind: models.ReminderKindAcceptance}}
+
+	sent, err := s.SendDueReminders()
+	if err != nil || sent != 2 {
+		t.Fatalf("first run sent %d (%v), want 2", sent, err)
+	}
+	want := map[string]models.NotificationEvent{
+		"grace@example.com":   models.NotificationAcceptanceReminder,
+		"barbara@example.com": models.NotificationFeedbackReminder,
+	}
+	if len(outbox.messages) != len(want) {
+		t.Fatalf("outbox messages = %d, want %d", len(outbox.messages), len(want))
+	}
+	for _, message := range outbox.messages {
+		if want[message.Recipient] != message.Event {
+			t.Errorf("queued %s for %s, want %v", message.Event, message.Recipient, want)
+		}
+	}
+	if len(reminders.reminders) != 3 {
+		t.Errorf("reminders recorded = %d, want 3", len(reminders.reminders))
+	}
+
+	if sent, err := s.SendDueReminders(); err != nil || sent != 0 {
+		t.Errorf("second run sent %d (%v), want 0", sent, err)
+	}
+	if len(outbox.messages) != len(want) {
+		t.Errorf("outbox messages after the second run = %d, want %d", len(outbox.messages), len(want))
+	}
+}
+
+// TestSendDueRemindersSkipsRemindersAlreadyRecorded checks a reminder recorded by another
+// scheduler instance since the run read the reminders is neither queued nor counted
+func TestSendDueRemindersSkipsRemindersAlreadyRecorded(t *testing.T) {
+	matches := &memCampaignMatchRepo{pending: []*models.Match{{
+		ID:             1,
+		OrganisationID: 1,
+		Status:         models.MatchStatusPending,
+		Participants:   []*models.MatchParticipant{testParticipant(1, 2, "grace", models.ParticipantStatusPending)},
+	}}}
+	s, _, outbox := newReminderService(nil, matches)
+	// The run reads no reminders, but the insert hits one recorded in the meantime
+	s.reminderRepo = &memReminderRepo{}
+	s.uow.(*memUnitOfWork).repos.Reminders = &memReminderRepo{reminders: []*models.MatchReminder{{MatchID: 1, UserID: 2, Kind: models.ReminderKindAcceptance}}}
+
+	if sent, err := s.SendDueReminders(); err != nil || sent != 0 {
+		t.Errorf("sent %d (%v), want 0", sent, err)
+	}
+	if len(outbox.messages) != 0 {
+		t.Errorf("outbox messages = %d, want none", len(outbox.messages))
+	}
+}
+
+func TestUpdatePolicyKeepsAcceptanceRemindersWithinWindow(t *testing.T) {
+	tests := []struct {
+		hoursBefore int
+		wantErr     bool
+	}{
+		{1, false},
+		{24, false},
+		{71, false},
+		{72, true},
+		{24 * 30, true},
+	}
+	for _, tt := range tests {
+		s, _, _ := newReminderService(nil, &memCampaignMatchRepo{})
+		input := &models.UpdateReminderPolicyInput{
+			AcceptanceReminderEnabled:     true,
+			AcceptanceReminderHoursBefore: tt.hoursBefore,
+			FeedbackReminderEnabled:       true,
+			FeedbackReminderHoursBefore:   24,
+		}
+		policy, err := s.UpdatePolicy(1, input)
+		if tt.wantErr {
+			if !errors.Is(err, ErrReminderAfterExpiry) {
+				t.Errorf("%d hours before: got %v, want %v", tt.hoursBefore, err, ErrReminderAfterExpiry)
+			}
+			continue
+		}
+		if err != nil || policy.AcceptanceReminderHoursBefore != tt.hoursBefore {
+			t.Errorf("%d hours before: got %+v, %v", tt.hoursBefore, policy, err)
+		}
+	}
+}
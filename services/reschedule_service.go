@@ -0,0 +1,366 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+var (
+	ErrMatchNotReschedulable = errors.New("only accepted matches can be rescheduled")
+	ErrInvalidRescheduleSlot = errors.New("proposed slots must be in the future, within working hours and within your availability")
+	ErrReschedulePending     = errors.New("you already have a reschedule proposal waiting for a response")
+	ErrRescheduleNotFound    = errors.New("reschedule proposal not found")
+	ErrRescheduleClosed      = errors.New("reschedule proposal is no longer open")
+	ErrOwnRescheduleProposal = errors.New("you cannot accept your own reschedule proposal")
+	ErrSlotNotProposed       = errors.New("the chosen slot was not proposed")
+)
+
+// rescheduleHorizonDays is how far ahead an accepted cuppa can be moved
+const rescheduleHorizonDays = 30
+
+// RescheduleService lets participants of an accepted match move it to another slot. One
+// participant proposes up to three slots from their availability; another picks one or
+// answers with slots of their own.
+type RescheduleService interface {
+	GetProposals(userID uint, matchID uint) ([]*models.RescheduleProposal, error)
+	ProposeSlots(userID uint, matchID uint, starts []time.Time) (*models.RescheduleProposal, error)
+	AcceptProposal(userID uint, matchID uint, proposalID uint, start time.Time) (*models.Match, error)
+}
+
+type rescheduleService struct {
+	proposalRepo    repositories.RescheduleProposalRepository
+	matchRepo       repositories.MatchRepository
+	availConfigRepo repositories.UserAvailabilityConfigRepository
+	orgRepo         repositories.OrganisationRepository
+	overrideRepo    repositories.AvailabilityOverrideRepository
+	calendarRepo    repositories.HolidayCalendarRepository
+	uow             repositories.UnitOfWork
+	notifier        Notifier
+}
+
+func NewRescheduleService(
+	proposalRepo repositories.RescheduleProposalRepository,
+	matchRepo repositories.MatchRepository,
+	availConfigRepo repositories.UserAvailabilityConfigRepository,
+	orgRepo repositories.OrganisationRepository,
+	overrideRepo repositories.AvailabilityOverrideRepository,
+	calendarRepo repositories.HolidayCalendarRepository,
+	uow repositories.UnitOfWork,
+	notifier Notifier,
+) RescheduleService {
+	return &rescheduleService{
+		proposalRepo:    proposalRepo,
+		matchRepo:       matchRepo,
+		availConfigRepo: availConfigRepo,
+		orgRepo:         orgRepo,
+		overrideRepo:    overrideRepo,
+		calendarRepo:    calendarRepo,
+		uow:             uow,
+		notifier:        notifier,
+	}
+}
+
+func (s *rescheduleService) GetProposals(userID uint, matchID uint) ([]*models.RescheduleProposal, error) {
+	if _, err := s.participantMatch(userID, matchID); err != nil {
+		return nil, err
+	}
+	return s.proposalRepo.FindByMatch(matchID)
+}
+
+func (s *rescheduleService) ProposeSlots(userID uint, matchID uint, starts []time.Time) (*models.RescheduleProposal, error) {
+	match, err := s.participantMatch(userID, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if !isReschedulable(match) {
+		return nil, ErrMatchNotReschedulable
+	}
+	if len(starts) == 0 || len(starts) > models.MaxRescheduleSlots {
+		return nil, ErrInvalidRescheduleSlot
+	}
+
+	org, err := s.orgRepo.FindByID(match.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.availConfigRepo.FindByUserID(userID)
+	if err != nil || config == nil {
+		return nil, ErrInvalidRescheduleSlot
+	}
+
+	proposer := match.Participant(userID).User
+	if proposer == nil {
+		proposer = &models.User{ID: userID}
+	}
+	now := time.Now()
+	free, err := s.freeTimes(org, proposer, config, now)
+	if err != nil {
+		return nil, err
+	}
+	slots := make(models.ProposedSlots, 0, len(starts))
+	seen := make(map[time.Time]bool)
+	for _, start := range starts {
+		start = start.UTC()
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+
+		slot := models.ProposedSlot{Start: start, End: start.Add(org.CuppaDuration())}
+		if !fitsAvailability(free, slot, now) {
+			return nil, ErrInvalidRescheduleSlot
+		}
+		slots = append(slots, slot)
+	}
+
+	proposal := &models.RescheduleProposal{
+		MatchID:      matchID,
+		ProposedByID: userID,
+		Slots:        slots,
+		Status:       models.RescheduleStatusPending,
+	}
+
+	// Counter the open proposal, save the new one and queue its notifications together
+	err = s.uow.Do(func(repos *repositories.TxRepositories) error {
+		// Proposing while another participant's proposal is open answers it with a counter-proposal
+		counter := false
+		pending, err := repos.RescheduleProposals.FindPendingByMatch(matchID)
+		if err != nil {
+			return err
+		}
+		if pending != nil {
+			if pending.ProposedByID == userID {
+				return ErrReschedulePending
+			}
+			pending.Status = models.RescheduleStatusCountered
+			pending.RespondedByID = &userID
+			pending.RespondedAt = &now
+			if err := repos.RescheduleProposals.Update(pending); err != nil {
+				return err
+			}
+			counter = true
+		}
+
+		if err := repos.RescheduleProposals.Create(proposal); err != nil {
+			return err
+		}
+
+		others := strings.Join(otherParticipantNames(match, userID), ", ")
+		var notifications []*Notification
+		for _, other := range match.ActiveParticipants() {
+			if other.UserID == userID || other.User == nil {
+				continue
+			}
+			// Show the times on the recipient's own clock
+			formatted := make([]string, len(slots))
+			for i, slot := range slots {
+				formatted[i] = formatMeetingTime(slot.Start, slot.End, other.User.Location(org))
+			}
+			notifications = append(notifications, &Notification{
+				Event:          models.NotificationRescheduleProposed,
+				OrganisationID: match.OrganisationID,
+				RecipientName:  fullName(other.User),
+				RecipientEmail: other.User.Email,
+				Text:           fmt.Sprintf("%s proposed new times for the cuppa with %s", fullName(proposer), others),
+				Data: NotificationData{
+					MatchID:     match.ID,
+					PartnerName: fullName(proposer),
+					Slots:       formatted,
+					Counter:     counter,
+				},
+			})
+		}
+		return s.notifier.Enqueue(repos.Outbox, notifications...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+func (s *rescheduleService) AcceptProposal(userID uint, matchID uint, proposalID uint, start time.Time) (*models.Match, error) {
+	match, err := s.participantMatch(userID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal, err := s.proposalRepo.FindByID(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil || proposal.MatchID != matchID {
+		return nil, ErrRescheduleNotFound
+	}
+	if proposal.Status != models.RescheduleStatusPending {
+		return nil, ErrRescheduleClosed
+	}
+	if proposal.ProposedByID == userID {
+		return nil, ErrOwnRescheduleProposal
+	}
+	if !isReschedulable(match) {
+		return nil, ErrMatchNotReschedulable
+	}
+
+	var chosen *models.ProposedSlot
+	for i := range proposal.Slots {
+		if proposal.Slots[i].Start.Equal(start) {
+			chosen = &proposal.Slots[i]
+			break
+		}
+	}
+	if chosen == nil {
+		return nil, ErrSlotNotProposed
+	}
+
+	now := time.Now()
+	if !chosen.Start.After(now) {
+		return nil, ErrInvalidRescheduleSlot
+	}
+
+	org, err := s.orgRepo.FindByID(match.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+	slotStart := chosen.Start.In(org.Location())
+	slotEnd := chosen.End.In(org.Location())
+	match.ScheduledDate = time.Date(slotStart.Year(), slotStart.Month(), slotStart.Day(), 0, 0, 0, 0, slotStart.Location())
+	match.ScheduledTime = slotStart.Format("15:04")
+	match.ScheduledStart = &slotStart
+	match.ScheduledEnd = &slotEnd
+	match.CalendarSequence++
+	// Keep the full feedback window after the new slot
+	feedbackDeadline := slotEnd.AddDate(0, 0, feedbackWindowDays)
+	if match.ExpiresAt == nil || match.ExpiresAt.Before(feedbackDeadline) {
+		match.ExpiresAt = &feedbackDeadline
+	}
+
+	proposal.Status = models.RescheduleStatusAccepted
+	proposal.AcceptedStart = &chosen.Start
+	proposal.RespondedByID = &userID
+	proposal.RespondedAt = &now
+
+	// Everyone gets the updated invite: the proposers with the acceptance, the accepter
+	// as a confirmation of the new time
+	accepter := match.Participant(userID).User
+	names := otherParticipantNames(match, userID)
+	others := strings.Join(names, ", ")
+	invite := buildMatchInvite(match, icsMethodRequest, now)
+	var notifications []*Notification
+	for _, other := range match.ActiveParticipants() {
+		if other.User == nil {
+			continue
+		}
+		formatted := formatMeetingTime(slotStart, slotEnd, other.User.Location(org))
+		if other.UserID == userID {
+			notifications = append(notifications, &Notification{
+				Event:          models.NotificationMatchConfirmed,
+				OrganisationID: match.OrganisationID,
+				RecipientName:  fullName(other.User),
+				RecipientEmail: other.User.Email,
+				Text:           fmt.Sprintf("%s's cuppa with %s is confirmed for %s", fullName(other.User), others, formatted),
+				Data: NotificationData{
+					MatchID:          match.ID,
+					ParticipantNames: names,
+					MeetingTime:      formatted,
+					Invite:           invite,
+				},
+			})
+		} else {
+			notifications = append(notifications, &Notification{
+				Event:          models.NotificationRescheduleAccepted,
+				OrganisationID: match.OrganisationID,
+				RecipientName:  fullName(other.User),
+				RecipientEmail: other.User.Email,
+				Text:           fmt.Sprintf("%s moved the cuppa with %s to %s", fullName(accepter), others, formatted),
+				Data: NotificationData{
+					MatchID:     match.ID,
+					PartnerName: fullName(accepter),
+					MeetingTime: formatted,
+					Invite:      invite,
+				},
+			})
+		}
+	}
+
+	// The accepted proposal, the new slot and the invites are saved together
+	err = s.uow.Do(func(repos *repositories.TxRepositories) error {
+		if err := repos.RescheduleProposals.Update(proposal); err != nil {
+			return err
+		}
+		if err := repos.Matches.Update(match); err != nil {
+			return err
+		}
+		return s.notifier.Enqueue(repos.Outbox, notifications...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return match, nil
+}
+
+// participantMatch loads the match and checks that the user takes part in it
+func (s *rescheduleService) participantMatch(userID uint, matchID uint) (*models.Match, error) {
+	match, err := s.matchRepo.FindByID(matchID)
+	if err != nil {
+		return nil, ErrMatchNotFound
+	}
+	if !match.IsActiveParticipant(userID) {
+		return nil, ErrUnauthorizedMatch
+	}
+	return match, nil
+}
+
+// isReschedulable reports whether everyone has accepted the match and it is still open
+func isReschedulable(match *models.Match) bool {
+	return match.Status == models.MatchStatusAccepted || match.Status == models.MatchStatusWaitingForFeedback
+}
+
+// freeTimes returns when the user is free from now to the reschedule horizon, on their own
+// clock: their weekly availability within working hours, with their dated overrides applied
+// and their holidays taken out, the same way the candidate pool reads it
+func (s *rescheduleService) freeTimes(org *models.Organisation, user *models.User, config *models.UserAvailabilityConfig, now time.Time) ([]models.Interval, error) {
+	// The last slot may start at the horizon, so its end lies up to a day beyond it
+	from, to := now, now.AddDate(0, 0, rescheduleHorizonDays+1)
+
+	// Widen the range by a day each side: the user's own time zone can shift their dates
+	calendars, err := s.calendarRepo.FindByOrganisationBetween(org.ID, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.overrideRepo.FindByUserIDsBetween([]uint{user.ID}, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	loc := user.Location(org)
+	free := freeIntervals(org, availability{config: config, loc: loc}, from, to)
+	free = applyOverrides(org, loc, free, overrides, from, to)
+	return models.SubtractIntervals(free, closedDays(calendars, user, loc)), nil
+}
+
+// fitsAvailability reports whether the slot starts within the reschedule horizon and lies
+// inside one of the user's free intervals
+func fitsAvailability(free []models.Interval, slot models.ProposedSlot, now time.Time) bool {
+	if !slot.Start.After(now) || slot.Start.After(now.AddDate(0, 0, rescheduleHorizonDays)) {
+		return false
+	}
+	for _, interval := range free {
+		if !slot.Start.Before(interval.Start) && !slot.End.After(interval.End) {
+			return true
+		}
+	}
+	return false
+}
+
+func fullName(user *models.User) string {
+	if user == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", user.FirstName, user.LastName)
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"virtual-cuppa-be/models"
+	"virtual-cuppa-be/repositories"
+)
+
+// memUnitOfWork runs fn against the repositories of its one transaction
+type memUnitOfWork struct {
+	repos *repositories.TxRepositories
+}
+
+func (u *memUnitOfWork) Do(fn func(repos *repositories.TxRepositories) error) error {
+	return fn(u.repos)
+}
+
+// memProposalRepo serves and records reschedule proposals
+type memProposalRepo struct {
+	repositories.RescheduleProposalRepository
+	proposal *models.RescheduleProposal
+	created  []*models.RescheduleProposal
+	updated  []*models.RescheduleProposal
+}
+
+func (r *memProposalRepo) FindPendingByMatch(matchID uint) (*models.RescheduleProposal, error) {
+	return nil, nil
+}
+
+func (r *memProposalRepo) Create(proposal *models.RescheduleProposal) error {
+	r.created = append(r.created, proposal)
+	return nil
+}
+
+func (r *memProposalRepo) FindByID(id uint) (*models.RescheduleProposal, error) {
+	if r.proposal == nil || r.proposal.ID != id {
+		return nil, nil
+	}
+	return r.proposal, nil
+}
+
+func (r *memProposalRepo) Update(proposal *models.RescheduleProposal) error {
+	r.updated = append(r.updated, proposal)
+	return nil
+}
+
+// memTxMatchRepo records the matches and participants updated in a transaction
+type memTxMatchRepo struct {
+	repositories.MatchRepository
+	updated             []*models.Match
+	updatedParticipants []*models.MatchParticipant
+}
+
+func (r *memTxMatchRepo) Update(match *models.Match) error {
+	r.updated = append(r.updated, match)
+	return nil
+}
+
+func (r *memTxMatchRepo) UpdateParticipant(participant *models.MatchParticipant) error {
+	r.updatedParticipants = append(r.updatedParticipants, participant)
+	return nil
+}
+
+// failingOutboxRepo refuses every message
+type failingOutboxRepo struct {
+	repositories.OutboxRepository
+}
+
+func (r *failingOutboxRepo) Create(message *models.OutboxMessage) error {
+	return errors.New("outbox unavailable")
+}
+
+// newAcceptService returns a reschedule service over a confirmed match between users 1 and
+// 2 with an open proposal from user 2, whose writes go to the returned transaction
+func newAcceptService(outbox repositories.OutboxRepository) (*rescheduleService, *repositories.TxRepositories, time.Time) {
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	match := &models.Match{
+		ID:             7,
+		OrganisationID: 1,
+		User1ID:        1,
+		User2ID:        2,
+		Status:         models.MatchStatusAccepted,
+		Participants: []*models.MatchParticipant{
+			{MatchID: 7, UserID: 1, Status: models.ParticipantStatusAccepted, User: &models.User{ID: 1, FirstName: "Ada", Email: "ada@example.com"}},
+			{MatchID: 7, UserID: 2, Status: models.ParticipantStatusAccepted, User: &models.User{ID: 2, FirstName: "Grace", Email: "grace@example.com"}},
+		},
+	}
+	proposal := &models.RescheduleProposal{
+		ID:           3,
+		MatchID:      7,
+		ProposedByID: 2,
+		Slots:        models.ProposedSlots{{Start: start, End: start.Add(30 * time.Minute)}},
+		Status:       models.RescheduleStatusPending,
+	}
+	tx := &repositories.TxRepositories{
+		Matches:             &memTxMatchRepo{},
+		RescheduleProposals: &memProposalRepo{},
+		Outbox:              outbox,
+	}
+	s := &rescheduleService{
+		proposalRepo: &memProposalRepo{proposal: proposal},
+		matchRepo:    &memLinkMatchRepo{match: match},
+		orgRepo:      &memOrgRepo{queryCounter: &queryCounter{}, org: &models.Organisation{ID: 1, TimeZone: "UTC"}},
+		uow:          &memUnitOfWork{repos: tx},
+		notifier:     &notifier{channelRepo: &memChannelRepo{}},
+	}
+	return s, tx, start
+}
+
+// TestAcceptProposalWritesInOneUnitOfWork checks the proposal, the new slot and the invites
+// are all written through the transaction
+func TestAcceptProposalWritesInOneUnitOfWork(t *testing.T) {
+	outbox := &memOutboxRepo{}
+	s, tx, start := newAcceptService(outbox)
+
+	match, err := s.AcceptProposal(1, 7, 3, start)
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if match.ScheduledStart == nil || !match.ScheduledStart.Equal(start) {
+		t.Errorf("scheduled start = %v, want %v", match.ScheduledStart, start)
+	}
+
+	proposals := tx.RescheduleProposals.(*memProposalRepo).updated
+	if len(proposals) != 1 || proposals[0].Status != models.RescheduleStatusAccepted {
+		t.Errorf("proposals updated in the transaction = %v, want the accepted proposal", proposals)
+	}
+	if matches := tx.Matches.(*memTxMatchRepo).updated; len(matches) != 1 {
+		t.Errorf("matches updated in the transaction = %d, want 1", len(matches))
+	}
+	if len(outbox.messages) != 2 {
+		t.Fatalf("outbox messages = %d, want one per participant", len(outbox.messages))
+	}
+	events := map[models.NotificationEvent]string{}
+	for _, message := range outbox.messages {
+		events[message.Event] = message.Recipient
+	}
+	if events[models.NotificationMatchConfirmed] != "ada@example.com" || events[models.NotificationRescheduleAccepted] != "grace@example.com" {
+		t.Errorf("queued events = %v, want the confirmation for Ada and the acceptance for Grace", events)
+	}
+}
+
+// TestAcceptProposalFailsWhenNotificationsCannotBeQueued checks a failure to queue the
+// invites fails the whole acceptance rather than leaving a silent reschedule
+func TestAcceptProposalFailsWhenNotificationsCannotBeQueued(t *testing.T) {
+	s, _, start := newAcceptService(&failingOutboxRepo{})
+
+	if _, err := s.AcceptProposal(1, 7, 3, start); err == nil {
+		t.Error("accept with a failing outbox: got no error")
+	}
+}
+
+// TestProposeSlotsChecksOverridesAndHolidays checks proposed slots are held against the
+// proposer's free times as matching reads them: weekly availability within working hours,
+// dated overrides and holidays
+func TestProposeSlotsChecksOverridesAndHolidays(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	monday := today.AddDate(0, 0, (8-int(today.Weekday()))%7+7)
+	day := func(offset int) time.Time {
+		return monday.AddDate(0, 0, offset)
+	}
+	at := func(offset, hour, minute int) time.Time {
+		return day(offset).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	var ranges models.TimeRanges
+	for _, weekday := range []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"} {
+		ranges = append(ranges, models.TimeRange{Day: weekday, Start: "09:00", End: "17:00"})
+	}
+	base, _, _ := newAcceptService(&memOutboxRepo{})
+	proposals := &memProposalRepo{}
+	s := &rescheduleService{
+		matchRepo:       base.matchRepo,
+		orgRepo:         base.orgRepo,
+		availConfigRepo: &memConfigRepo{queryCounter: &queryCounter{}, configs: map[uint]*models.UserAvailabilityConfig{1: {UserID: 1, TimeRanges: ranges}}},
+		calendarRepo: &memCalendarRepo{queryCounter: &queryCounter{}, calendars: []*models.HolidayCalendar{
+			{Dates: []models.CalendarDate{{Date: day(1), Name: "Bank holiday"}}},
+			{Office: "Lisbon", Dates: []models.CalendarDate{{Date: day(3), Name: "Lisbon holiday"}}},
+		}},
+		overrideRepo: &memOverrideRepo{queryCounter: &queryCounter{}, overrides: []*models.AvailabilityOverride{
+			{UserID: 1, Kind: models.OverrideKindUnavailable, StartDate: day(2), EndDate: day(2), StartTime: "12:00", EndTime: "14:00"},
+			{UserID: 1, Kind: models.OverrideKindAvailable, StartDate: day(5), EndDate: day(5), StartTime: "10:00", EndTime: "12:00"},
+		}},
+		uow:      &memUnitOfWork{repos: &repositories.TxRepositories{RescheduleProposals: proposals, Outbox: &memOutboxRepo{}}},
+		notifier: &notifier{channelRepo: &memChannelRepo{}},
+	}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		fits  bool
+	}{
+		{"weekly availability", at(0, 10, 0), true},
+		{"ends at the end of the working day", at(0, 16, 30), true},
+		{"outside working hours", at(0, 17, 0), false},
+		{"holiday", at(1, 10, 0), false},
+		{"another office's holiday", at(3, 10, 0), true},
+		{"unavailable override", at(2, 12, 30), false},
+		{"same day outside the override", at(2, 10, 0), true},
+		{"available override on a weekend", at(5, 10, 30), true},
+		{"weekend outside the override", at(5, 13, 0), false},
+		{"beyond the horizon", today.AddDate(0, 0, rescheduleHorizonDays+7).Add(10 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ProposeSlots(1, 7, []time.Time{tt.start})
+			if tt.fits && err != nil {
+				t.Errorf("%s: %v", tt.start.Format(time.RFC1123), err)
+			}
+			if !tt.fits && !errors.Is(err, ErrInvalidRescheduleSlot) {
+				t.Errorf("%s: got %v, want %v", tt.start.Format(time.RFC1123), err, ErrInvalidRescheduleSlot)
+			}
+		})
+	}
+	if len(proposals.created) != 5 {
+		t.Errorf("proposals created = %d, want one per fitting slot", len(proposals.created))
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+	"virtual-cuppa-be/models"
+)
+
+// schedulingWindowDays is how many days ahead (starting tomorrow) cuppas are scheduled
+const schedulingWindowDays = 7
+
+// slotStep is the granularity of proposed start times
+const slotStep = 30 * time.Minute
+
+var ErrNoCommonSlot = errors.New("participants have no common availability slot")
+
+// meetingSlot is a concrete time window for a cuppa
+type meetingSlot struct {
+	start time.Time
+	end   time.Time
+}
+
+// availability is a participant's weekly availability and the time zone it is expressed in
+type availability struct {
+	config *models.UserAvailabilityConfig
+	loc    *time.Location
+}
+
+// schedulingRange returns the days cuppas are scheduled in: from tomorrow, on the
+// organisation's calendar, for schedulingWindowDays days
+func schedulingRange(org *models.Organisation, now time.Time) (time.Time, time.Time) {
+	local := now.In(org.Location())
+	from := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location()).AddDate(0, 0, 1)
+	return from, from.AddDate(0, 0, schedulingWindowDays)
+}
+
+// freeIntervals returns when a participant is available within [from, to). Their weekly
+// windows and the organisation's working hours are both read on the participant's own clock.
+func freeIntervals(org *models.Organisation, a availability, from, to time.Time) []models.Interval {
+	startHour, endHour := org.WorkingHours()
+	var windows []models.WeeklyWindow
+	for _, w := range a.config.Windows() {
+		if w = w.Clip(startHour*60, endHour*60); w.Duration() > 0 {
+			windows = append(windows, w)
+		}
+	}
+	return models.WindowIntervals(windows, a.loc, from, to)
+}
+
+// applyOverrides adjusts a participant's free times within [from, to) by their dated
+// overrides, read on their own clock: days with an available override keep only the
+// override's hours within working hours, then unavailable overrides are taken out
+func applyOverrides(org *models.Organisation, loc *time.Location, free []models.Interval, overrides []*models.AvailabilityOverride, from, to time.Time) []models.Interval {
+	if len(overrides) == 0 {
+		return free
+	}
+
+	startHour, endHour := org.WorkingHours()
+	var replacedDays, added, removed []models.Interval
+	for _, override := range overrides {
+		switch override.Kind {
+		case models.OverrideKindAvailable:
+			replacedDays = append(replacedDays, override.Days(loc)...)
+			added = append(added, override.Intervals(loc, startHour*60, endHour*60)...)
+		case models.OverrideKindUnavailable:
+			removed = append(removed, override.Intervals(loc, 0, 24*60)...)
+		}
+	}
+
+	free = models.SubtractIntervals(free, mergeIntervals(replacedDays))
+	added = models.IntersectIntervals(mergeIntervals(added), []models.Interval{{Start: from, End: to}})
+	free = mergeIntervals(append(free, added...))
+	return models.SubtractIntervals(free, mergeIntervals(removed))
+}
+
+// commonIntervals intersects everyone's free times and keeps the intervals long enough for a cuppa
+func commonIntervals(org *models.Organisation, free [][]models.Interval) []models.Interval {
+	if len(free) == 0 {
+		return nil
+	}
+	common := free[0]
+	for _, f := range free[1:] {
+		common = models.IntersectIntervals(common, f)
+	}
+
+	var long []models.Interval
+	for _, interval := range common {
+		if interval.Duration() >= org.CuppaDuration() {
+			long = append(long, interval)
+		}
+	}
+	return long
+}
+
+// pickMeetingSlot chooses a concrete time for a cuppa inside one of the intervals every
+// participant is free for. Days with the fewest cuppas already scheduled are preferred so
+// that meetings spread over the scheduling range. The slot is returned in the organisation's
+// time zone.
+func (s *matchService) pickMeetingSlot(org *models.Organisation, common []models.Interval, now time.Time) (*meetingSlot, error) {
+	if len(common) == 0 {
+		return nil, ErrNoCommonSlot
+	}
+
+	loc := org.Location()
+	duration := org.CuppaDuration()
+	from, to := schedulingRange(org, now)
+
+	starts, err := s.matchRepo.FindScheduledStartsBetween(org.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	load := make(map[string]int)
+	for _, start := range starts {
+		load[start.In(loc).Format("2006-01-02")]++
+	}
+
+	var candidates []meetingSlot
+	bestLoad := -1
+	for _, interval := range common {
+		dayLoad := load[interval.Start.In(loc).Format("2006-01-02")]
+		if bestLoad >= 0 && dayLoad > bestLoad {
+			continue
+		}
+		if dayLoad < bestLoad {
+			candidates = nil
+		}
+		bestLoad = dayLoad
+		candidates = append(candidates, meetingSlot{start: interval.Start, end: interval.End})
+	}
+
+	// Pick a start time on the slot grid that leaves room for the whole cuppa
+	window := candidates[rand.Intn(len(candidates))]
+	steps := int((window.end.Sub(window.start)-duration)/slotStep) + 1
+	start := window.start.Add(time.Duration(rand.Intn(steps)) * slotStep).In(loc)
+
+	return &meetingSlot{start: start, end: start.Add(duration)}, nil
+}
+
+// formatInterval renders an interval on the clock of loc, e.g. "Tuesday 12:00-13:30"
+func formatInterval(interval models.Interval, loc *time.Location) string {
+	return fmt.Sprintf("%s-%s", interval.Start.In(loc).Format("Monday 15:04"), interval.End.In(loc).Format("15:04"))
+}
+
+// formatMeetingTime renders a meeting for emails in the recipient's time zone,
+// e.g. "Monday 2 March, 14:00-14:30 GMT"
+func formatMeetingTime(start, end time.Time, loc *time.Location) string {
+	return fmt.Sprintf("%s-%s", start.In(loc).Format("Monday 2 January, 15:04"), end.In(loc).Format("15:04 MST"))
+}
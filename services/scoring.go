@@ -0,0 +1,170 @@
+package services
+
+import (
+	"math"
+	"math/rand"
+	"virtual-cuppa-be/models"
+)
+
+// Names of the built-in scoring strategies, used as keys in Organisation.ScoringWeights
+const (
+	ScorerTagOverlap      = "tag_overlap"
+	ScorerTagDiversity    = "tag_diversity"
+	ScorerSenioritySpread = "seniority_spread"
+	ScorerPastRating      = "past_rating"
+	ScorerRandom          = "random"
+)
+
+// MatchScorer rates how good a pairing of two users is on a scale from 0 to 100
+type MatchScorer interface {
+	Name() string
+	Score(a, b *models.User) float64
+}
+
+// builtinScorers creates the strategies organisations can pick from
+var builtinScorers = map[string]func() MatchScorer{
+	ScorerTagOverlap:      func() MatchScorer { return tagOverlapScorer{} },
+	ScorerTagDiversity:    func() MatchScorer { return tagDiversityScorer{} },
+	ScorerSenioritySpread: func() MatchScorer { return senioritySpreadScorer{} },
+	ScorerPastRating:      func() MatchScorer { return pastRatingScorer{} },
+	ScorerRandom:          func() MatchScorer { return randomScorer{} },
+}
+
+// tagOverlapScorer prefers colleagues with shared interests (Jaccard index over tag names)
+type tagOverlapScorer struct{}
+
+func (tagOverlapScorer) Name() string { return ScorerTagOverlap }
+
+func (tagOverlapScorer) Score(a, b *models.User) float64 {
+	return tagJaccard(a.Tags, b.Tags) * 100
+}
+
+// tagDiversityScorer prefers colleagues with different interests ("meet someone different")
+type tagDiversityScorer struct{}
+
+func (tagDiversityScorer) Name() string { return ScorerTagDiversity }
+
+func (tagDiversityScorer) Score(a, b *models.User) float64 {
+	if len(a.Tags) == 0 && len(b.Tags) == 0 {
+		return 0
+	}
+	return (1 - tagJaccard(a.Tags, b.Tags)) * 100
+}
+
+// senioritySpreadScorer prefers pairs far apart in seniority. Users without a level score 0.
+type senioritySpreadScorer struct{}
+
+func (senioritySpreadScorer) Name() string { return ScorerSenioritySpread }
+
+func (senioritySpreadScorer) Score(a, b *models.User) float64 {
+	if a.SeniorityLevel == nil || b.SeniorityLevel == nil {
+		return 0
+	}
+	spread := math.Abs(float64(*a.SeniorityLevel - *b.SeniorityLevel))
+	return spread / float64(models.MaxSeniorityLevel-models.MinSeniorityLevel) * 100
+}
+
+// pastRatingScorer prefers users who received good feedback in earlier cuppas.
+// Users without ratings yet count as average.
+type pastRatingScorer struct{}
+
+func (pastRatingScorer) Name() string { return ScorerPastRating }
+
+func (pastRatingScorer) Score(a, b *models.User) float64 {
+	return (ratingScore(a.AverageRating) + ratingScore(b.AverageRating)) / 2
+}
+
+func ratingScore(rating *float64) float64 {
+	if rating == nil {
+		return 50
+	}
+	// Ratings run from 1 to 5
+	return (*rating - 1) / 4 * 100
+}
+
+// randomScorer adds variety so that the same pairs do not always win
+type randomScorer struct{}
+
+func (randomScorer) Name() string { return ScorerRandom }
+
+func (randomScorer) Score(a, b *models.User) float64 {
+	return rand.Float64() * 100
+}
+
+// compositeScorer combines strategies as a weighted average of their scores
+type compositeScorer struct {
+	scorers []MatchScorer
+	weights []float64
+}
+
+// newCompositeScorer builds a scorer from strategy weights keyed by strategy name.
+// Unknown names and non-positive weights are ignored; if nothing remains, tag overlap is used.
+func newCompositeScorer(weights map[string]float64) *compositeScorer {
+	composite := &compositeScorer{}
+	for _, name := range []string{ScorerTagOverlap, ScorerTagDiversity, ScorerSenioritySpread, ScorerPastRating, ScorerRandom} {
+		weight := weights[name]
+		if weight <= 0 {
+			continue
+		}
+		composite.scorers = append(composite.scorers, builtinScorers[name]())
+		composite.weights = append(composite.weights, weight)
+	}
+	if len(composite.scorers) == 0 {
+		composite.scorers = []MatchScorer{tagOverlapScorer{}}
+		composite.weights = []float64{1}
+	}
+	return composite
+}
+
+func (c *compositeScorer) Name() string { return "composite" }
+
+func (c *compositeScorer) Score(a, b *models.User) float64 {
+	score, _ := c.Breakdown(a, b)
+	return score
+}
+
+// Breakdown returns the combined score together with each strategy's own score
+func (c *compositeScorer) Breakdown(a, b *models.User) (float64, map[string]float64) {
+	components := make(map[string]float64, len(c.scorers))
+	var total, weightSum float64
+	for i, scorer := range c.scorers {
+		score := scorer.Score(a, b)
+		components[scorer.Name()] = score
+		total += score * c.weights[i]
+		weightSum += c.weights[i]
+	}
+	return total / weightSum, components
+}
+
+// scorerFor returns the composite scorer configured for the organisation
+func scorerFor(org *models.Organisation) *compositeScorer {
+	return newCompositeScorer(org.ScoringWeights)
+}
+
+// tagJaccard is the Jaccard index over tag names, between 0 and 1
+func tagJaccard(tags1, tags2 []models.Tag) float64 {
+	tagMap := make(map[string]bool)
+	for _, tag := range tags1 {
+		tagMap[tag.Name] = true
+	}
+
+	commonTags := 0
+	totalUniqueTags := len(tagMap)
+	seen := make(map[string]bool)
+	for _, tag := range tags2 {
+		if seen[tag.Name] {
+			continue
+		}
+		seen[tag.Name] = true
+		if tagMap[tag.Name] {
+			commonTags++
+		} else {
+			totalUniqueTags++
+		}
+	}
+
+	if totalUniqueTags == 0 {
+		return 0
+	}
+	return float64(commonTags) / float64(totalUniqueTags)
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// smtpTimeout bounds a whole SMTP conversation so an unreachable server can't stall the
+// outbox worker
+const smtpTimeout = 30 * time.Second
+
+// smtpEmailService sends the emails over SMTP, rendering email-templates/ locally instead of
+// using SendGrid dynamic templates. It suits self-hosted installs and local development
+// against an SMTP sink such as Mailpit.
+type smtpEmailService struct {
+	host      string
+	port      string
+	username  string
+	password  string
+	from      mail.Address
+	appURL    string
+	templates map[string]*emailTemplate
+}
+
+// NewSMTPEmailService loads the templates from EMAIL_TEMPLATES_PATH and fails if any of them
+// is missing or invalid
+func NewSMTPEmailService() (EmailService, error) {
+	templates, err := loadEmailTemplates(getEmailTemplatesPath())
+	if err != nil {
+		return nil, err
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	fromAddress := os.Getenv("SMTP_FROM")
+	if fromAddress == "" {
+		fromAddress = "noreply@notacv.com"
+	}
+
+	return &smtpEmailService{
+		host:      os.Getenv("SMTP_HOST"),
+		port:      port,
+		username:  os.Getenv("SMTP_USERNAME"),
+		password:  os.Getenv("SMTP_PASSWORD"),
+		from:      mail.Address{Name: "Virtual Cuppa", Address: fromAddress},
+		appURL:    os.Getenv("APP_URL"),
+		templates: templates,
+	}, nil
+}
+
+func (s *smtpEmailService) SendConfirmCode(toEmail string, toName string, confirmCode string) error {
+	return s.send(toEmail, toName, confirmCodeTemplate, map[string]interface{}{
+		"Code": confirmCode,
+	}, nil, "")
+}
+
+func (s *smtpEmailService) SendInvitation(toEmail string, toName string, organisationName string) error {
+	return s.send(toEmail, toName, userInvitationTemplate, map[string]interface{}{
+		"UserName":         toName,
+		"OrganisationName": organisationName,
+	}, nil, "")
+}
+
+func (s *smtpEmailService) SendMatchCreated(toEmail string, toName string, participantNames []string, sharedTags []string, meetingTime string, acceptURL string, rejectURL string) error {
+	return s.send(toEmail, toName, matchCreatedTemplate, map[string]interface{}{
+		"UserName":         toName,
+		"ParticipantNames": participantNames,
+		"SharedTags":       sharedTags,
+		"MeetingTime":      meetingTime,
+		"AcceptURL":        acceptURL,
+		"RejectURL":        rejectURL,
+	}, nil, "")
+}
+
+func (s *smtpEmailService) SendAcceptanceReminder(toEmail string, toName string, participantNames []string, meetingTime string, acceptURL string, rejectURL string) error {
+	return s.send(toEmail, toName, acceptanceReminderTemplate, map[string]interface{}{
+		"UserName":         toName,
+		"ParticipantNames": participantNames,
+		"MeetingTime":      meetingTime,
+		"AcceptURL":        acceptURL,
+		"RejectURL":        rejectURL,
+	}, nil, "")
+}
+
+func (s *smtpEmailService) SendMatchAccepted(toEmail string, toName string, matchName string, matchEmail string, meetingTime string, availabilitySlots []AvailabilitySlot) error {
+	return s.send(toEmail, toName, matchAcceptedTemplate, map[string]interface{}{
+		"MatchName":         matchName,
+		"MatchEmail":        matchEmail,
+		"MeetingTime":       meetingTime,
+		"AvailabilitySlots": availabilitySlots,
+	}, nil, "")
+}
+
+func (s *smtpEmailService) SendRescheduleProposed(toEmail string, toName string, proposerName string, slots []string, counter bool) error {
+	return s.send(toEmail, toName, rescheduleProposedTemplate, map[string]interface{}{
+		"ProposerName": proposerName,
+		"Slots":        slots,
+		"Counter":      counter,
+	}, nil, "")
+}
+
+func (s *smtpEmailService) SendRescheduleAccepted(toEmail string, toName string, accepterName string, slot string, invite []byte) error {
+	return s.send(toEmail, toName, rescheduleAcceptedTemplate, map[string]interface{}{
+		"AccepterName": accepterName,
+		"Slot":         slot,
+	}, invite, "REQUEST")
+}
+
+func (s *smtpEmailService) SendMatchConfirmed(toEmail string, toName string, participantNames []string, meetingTime string, invite []byte) error {
+	return s.send(toEmail, toName, matchConfirmedTemplate, map[string]interface{}{
+		"ParticipantNames": participantNames,
+		"MeetingTime":      meetingTime,
+	}, invite, "REQUEST")
+}
+
+func (s *smtpEmailService) SendMatchCancelled(toEmail string, toName string, meetingTime string, cancellation []byte) error {
+	return s.send(toEmail, toName, matchCancelledTemplate, map[string]interface{}{
+		"MeetingTime": meetingTime,
+	}, cancellation, "CANCEL")
+}
+
+func (s *smtpEmailService) SendFeedbackReminder(toEmail string, toName string, participantNames []string, meetingTime string, feedbackDeadline string) error {
+	return s.send(toEmail, toName, feedbackReminderTemplate, map[string]interface{}{
+		"UserName":         toName,
+		"ParticipantNames": participantNames,
+		"MeetingTime":      meetingTime,
+		"FeedbackDeadline": feedbackDeadline,
+	}, nil, "")
+}
+
+// send renders the template with data and mails it, with the invite attached when given
+func (s *smtpEmailService) send(toEmail string, toName string, templateName string, data map[string]interface{}, invite []byte, method string) error {
+	if s.host == "" {
+		return fmt.Errorf("smtp not configured: SMTP_HOST is empty")
+	}
+
+	tmpl := s.templates[templateName]
+	data["AppURL"] = s.appURL
+	htmlBody, textBody, err := tmpl.render(data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s for %s: %w", templateName, toEmail, err)
+	}
+
+	to := mail.Address{Name: toName, Address: toEmail}
+	message, err := buildMIMEMessage(s.from, to, tmpl.subject, textBody, htmlBody, invite, method)
+	if err != nil {
+		return err
+	}
+
+	if err := s.deliver(toEmail, message); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", toEmail, err)
+	}
+	return nil
+}
+
+// deliver runs the SMTP conversation, upgrading to TLS when the server offers STARTTLS and
+// authenticating when SMTP_USERNAME is set
+func (s *smtpEmailService) deliver(toEmail string, message []byte) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(s.host, s.port), smtpTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(toEmail); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMIMEMessage assembles a multipart/mixed message: the plain-text and HTML bodies as
+// alternatives, followed by the calendar invite as cuppa.ics
+func buildMIMEMessage(from, to mail.Address, subject, textBody, htmlBody string, invite []byte, method string) ([]byte, error) {
+	var alternatives bytes.Buffer
+	alternative := multipart.NewWriter(&alternatives)
+	for _, body := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", textBody},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		part, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(body.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mixed := multipart.NewWriter(&body)
+	part, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(alternatives.Bytes()); err != nil {
+		return nil, err
+	}
+	if len(invite) > 0 {
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"text/calendar; charset=utf-8; method=" + method + `; name="cuppa.ics"`},
+			"Content-Disposition":       {`attachment; filename="cuppa.ics"`},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(invite)
+		for len(encoded) > 76 {
+			if _, err := part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+				return nil, err
+			}
+			encoded = encoded[76:]
+		}
+		if _, err := part.Write([]byte(encoded + "\r\n")); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	headers := []string{
+		"From: " + from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: " + newMessageID(from.Address),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary=" + mixed.Boundary(),
+	}
+	message.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+func newMessageID(fromAddress string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(fromAddress, "@"); at >= 0 {
+		domain = fromAddress[at+1:]
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">"
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// receivedMail is what the test SMTP server was sent
+type receivedMail struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// startSMTPServer accepts one SMTP conversation on a local port, without STARTTLS or AUTH,
+// and sends what it received on the returned channel once the client quits
+func startSMTPServer(t *testing.T) (string, string, <-chan receivedMail) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan receivedMail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		var message receivedMail
+		reply := func(line string) bool {
+			return text.PrintfLine("%s", line) == nil
+		}
+		if !reply("220 localhost ESMTP test") {
+			return
+		}
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch verb {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "MAIL":
+				message.from = strings.TrimPrefix(line[len("MAIL "):], "FROM:")
+				reply("250 OK")
+			case "RCPT":
+				message.to = append(message.to, strings.TrimPrefix(line[len("RCPT "):], "TO:"))
+				reply("250 OK")
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				message.data, err = text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				reply("250 OK")
+			case "QUIT":
+				reply("221 Bye")
+				received <- message
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port, received
+}
+
+func TestSMTPDeliverSendsMultipartMessageWithInvite(t *testing.T) {
+	host, port, received := startSMTPServer(t)
+	s := &smtpEmailService{
+		host: host,
+		port: port,
+		from: mail.Address{Name: "Virtual Cuppa", Address: "noreply@example.com"},
+	}
+
+	invite := []byte("BEGIN:VCALENDAR\r\nMETHOD:REQUEST\r\n" + strings.Repeat("DESCRIPTION:A long line to wrap\r\n", 5) + "END:VCALENDAR\r\n")
+	textBody := "Your cuppa with Grace is confirmed for Monday at 10:00."
+	htmlBody := "<p>Your cuppa with <strong>Grace</strong> is confirmed for Monday at 10:00.</p>"
+	to := mail.Address{Name: "Ada Lovelace", Address: "ada@example.com"}
+	message, err := buildMIMEMessage(s.from, to, "Cuppa confirmed ☕", textBody, htmlBody, invite, "REQUEST")
+	if err != nil {
+		t.Fatalf("build message: %v", err)
+	}
+	if err := s.deliver(to.Address, message); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	got := <-received
+	if got.from != "<noreply@example.com>" {
+		t.Errorf("MAIL FROM = %q, want <noreply@example.com>", got.from)
+	}
+	if len(got.to) != 1 || got.to[0] != "<ada@example.com>" {
+		t.Errorf("RCPT TO = %q, want [<ada@example.com>]", got.to)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(got.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); err != nil || subject != "Cuppa confirmed ☕" {
+		t.Errorf("subject = %q, %v; want Cuppa confirmed ☕", subject, err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, want multipart/mixed", msg.Header.Get("Content-Type"))
+	}
+
+	mixed := multipart.NewReader(msg.Body, params["boundary"])
+	alternativePart, err := mixed.NextPart()
+	if err != nil {
+		t.Fatalf("first part: %v", err)
+	}
+	mediaType, params, err = mime.ParseMediaType(alternativePart.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("first part Content-Type = %q, want multipart/alternative", alternativePart.Header.Get("Content-Type"))
+	}
+	alternative := multipart.NewReader(alternativePart, params["boundary"])
+	for _, want := range []struct{ mediaType, body string }{
+		{"text/plain", textBody},
+		{"text/html", htmlBody},
+	} {
+		part, err := alternative.NextPart()
+		if err != nil {
+			t.Fatalf("%s part: %v", want.mediaType, err)
+		}
+		if mediaType, params, _ := mime.ParseMediaType(part.Header.Get("Content-Type")); mediaType != want.mediaType || params["charset"] != "utf-8" {
+			t.Errorf("Content-Type = %q, want %s; charset=utf-8", part.Header.Get("Content-Type"), want.mediaType)
+		}
+		// NextPart decodes quoted-printable parts itself
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read %s part: %v", want.mediaType, err)
+		}
+		if string(body) != want.body {
+			t.Errorf("%s body = %q, want %q", want.mediaType, body, want.body)
+		}
+	}
+	if _, err := alternative.NextPart(); err != io.EOF {
+		t.Errorf("multipart/alternative has more than two parts: %v", err)
+	}
+
+	invitePart, err := mixed.NextPart()
+	if err != nil {
+		t.Fatalf("invite part: %v", err)
+	}
+	mediaType, params, err = mime.ParseMediaType(invitePart.Header.Get("Content-Type"))
+	if err != nil || mediaType != "text/calendar" || params["method"] != "REQUEST" || params["name"] != "cuppa.ics" {
+		t.Errorf("invite Content-Type = %q, want text/calendar with method=REQUEST and name cuppa.ics", invitePart.Header.Get("Content-Type"))
+	}
+	if invitePart.FileName() != "cuppa.ics" {
+		t.Errorf("invite filename = %q, want cuppa.ics", invitePart.FileName())
+	}
+	if got := invitePart.Header.Get("Content-Transfer-Encoding"); got != "base64" {
+		t.Errorf("invite Content-Transfer-Encoding = %q, want base64", got)
+	}
+	encoded, err := io.ReadAll(invitePart)
+	if err != nil {
+		t.Fatalf("read invite: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(encoded)), "\n") {
+		if len(strings.TrimSuffix(line, "\r")) > 76 {
+			t.Errorf("base64 line of %d characters, want at most 76", len(line))
+		}
+	}
+	decoded, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, bytes.NewReader(encoded)))
+	if err != nil {
+		t.Fatalf("decode invite: %v", err)
+	}
+	if !bytes.Equal(decoded, invite) {
+		t.Errorf("invite = %q, want %q", decoded, invite)
+	}
+	if _, err := mixed.NextPart(); err != io.EOF {
+		t.Errorf("message has more than two parts: %v", err)
+	}
+}
@@ -2,6 +2,9 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"time"
 	"virtual-cuppa-be/models"
 	"virtual-cuppa-be/repositories"
 )
@@ -10,6 +13,7 @@ var (
 	ErrConfigNotFound      = errors.New("availability configuration not found")
 	ErrConfigAlreadyExists = errors.New("availability configuration already exists for this user")
 	ErrNoAvailabilitySet   = errors.New("at least one availability slot must be selected")
+	ErrInvalidTimeRange    = errors.New("invalid availability time range")
 )
 
 type UserAvailabilityConfigService interface {
@@ -18,20 +22,24 @@ type UserAvailabilityConfigService interface {
 	UpdateConfig(userID uint, input models.UpdateAvailabilityConfigInput) (*models.UserAvailabilityConfig, error)
 	DeleteConfig(userID uint) error
 	HasConfig(userID uint) (bool, error)
+	ImportICS(userID uint, ics io.Reader, apply bool) (*models.AvailabilityImport, error)
 }
 
 type userAvailabilityConfigService struct {
 	configRepo repositories.UserAvailabilityConfigRepository
 	userRepo   repositories.UserRepository
+	orgRepo    repositories.OrganisationRepository
 }
 
 func NewUserAvailabilityConfigService(
 	configRepo repositories.UserAvailabilityConfigRepository,
 	userRepo repositories.UserRepository,
+	orgRepo repositories.OrganisationRepository,
 ) UserAvailabilityConfigService {
 	return &userAvailabilityConfigService{
 		configRepo: configRepo,
 		userRepo:   userRepo,
+		orgRepo:    orgRepo,
 	}
 }
 
@@ -54,11 +62,6 @@ func (s *userAvailabilityConfigService) CreateConfig(userID uint, input models.C
 		return nil, ErrConfigAlreadyExists
 	}
 
-	// Validate that at least one slot is selected
-	if !s.hasAtLeastOneSlot(input) {
-		return nil, ErrNoAvailabilitySet
-	}
-
 	config := &models.UserAvailabilityConfig{
 		UserID:             userID,
 		MondayMorning:      input.MondayMorning,
@@ -77,6 +80,22 @@ func (s *userAvailabilityConfigService) CreateConfig(userID uint, input models.C
 		SundayAfternoon:    input.SundayAfternoon,
 	}
 
+	// Time ranges take precedence; otherwise the half-day flags become whole half-day ranges
+	if len(input.TimeRanges) > 0 {
+		windows, err := models.ParseTimeRanges(input.TimeRanges)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTimeRange, err)
+		}
+		config.SetWindows(windows)
+	} else {
+		config.SetWindows(config.Windows())
+	}
+
+	// Validate that at least one slot is selected
+	if len(config.TimeRanges) == 0 {
+		return nil, ErrNoAvailabilitySet
+	}
+
 	if err := s.configRepo.Create(config); err != nil {
 		return nil, err
 	}
@@ -104,52 +123,43 @@ func (s *userAvailabilityConfigService) UpdateConfig(userID uint, input models.U
 		return nil, ErrConfigNotFound
 	}
 
-	// Update only provided fields
-	if input.MondayMorning != nil {
-		config.MondayMorning = *input.MondayMorning
-	}
-	if input.MondayAfternoon != nil {
-		config.MondayAfternoon = *input.MondayAfternoon
-	}
-	if input.TuesdayMorning != nil {
-		config.TuesdayMorning = *input.TuesdayMorning
-	}
-	if input.TuesdayAfternoon != nil {
-		config.TuesdayAfternoon = *input.TuesdayAfternoon
-	}
-	if input.WednesdayMorning != nil {
-		config.WednesdayMorning = *input.WednesdayMorning
-	}
-	if input.WednesdayAfternoon != nil {
-		config.WednesdayAfternoon = *input.WednesdayAfternoon
-	}
-	if input.ThursdayMorning != nil {
-		config.ThursdayMorning = *input.ThursdayMorning
-	}
-	if input.ThursdayAfternoon != nil {
-		config.ThursdayAfternoon = *input.ThursdayAfternoon
-	}
-	if input.FridayMorning != nil {
-		config.FridayMorning = *input.FridayMorning
-	}
-	if input.FridayAfternoon != nil {
-		config.FridayAfternoon = *input.FridayAfternoon
-	}
-	if input.SaturdayMorning != nil {
-		config.SaturdayMorning = *input.SaturdayMorning
-	}
-	if input.SaturdayAfternoon != nil {
-		config.SaturdayAfternoon = *input.SaturdayAfternoon
-	}
-	if input.SundayMorning != nil {
-		config.SundayMorning = *input.SundayMorning
-	}
-	if input.SundayAfternoon != nil {
-		config.SundayAfternoon = *input.SundayAfternoon
+	if input.TimeRanges != nil {
+		windows, err := models.ParseTimeRanges(input.TimeRanges)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTimeRange, err)
+		}
+		config.SetWindows(windows)
+	}
+
+	// Update only provided half-days, keeping the time ranges in the rest of the week
+	halfDays := []struct {
+		day       time.Weekday
+		period    string
+		available *bool
+	}{
+		{time.Monday, models.PeriodMorning, input.MondayMorning},
+		{time.Monday, models.PeriodAfternoon, input.MondayAfternoon},
+		{time.Tuesday, models.PeriodMorning, input.TuesdayMorning},
+		{time.Tuesday, models.PeriodAfternoon, input.TuesdayAfternoon},
+		{time.Wednesday, models.PeriodMorning, input.WednesdayMorning},
+		{time.Wednesday, models.PeriodAfternoon, input.WednesdayAfternoon},
+		{time.Thursday, models.PeriodMorning, input.ThursdayMorning},
+		{time.Thursday, models.PeriodAfternoon, input.ThursdayAfternoon},
+		{time.Friday, models.PeriodMorning, input.FridayMorning},
+		{time.Friday, models.PeriodAfternoon, input.FridayAfternoon},
+		{time.Saturday, models.PeriodMorning, input.SaturdayMorning},
+		{time.Saturday, models.PeriodAfternoon, input.SaturdayAfternoon},
+		{time.Sunday, models.PeriodMorning, input.SundayMorning},
+		{time.Sunday, models.PeriodAfternoon, input.SundayAfternoon},
+	}
+	for _, halfDay := range halfDays {
+		if halfDay.available != nil && *halfDay.available != config.IsAvailable(halfDay.day, halfDay.period) {
+			config.SetHalfDay(halfDay.day, halfDay.period, *halfDay.available)
+		}
 	}
 
 	// Validate that at least one slot is still selected
-	if !s.hasAtLeastOneSlotInConfig(config) {
+	if len(config.Windows()) == 0 {
 		return nil, ErrNoAvailabilitySet
 	}
 
@@ -176,24 +186,70 @@ func (s *userAvailabilityConfigService) HasConfig(userID uint) (bool, error) {
 	return s.configRepo.Exists(userID)
 }
 
-// Helper function to check if at least one slot is selected in input
-func (s *userAvailabilityConfigService) hasAtLeastOneSlot(input models.CreateAvailabilityConfigInput) bool {
-	return input.MondayMorning || input.MondayAfternoon ||
-		input.TuesdayMorning || input.TuesdayAfternoon ||
-		input.WednesdayMorning || input.WednesdayAfternoon ||
-		input.ThursdayMorning || input.ThursdayAfternoon ||
-		input.FridayMorning || input.FridayAfternoon ||
-		input.SaturdayMorning || input.SaturdayAfternoon ||
-		input.SundayMorning || input.SundayAfternoon
-}
+// ImportICS proposes weekly availability from the busy events of an exported calendar and
+// returns how it differs from the saved configuration. With apply, the proposal is saved
+// through CreateConfig or UpdateConfig like any other edit.
+func (s *userAvailabilityConfigService) ImportICS(userID uint, ics io.Reader, apply bool) (*models.AvailabilityImport, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	// Members outside an organisation get the default working hours and time zone
+	org := &models.Organisation{}
+	if user.OrganisationID != nil {
+		found, err := s.orgRepo.FindByID(*user.OrganisationID)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			org = found
+		}
+	}
+
+	events, err := parseICS(ics)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := user.Location(org)
+	from, to := freeBusyRange(loc, time.Now())
+	busy, busyEvents := busyIntervals(events, loc, from, to)
+	proposed := proposeWindows(org, loc, busy, from)
+
+	config, err := s.configRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	var current []models.WeeklyWindow
+	if config != nil {
+		current = config.Windows()
+	}
 
-// Helper function to check if at least one slot is selected in config
-func (s *userAvailabilityConfigService) hasAtLeastOneSlotInConfig(config *models.UserAvailabilityConfig) bool {
-	return config.MondayMorning || config.MondayAfternoon ||
-		config.TuesdayMorning || config.TuesdayAfternoon ||
-		config.WednesdayMorning || config.WednesdayAfternoon ||
-		config.ThursdayMorning || config.ThursdayAfternoon ||
-		config.FridayMorning || config.FridayAfternoon ||
-		config.SaturdayMorning || config.SaturdayAfternoon ||
-		config.SundayMorning || config.SundayAfternoon
+	result := &models.AvailabilityImport{
+		TimeRanges:  models.NewTimeRanges(proposed),
+		Added:       models.NewTimeRanges(models.SubtractWindows(proposed, current)),
+		Removed:     models.NewTimeRanges(models.SubtractWindows(current, proposed)),
+		SampledFrom: from,
+		SampledTo:   to,
+		BusyEvents:  busyEvents,
+	}
+	if !apply {
+		return result, nil
+	}
+
+	if config == nil {
+		config, err = s.CreateConfig(userID, models.CreateAvailabilityConfigInput{TimeRanges: result.TimeRanges})
+	} else {
+		config, err = s.UpdateConfig(userID, models.UpdateAvailabilityConfigInput{TimeRanges: result.TimeRanges})
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	result.Config = config
+	return result, nil
 }
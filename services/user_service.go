@@ -3,6 +3,7 @@ package services
 import (
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 
@@ -26,21 +27,26 @@ type UserService interface {
 	CreateUser(adminID uint, input *models.CreateUserInput) (*models.User, error)
 	DeleteUser(adminID uint, userID uint) error
 	UpdateUserTags(adminID uint, userID uint, tagNames []string) error
+	UpdateUserSeniority(adminID uint, userID uint, level int) error
+	UpdateUserOffice(adminID uint, userID uint, office string) error
+	UpdateTimeZone(userID uint, timeZone string) (*models.User, error)
 }
 
 type userService struct {
 	userRepo repositories.UserRepository
 	orgRepo  repositories.OrganisationRepository
 	tagRepo  repositories.TagRepository
-	emailSvc EmailService
+	uow      repositories.UnitOfWork
+	notifier Notifier
 }
 
-func NewUserService(userRepo repositories.UserRepository, orgRepo repositories.OrganisationRepository, tagRepo repositories.TagRepository, emailSvc EmailService) UserService {
+func NewUserService(userRepo repositories.UserRepository, orgRepo repositories.OrganisationRepository, tagRepo repositories.TagRepository, uow repositories.UnitOfWork, notifier Notifier) UserService {
 	return &userService{
 		userRepo: userRepo,
 		orgRepo:  orgRepo,
 		tagRepo:  tagRepo,
-		emailSvc: emailSvc,
+		uow:      uow,
+		notifier: notifier,
 	}
 }
 
@@ -195,14 +201,27 @@ func (s *userService) CreateUser(adminID uint, input *models.CreateUserInput) (*
 		IsConfirmed:    false,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	org, err := s.orgRepo.FindByID(*admin.OrganisationID)
+	if err != nil {
 		return nil, err
 	}
 
-	// Send invitation email
-	org, err := s.orgRepo.FindByID(*admin.OrganisationID)
-	if err == nil && org != nil {
-		s.emailSvc.SendInvitation(user.Email, user.FirstName+" "+user.LastName, org.Name)
+	// Create the user and queue the invitation email together
+	err = s.uow.Do(func(repos *repositories.TxRepositories) error {
+		if err := repos.Users.Create(user); err != nil {
+			return err
+		}
+		return s.notifier.Enqueue(repos.Outbox, &Notification{
+			Event:          models.NotificationUserInvited,
+			OrganisationID: org.ID,
+			RecipientName:  user.FirstName + " " + user.LastName,
+			RecipientEmail: user.Email,
+			Text:           fmt.Sprintf("%s was invited to %s", user.FirstName+" "+user.LastName, org.Name),
+			Data:           NotificationData{OrganisationName: org.Name},
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return user, nil
@@ -281,3 +300,84 @@ func (s *userService) UpdateUserTags(adminID uint, userID uint, tagNames []strin
 
 	return nil
 }
+
+func (s *userService) UpdateUserSeniority(adminID uint, userID uint, level int) error {
+	admin, err := s.userRepo.FindByID(adminID)
+	if err != nil {
+		return err
+	}
+	if admin == nil {
+		return ErrUserNotFound
+	}
+
+	if admin.OrganisationID == nil {
+		return ErrAdminNoOrganisation
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	// Check if user belongs to the same organisation
+	if user.OrganisationID == nil || *user.OrganisationID != *admin.OrganisationID {
+		return errors.New("user does not belong to your organisation")
+	}
+
+	user.SeniorityLevel = &level
+	return s.userRepo.Update(user)
+}
+
+// UpdateUserOffice sets the office whose regional holiday calendars apply to the user
+func (s *userService) UpdateUserOffice(adminID uint, userID uint, office string) error {
+	admin, err := s.userRepo.FindByID(adminID)
+	if err != nil {
+		return err
+	}
+	if admin == nil {
+		return ErrUserNotFound
+	}
+
+	if admin.OrganisationID == nil {
+		return ErrAdminNoOrganisation
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	if user.OrganisationID == nil || *user.OrganisationID != *admin.OrganisationID {
+		return errors.New("user does not belong to your organisation")
+	}
+
+	user.Office = office
+	return s.userRepo.Update(user)
+}
+
+// UpdateTimeZone sets the user's IANA time zone; an empty zone falls back to the organisation's
+func (s *userService) UpdateTimeZone(userID uint, timeZone string) (*models.User, error) {
+	if timeZone != "" && !models.IsValidTimeZone(timeZone) {
+		return nil, ErrInvalidTimeZone
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.TimeZone = timeZone
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -16,18 +16,31 @@ type Cache struct {
 }
 
 var confirmCodeCache *Cache
+var matchProposalCache *Cache
 
 func init() {
-	confirmCodeCache = &Cache{
+	confirmCodeCache = NewCache()
+	matchProposalCache = NewCache()
+}
+
+// NewCache creates an empty cache that drops expired items in the background
+func NewCache() *Cache {
+	cache := &Cache{
 		items: make(map[string]CacheItem),
 	}
-	go confirmCodeCache.cleanupExpired()
+	go cache.cleanupExpired()
+	return cache
 }
 
 func GetConfirmCodeCache() *Cache {
 	return confirmCodeCache
 }
 
+// GetMatchProposalCache holds match generation previews until an admin commits them
+func GetMatchProposalCache() *Cache {
+	return matchProposalCache
+}
+
 func (c *Cache) Set(key string, value string, duration time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
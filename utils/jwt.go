@@ -57,6 +57,53 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
+// matchActionSecret signs match action links. It differs from jwtSecret so that a link can
+// never be used as a login token, nor a login token as a link.
+var matchActionSecret = append([]byte("match-action:"), jwtSecret...)
+
+// MatchActionClaims allow one user to take one action on one match without logging in,
+// from a link in an email
+type MatchActionClaims struct {
+	UserID  uint   `json:"user_id"`
+	MatchID uint   `json:"match_id"`
+	Action  string `json:"action"`
+	jwt.RegisteredClaims
+}
+
+func GenerateMatchActionToken(userID uint, matchID uint, action string, ttl time.Duration) (string, error) {
+	claims := MatchActionClaims{
+		UserID:  userID,
+		MatchID: matchID,
+		Action:  action,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(matchActionSecret)
+}
+
+func ValidateMatchActionToken(tokenString string) (*MatchActionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &MatchActionClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return matchActionSecret, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*MatchActionClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid token")
+}
+
 func GenerateRefreshToken() (string, error) {
 	b := make([]byte, 32)
 	_, err := rand.Read(b)
@@ -66,6 +113,16 @@ func GenerateRefreshToken() (string, error) {
 	return base64.URLEncoding.EncodeToString(b), nil
 }
 
+// GenerateFeedToken returns a random URL-safe token for calendar feed addresses
+func GenerateFeedToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 func GenerateConfirmCode() string {
 	code := ""
 	for i := 0; i < 6; i++ {
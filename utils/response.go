@@ -30,6 +30,12 @@ func RespondWithMessage(c *gin.Context, code int, message string) {
 }
 
 func HandleServiceError(c *gin.Context, err error) {
+	// Time range errors carry the offending range after the prefix
+	if strings.HasPrefix(err.Error(), "invalid availability time range") {
+		RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	switch err.Error() {
 	case "user with this email already exists":
 		RespondWithError(c, http.StatusConflict, err.Error())
@@ -53,6 +59,10 @@ func HandleServiceError(c *gin.Context, err error) {
 		RespondWithError(c, http.StatusConflict, err.Error())
 	case "at least one availability slot must be selected":
 		RespondWithError(c, http.StatusBadRequest, err.Error())
+	case "working hours must end after they start":
+		RespondWithError(c, http.StatusBadRequest, err.Error())
+	case "invalid time zone":
+		RespondWithError(c, http.StatusBadRequest, err.Error())
 	default:
 		log.Printf("Unhandled service error: %v", err)
 		if strings.Contains(err.Error(), "sendgrid") {